@@ -28,13 +28,40 @@ type LoginResult struct {
 	ExpiresOn *time.Time  `json:"expiresOn,omitempty"`
 }
 
-func CheckAzdVersion() CheckResult {
-	return CheckTool("azd", "version")
+func CheckAzdVersion(r Runner) CheckResult {
+	return CheckTool(r, "azd", "version")
 }
 
-func CheckAzdLogin(ctx context.Context, client IAzdClient) CheckResult {
+// CheckAzdRequiredVersion validates the installed azd version, taken from the result of
+// CheckAzdVersion, against requiredVersions.azd from azure.yaml.
+func CheckAzdRequiredVersion(azd CheckResult, requiredRange string) CheckResult {
+	name := "azd version"
+	upgrade := fmt.Sprintf("Upgrade azd to a version matching %s: https://aka.ms/azd-install", requiredRange)
+	if !azd.Installed {
+		return CheckResult{Name: name, Severity: SeveritySkip, Error: fmt.Errorf("azd is not installed")}
+	}
+
+	v, err := ParseVersion(azd.Version)
+	if err != nil {
+		return CheckResult{Name: name, Installed: true, Version: azd.Version, Error: fmt.Errorf("failed to parse azd version: %w", err),
+			Severity: SeverityFail, Remediation: upgrade}
+	}
+	ok, err := SatisfiesRange(v, requiredRange)
+	if err != nil {
+		return CheckResult{Name: name, Installed: true, Version: v.String(), Error: err,
+			Severity: SeverityFail, Remediation: "Fix requiredVersions.azd in azure.yaml"}
+	}
+	if !ok {
+		return CheckResult{Name: name, Installed: true, Version: v.String(),
+			Error:    fmt.Errorf("azd %s does not satisfy requiredVersions.azd %s", v, requiredRange),
+			Severity: SeverityFail, Remediation: upgrade}
+	}
+	return CheckResult{Name: name, Installed: true, Version: v.String(), Running: true, Severity: SeverityPass}
+}
+
+func CheckAzdLogin(ctx context.Context, r Runner, client IAzdClient) CheckResult {
 	// Check login status using CLI command
-	out, err := runnerOutput(ctx, "azd", "auth", "login", "--check-status")
+	out, err := runnerOutput(ctx, r, "azd", "auth", "login", "--check-status")
 	outputStr := strings.TrimSpace(string(out))
 
 	if err != nil {
@@ -43,33 +70,37 @@ func CheckAzdLogin(ctx context.Context, client IAzdClient) CheckResult {
 			if errors.Is(err, context.Canceled) {
 				msg = "Canceled"
 			}
-			return CheckResult{Name: "azd auth", Installed: true, Version: msg, Running: false, Error: err}
+			return CheckResult{Name: "azd auth", Installed: true, Version: msg, Running: false, Error: err,
+				Severity: SeverityFail, Remediation: "Retry with a longer --auth-timeout, or run `azd auth login`"}
 		}
 
 		msg := "Not logged in"
 		if len(outputStr) > 0 {
 			msg = outputStr
 		}
-		return CheckResult{Name: "azd auth", Installed: true, Version: msg, Running: false, Error: fmt.Errorf("not logged in: %w", err)}
+		return CheckResult{Name: "azd auth", Installed: true, Version: msg, Running: false, Error: fmt.Errorf("not logged in: %w", err),
+			Severity: SeverityFail, Remediation: "Run `azd auth login`"}
 	}
 
 	if outputStr == "" {
 		outputStr = "Logged in"
 	}
 
-	return CheckResult{Name: "azd auth", Installed: true, Version: outputStr, Running: true}
+	return CheckResult{Name: "azd auth", Installed: true, Version: outputStr, Running: true, Severity: SeverityPass}
 }
 
 func CheckAzdInit(ctx context.Context, client IAzdClient) CheckResult {
 	// Check if project is initialized using extension client
 	resp, err := client.Project().Get(ctx, &azdext.EmptyRequest{})
 	if err != nil {
-		return CheckResult{Name: "azd project", Installed: false, Error: fmt.Errorf("project not initialized: %w", err)}
+		return CheckResult{Name: "azd project", Installed: false, Error: fmt.Errorf("project not initialized: %w", err),
+			Severity: SeverityFail, Remediation: "Run `azd init`"}
 	}
 
 	if resp.Project == nil {
-		return CheckResult{Name: "azd project", Installed: false, Error: fmt.Errorf("project not initialized (no project returned)")}
+		return CheckResult{Name: "azd project", Installed: false, Error: fmt.Errorf("project not initialized (no project returned)"),
+			Severity: SeverityFail, Remediation: "Run `azd init`"}
 	}
 
-	return CheckResult{Name: "azd project", Installed: true, Version: fmt.Sprintf("Initialized (%s)", resp.Project.Name), Running: true}
+	return CheckResult{Name: "azd project", Installed: true, Version: fmt.Sprintf("Initialized (%s)", resp.Project.Name), Running: true, Severity: SeverityPass}
 }
@@ -0,0 +1,264 @@
+package checks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached version output is reused.
+const DefaultCacheTTL = 24 * time.Hour
+
+// VersionCache persists the output of tool version commands (e.g. `node --version`)
+// across runs. Entries are keyed by the resolved binary path, its size and modification
+// time, so upgrading or replacing a tool invalidates its entry, and by the working
+// directory and the version pin files that apply to it (see pinFiles).
+type VersionCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+type cacheEntry struct {
+	Output  string    `json:"output"`
+	Created time.Time `json:"created"`
+}
+
+// DefaultCachePath returns the cache file under the azd config directory
+// ($AZD_CONFIG_DIR, or ~/.azd).
+func DefaultCachePath() (string, error) {
+	dir := os.Getenv("AZD_CONFIG_DIR")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve the azd config directory: %w", err)
+		}
+		dir = filepath.Join(home, ".azd")
+	}
+	return filepath.Join(dir, "doctor", "cache.json"), nil
+}
+
+// LoadVersionCache reads the cache at path. A missing or unreadable cache file starts empty.
+func LoadVersionCache(path string, ttl time.Duration) *VersionCache {
+	c := &VersionCache{path: path, ttl: ttl, now: time.Now, entries: make(map[string]cacheEntry)}
+	if data, err := os.ReadFile(path); err == nil {
+		// A corrupt cache is discarded and rewritten on Save.
+		_ = json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+// Save writes the cache back to disk if it changed. Expired entries are dropped.
+func (c *VersionCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	for key, entry := range c.entries {
+		if c.expired(entry) {
+			delete(c.entries, key)
+		}
+	}
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// Write to a temporary file first so that concurrent readers never see a partial file.
+	// Each save uses its own file since the extension host and a manual run can save at
+	// the same time.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), "versions-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+func (c *VersionCache) expired(entry cacheEntry) bool {
+	return c.now().Sub(entry.Created) > c.ttl
+}
+
+func (c *VersionCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || c.expired(entry) {
+		return nil, false
+	}
+	return []byte(entry.Output), true
+}
+
+func (c *VersionCache) put(key string, out []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{Output: string(out), Created: c.now()}
+	c.dirty = true
+}
+
+// isVersionQuery reports whether the arguments only ask a tool for its version.
+func isVersionQuery(args []string) bool {
+	if len(args) != 1 {
+		return false
+	}
+	switch args[0] {
+	case "--version", "version", "-version":
+		return true
+	}
+	return false
+}
+
+// CachingRunner is a Runner that answers version queries from a VersionCache. Other
+// commands, and tools whose binary can't be resolved, always run.
+type CachingRunner struct {
+	Runner
+	cache *VersionCache
+}
+
+// NewCachingRunner wraps r with the cache. r should implement PathResolver; without it
+// binaries can't be identified and nothing is cached.
+func NewCachingRunner(r Runner, cache *VersionCache) *CachingRunner {
+	return &CachingRunner{Runner: r, cache: cache}
+}
+
+func (c *CachingRunner) Output(name string, args ...string) ([]byte, error) {
+	return c.cached(name, args, "", "", func() ([]byte, error) { return c.Runner.Output(name, args...) })
+}
+
+func (c *CachingRunner) OutputContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return c.cached(name, args, "", "", func() ([]byte, error) { return runnerOutput(ctx, c.Runner, name, args...) })
+}
+
+func (c *CachingRunner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return c.cached(name, args, "", "combined", func() ([]byte, error) { return runnerCombinedOutput(ctx, c.Runner, name, args...) })
+}
+
+// OutputInDir runs the command in dir; its version output is cached for dir and the
+// pin files that apply there. Wrapped runners without DirRunner run it in the process
+// working directory.
+func (c *CachingRunner) OutputInDir(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if _, ok := c.Runner.(DirRunner); !ok {
+		return c.OutputContext(ctx, name, args...)
+	}
+	return c.cached(name, args, dir, "", func() ([]byte, error) { return runnerOutputInDir(ctx, c.Runner, dir, name, args...) })
+}
+
+func (c *CachingRunner) RunContext(ctx context.Context, name string, args ...string) error {
+	return runnerRun(ctx, c.Runner, name, args...)
+}
+
+func (c *CachingRunner) LookPath(name string) (string, error) {
+	if pr, ok := c.Runner.(PathResolver); ok {
+		return pr.LookPath(name)
+	}
+	return "", fmt.Errorf("runner can't resolve %s", name)
+}
+
+func (c *CachingRunner) RunInteractive(ctx context.Context, name string, args ...string) error {
+	return RunInteractive(ctx, c.Runner, name, args...)
+}
+
+// cached runs a command through the cache. dir is the directory the command runs in,
+// the process working directory if empty. mode distinguishes outputs of the same
+// command captured differently (e.g. combined with stderr).
+func (c *CachingRunner) cached(name string, args []string, dir, mode string, run func() ([]byte, error)) ([]byte, error) {
+	key, ok := c.key(name, args, dir)
+	if !ok {
+		return run()
+	}
+	if mode != "" {
+		key += "|" + mode
+	}
+	if out, ok := c.cache.get(key); ok {
+		return out, nil
+	}
+	out, err := run()
+	if err == nil {
+		c.cache.put(key, out)
+	}
+	return out, err
+}
+
+// key identifies the binary and command, or returns false if the command isn't cacheable.
+func (c *CachingRunner) key(name string, args []string, dir string) (string, bool) {
+	if !isVersionQuery(args) {
+		return "", false
+	}
+	path := runnerLookPath(c.Runner, name)
+	if path == "" {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	wd, ok := workDirKey(dir)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s|%d|%d|%s|%s", path, info.Size(), info.ModTime().UnixNano(), strings.Join(args, " "), wd), true
+}
+
+// pinFiles select the version a tool reports depending on the directory it runs in:
+// dotnet reads global.json, pyenv and asdf shims read .python-version and .tool-versions,
+// nvm and volta read .nvmrc, .node-version and package.json, and corepack shims (pnpm,
+// yarn) the packageManager field of package.json. Wrappers such as ./mvnw resolve
+// relative to the working directory itself.
+var pinFiles = []string{"global.json", ".python-version", ".tool-versions", ".nvmrc", ".node-version", "package.json"}
+
+// workDirKey identifies the working directory (the process one if wd is empty) and the
+// contents of the nearest pin files in it or its parents, so that a version cached in one
+// project or service isn't reused in another.
+func workDirKey(wd string) (string, bool) {
+	if wd == "" {
+		var err error
+		if wd, err = os.Getwd(); err != nil {
+			return "", false
+		}
+	}
+	wd, err := filepath.Abs(wd)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(wd))
+	for _, name := range pinFiles {
+		path := findUp(wd, "", name)
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", false
+		}
+		fmt.Fprintf(h, "\x00%s\x00%d\x00", path, len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8]), true
+}
@@ -0,0 +1,236 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// binRunner resolves every tool to a fake binary in dir and counts Output calls.
+type binRunner struct {
+	MockRunner
+	dir   string
+	calls int
+}
+
+func (b *binRunner) LookPath(name string) (string, error) {
+	path := filepath.Join(b.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func newBinRunner(t *testing.T, tools ...string) *binRunner {
+	b := &binRunner{dir: t.TempDir()}
+	for _, tool := range tools {
+		require.NoError(t, os.WriteFile(filepath.Join(b.dir, tool), []byte("binary"), 0755))
+	}
+	b.OutputFunc = func(name string, args ...string) ([]byte, error) {
+		b.calls++
+		if _, err := b.LookPath(name); err != nil {
+			return nil, fmt.Errorf("executable file not found in $PATH")
+		}
+		return []byte(fmt.Sprintf("%s %d", name, b.calls)), nil
+	}
+	return b
+}
+
+// dirBinRunner is a binRunner that records the directories of OutputInDir calls.
+type dirBinRunner struct {
+	*binRunner
+	dirs []string
+}
+
+func (b *dirBinRunner) OutputInDir(_ context.Context, dir, name string, args ...string) ([]byte, error) {
+	b.dirs = append(b.dirs, dir)
+	return b.Output(name, args...)
+}
+
+func TestCachingRunner(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "doctor", "cache.json")
+
+	t.Run("Version queries are cached per binary", func(t *testing.T) {
+		bin := newBinRunner(t, "node")
+		r := NewCachingRunner(bin, LoadVersionCache(cachePath, time.Hour))
+
+		out, err := r.Output("node", "--version")
+		require.NoError(t, err)
+		assert.Equal(t, "node 1", string(out))
+		out, _ = r.Output("node", "--version")
+		assert.Equal(t, "node 1", string(out), "second call is served from the cache")
+		assert.Equal(t, 1, bin.calls)
+
+		// Upgrading the binary changes its size and mtime.
+		require.NoError(t, os.WriteFile(filepath.Join(bin.dir, "node"), []byte("new binary"), 0755))
+		out, _ = r.Output("node", "--version")
+		assert.Equal(t, "node 2", string(out))
+	})
+
+	t.Run("Other commands and missing tools always run", func(t *testing.T) {
+		bin := newBinRunner(t, "azd")
+		r := NewCachingRunner(bin, LoadVersionCache(cachePath, time.Hour))
+
+		r.Output("azd", "auth", "login", "--check-status")
+		r.Output("azd", "auth", "login", "--check-status")
+		assert.Equal(t, 2, bin.calls)
+
+		_, err := r.Output("terraform", "--version")
+		assert.Error(t, err)
+		_, err = r.Output("terraform", "--version")
+		assert.Error(t, err)
+		assert.Equal(t, 4, bin.calls, "failures are not cached")
+	})
+
+	t.Run("Runners without PathResolver are not cached", func(t *testing.T) {
+		calls := 0
+		mock := &MockRunner{OutputFunc: func(name string, args ...string) ([]byte, error) {
+			calls++
+			return []byte("1.0.0"), nil
+		}}
+		r := NewCachingRunner(mock, LoadVersionCache(cachePath, time.Hour))
+		r.Output("git", "--version")
+		r.Output("git", "--version")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Combined output is cached separately", func(t *testing.T) {
+		bin := newBinRunner(t, "java")
+		r := NewCachingRunner(bin, LoadVersionCache(cachePath, time.Hour))
+
+		r.Output("java", "-version")
+		out, err := r.CombinedOutput(context.Background(), "java", "-version")
+		require.NoError(t, err)
+		assert.Equal(t, "java 2", string(out), "stdout-only output is not reused")
+		out, _ = r.CombinedOutput(context.Background(), "java", "-version")
+		assert.Equal(t, "java 2", string(out))
+		assert.Equal(t, 2, bin.calls)
+	})
+
+	t.Run("Entries are cached per directory and pin files", func(t *testing.T) {
+		// A pyenv shim reports the version pinned by the project it runs in.
+		bin := newBinRunner(t, "python3")
+		r := NewCachingRunner(bin, LoadVersionCache(cachePath, time.Hour))
+		a, b := t.TempDir(), t.TempDir()
+		writeFiles(t, a, map[string]string{".python-version": "3.11\n", "api/main.py": ""})
+		writeFiles(t, b, map[string]string{".python-version": "3.12\n"})
+
+		t.Chdir(a)
+		out, _ := r.Output("python3", "--version")
+		assert.Equal(t, "python3 1", string(out))
+
+		t.Chdir(b)
+		out, _ = r.Output("python3", "--version")
+		assert.Equal(t, "python3 2", string(out), "project B doesn't reuse the version of project A")
+
+		t.Chdir(a)
+		out, _ = r.Output("python3", "--version")
+		assert.Equal(t, "python3 1", string(out))
+
+		// Subdirectories see the same pin file but may resolve wrappers differently.
+		t.Chdir(filepath.Join(a, "api"))
+		out, _ = r.Output("python3", "--version")
+		assert.Equal(t, "python3 3", string(out))
+
+		// Changing the pin invalidates the entry.
+		writeFiles(t, a, map[string]string{".python-version": "3.13\n"})
+		t.Chdir(a)
+		out, _ = r.Output("python3", "--version")
+		assert.Equal(t, "python3 4", string(out))
+	})
+
+	t.Run("Commands run in a service directory are cached per directory", func(t *testing.T) {
+		bin := &dirBinRunner{binRunner: newBinRunner(t, "pnpm")}
+		r := NewCachingRunner(bin, LoadVersionCache(cachePath, time.Hour))
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{
+			"package.json":     `{"packageManager": "pnpm@9.1.0"}`,
+			"api/package.json": `{"packageManager": "pnpm@8.15.4"}`,
+			"web/package.json": `{"packageManager": "pnpm@9.1.0"}`,
+		})
+		t.Chdir(root)
+		ctx := context.Background()
+
+		out, _ := r.OutputInDir(ctx, filepath.Join(root, "api"), "pnpm", "--version")
+		assert.Equal(t, "pnpm 1", string(out))
+		out, _ = r.OutputInDir(ctx, filepath.Join(root, "web"), "pnpm", "--version")
+		assert.Equal(t, "pnpm 2", string(out), "services don't share an entry")
+		out, _ = r.OutputInDir(ctx, filepath.Join(root, "api"), "pnpm", "--version")
+		assert.Equal(t, "pnpm 1", string(out))
+		out, _ = r.Output("pnpm", "--version")
+		assert.Equal(t, "pnpm 3", string(out), "the project root doesn't reuse a service entry")
+		assert.Equal(t, []string{filepath.Join(root, "api"), filepath.Join(root, "web")}, bin.dirs)
+	})
+
+	t.Run("Entries expire after the TTL", func(t *testing.T) {
+		bin := newBinRunner(t, "git")
+		cache := LoadVersionCache(cachePath, time.Hour)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		r := NewCachingRunner(bin, cache)
+
+		r.Output("git", "--version")
+		now = now.Add(2 * time.Hour)
+		out, _ := r.Output("git", "--version")
+		assert.Equal(t, "git 2", string(out))
+	})
+}
+
+func TestVersionCacheSave(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "doctor", "cache.json")
+	bin := newBinRunner(t, "python3")
+
+	cache := LoadVersionCache(cachePath, time.Hour)
+	NewCachingRunner(bin, cache).Output("python3", "--version")
+	require.NoError(t, cache.Save())
+
+	// A new process reuses the saved result without running the tool.
+	out, err := NewCachingRunner(bin, LoadVersionCache(cachePath, time.Hour)).Output("python3", "--version")
+	require.NoError(t, err)
+	assert.Equal(t, "python3 1", string(out))
+	assert.Equal(t, 1, bin.calls)
+
+	// A corrupt cache starts empty.
+	require.NoError(t, os.WriteFile(cachePath, []byte("{not json"), 0644))
+	out, _ = NewCachingRunner(bin, LoadVersionCache(cachePath, time.Hour)).Output("python3", "--version")
+	assert.Equal(t, "python3 2", string(out))
+}
+
+func TestVersionCacheSave_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "cache.json")
+
+	// The extension host and a manual run save the same cache at the same time.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache := LoadVersionCache(cachePath, time.Hour)
+			cache.put(fmt.Sprintf("tool%d", i), []byte("1.0.0"))
+			assert.NoError(t, cache.Save())
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(cachePath)
+	require.NoError(t, err)
+	assert.True(t, json.Valid(data), "the cache is never truncated: %s", data)
+	tmp, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	assert.Empty(t, tmp, "temporary files are renamed")
+}
+
+func TestDefaultCachePath(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", filepath.Join("/tmp", "azd-config"))
+	path, err := DefaultCachePath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/tmp", "azd-config", "doctor", "cache.json"), path)
+}
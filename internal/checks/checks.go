@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 )
@@ -24,6 +26,31 @@ type RunnerWithContext interface {
 	RunContext(ctx context.Context, name string, args ...string) error
 }
 
+// PathResolver is an optional extension to Runner that resolves the path of an executable,
+// reported as CheckResult.Path.
+type PathResolver interface {
+	LookPath(name string) (string, error)
+}
+
+// InteractiveRunner is an optional extension to Runner that runs a command attached to
+// the terminal, for commands that prompt the user (e.g. `azd auth login` run by `fix`).
+type InteractiveRunner interface {
+	RunInteractive(ctx context.Context, name string, args ...string) error
+}
+
+// CombinedOutputRunner is an optional extension to Runner that captures stdout and stderr
+// together, for tools that print their version to stderr (e.g. `java -version`).
+type CombinedOutputRunner interface {
+	CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// DirRunner is an optional extension to Runner that runs a command in another working
+// directory, for tools whose version depends on the pins of the project they run in
+// (e.g. corepack, volta, pyenv or asdf shims).
+type DirRunner interface {
+	OutputInDir(ctx context.Context, dir, name string, args ...string) ([]byte, error)
+}
+
 type RealRunner struct{}
 
 func (r *RealRunner) Output(name string, args ...string) ([]byte, error) {
@@ -34,6 +61,12 @@ func (r *RealRunner) OutputContext(ctx context.Context, name string, args ...str
 	return exec.CommandContext(ctx, name, args...).Output()
 }
 
+func (r *RealRunner) OutputInDir(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
+
 func (r *RealRunner) Run(name string, args ...string) error {
 	return exec.Command(name, args...).Run()
 }
@@ -42,118 +75,258 @@ func (r *RealRunner) RunContext(ctx context.Context, name string, args ...string
 	return exec.CommandContext(ctx, name, args...).Run()
 }
 
-var CommandRunner Runner = &RealRunner{}
+func (r *RealRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+func (r *RealRunner) RunInteractive(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+func (r *RealRunner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
 
-func runnerOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
-	if r, ok := CommandRunner.(RunnerWithContext); ok {
-		return r.OutputContext(ctx, name, args...)
+func runnerOutput(ctx context.Context, r Runner, name string, args ...string) ([]byte, error) {
+	if rc, ok := r.(RunnerWithContext); ok {
+		return rc.OutputContext(ctx, name, args...)
 	}
 
-	return CommandRunner.Output(name, args...)
+	return r.Output(name, args...)
 }
 
-func runnerRun(ctx context.Context, name string, args ...string) error {
-	if r, ok := CommandRunner.(RunnerWithContext); ok {
-		return r.RunContext(ctx, name, args...)
+// runnerOutputInDir runs the command in dir when the runner supports it, and in the
+// process working directory otherwise.
+func runnerOutputInDir(ctx context.Context, r Runner, dir, name string, args ...string) ([]byte, error) {
+	if dr, ok := r.(DirRunner); ok && dir != "" {
+		return dr.OutputInDir(ctx, dir, name, args...)
 	}
+	return runnerOutput(ctx, r, name, args...)
+}
 
-	return CommandRunner.Run(name, args...)
+// serviceVersion returns the version of a tool as seen from a service directory, where
+// version manager shims may select another version than in the process working directory.
+// res is the result of the tool check, whose version is kept when the runner can't run
+// commands in dir or the command fails.
+func serviceVersion(ctx context.Context, r Runner, dir, tool string, res CheckResult) string {
+	if _, ok := r.(DirRunner); !ok || dir == "" {
+		return res.Version
+	}
+	out, err := runnerOutputInDir(ctx, r, dir, tool, "--version")
+	if err != nil {
+		return res.Version
+	}
+	return cleanVersion(tool, string(out))
 }
 
+// runnerCombinedOutput returns stdout and stderr of a command when the runner supports it,
+// and stdout otherwise.
+func runnerCombinedOutput(ctx context.Context, r Runner, name string, args ...string) ([]byte, error) {
+	if cr, ok := r.(CombinedOutputRunner); ok {
+		return cr.CombinedOutput(ctx, name, args...)
+	}
+	return runnerOutput(ctx, r, name, args...)
+}
+
+func runnerRun(ctx context.Context, r Runner, name string, args ...string) error {
+	if rc, ok := r.(RunnerWithContext); ok {
+		return rc.RunContext(ctx, name, args...)
+	}
+
+	return r.Run(name, args...)
+}
+
+// RunInteractive runs the command attached to the terminal if the runner supports it,
+// and as a regular command otherwise.
+func RunInteractive(ctx context.Context, r Runner, name string, args ...string) error {
+	if ir, ok := r.(InteractiveRunner); ok {
+		return ir.RunInteractive(ctx, name, args...)
+	}
+	return runnerRun(ctx, r, name, args...)
+}
+
+// runnerLookPath returns the resolved path of the executable, or "" if the runner can't resolve it.
+func runnerLookPath(r Runner, name string) string {
+	if pr, ok := r.(PathResolver); ok {
+		if path, err := pr.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Severity describes how a check result should be treated by commands.
+type Severity string
+
+const (
+	// SeverityPass means the requirement is met.
+	SeverityPass Severity = "pass"
+	// SeverityWarn means something is missing or wrong but is not required.
+	SeverityWarn Severity = "warn"
+	// SeverityFail means a required tool or setting is missing; verify fails on it.
+	SeverityFail Severity = "fail"
+	// SeveritySkip means the check did not run (e.g. a dependency failed).
+	SeveritySkip Severity = "skip"
+	// SeverityInfo is informational output that is neither a pass nor a problem.
+	SeverityInfo Severity = "info"
+)
+
 type CheckResult struct {
+	// ID is the ID of the check that produced the result; set by the Executor.
+	ID        string
 	Name      string
 	Installed bool
 	Version   string
 	Running   bool
 	HasDaemon bool
 	Error     error
+	Severity  Severity
+	// Remediation is a short hint on how to fix a failed or warning result.
+	Remediation string
+	// Install is the command that installs a missing tool on this machine, if known.
+	Install string
+	// Path is the resolved path of the executable, if known.
+	Path string
+	// Duration is how long the check took; set by the Executor.
+	Duration time.Duration
+	// Location points at the azure.yaml entry that caused the result, if any.
+	Location *Location
+}
+
+// Status returns the severity of the result. Results that don't set Severity
+// explicitly are classified from Installed, Running and Error.
+func (r CheckResult) Status() Severity {
+	if r.Severity != "" {
+		return r.Severity
+	}
+	if !r.Installed || r.Error != nil || (r.HasDaemon && !r.Running) {
+		return SeverityFail
+	}
+	return SeverityPass
 }
 
-func CheckTool(name string, args ...string) CheckResult {
-	out, err := CommandRunner.Output(name, args...)
+// Failed reports whether the result has fail severity.
+func (r CheckResult) Failed() bool {
+	return r.Status() == SeverityFail
+}
+
+func CheckTool(r Runner, name string, args ...string) CheckResult {
+	out, err := r.Output(name, args...)
 	if err != nil {
-		return CheckResult{Name: name, Installed: false, Error: err}
+		return CheckResult{Name: name, Installed: false, Error: err, Severity: SeverityFail}
+	}
+	return CheckResult{Name: name, Installed: true, Version: cleanVersion(name, string(out)), Running: true, Severity: SeverityPass,
+		Path: runnerLookPath(r, name)}
+}
+
+// cleanVersion returns the semantic version parsed from the tool output, or the first
+// line of the output when it has no recognizable version.
+func cleanVersion(name, output string) string {
+	if v, err := ParseToolVersion(name, output); err == nil {
+		return v.String()
 	}
-	return CheckResult{Name: name, Installed: true, Version: strings.TrimSpace(string(out)), Running: true}
+	output = strings.TrimSpace(output)
+	if i := strings.IndexByte(output, '\n'); i >= 0 {
+		output = strings.TrimSpace(output[:i])
+	}
+	return output
+}
+
+func CheckDocker(r Runner) CheckResult {
+	return CheckDockerWithOS(r, runtime.GOOS)
 }
 
-func CheckDocker() CheckResult {
-	return CheckDockerWithOS(runtime.GOOS)
+// CheckDockerWithOS checks for Docker or Podman with OS-specific priority, including daemon status.
+func CheckDockerWithOS(r Runner, goos string) CheckResult {
+	res := CheckContainerRuntimeWithOS(r, goos)
+	if !res.Installed {
+		return res
+	}
+
+	daemon := CheckContainerDaemon(r, res.Name)
+	res.HasDaemon = true
+	res.Running = daemon.Running
+	res.Error = daemon.Error
+	res.Severity = daemon.Severity
+	res.Remediation = daemon.Remediation
+	return res
 }
 
-// CheckDockerWithOS checks for Docker or Podman with OS-specific priority
-func CheckDockerWithOS(goos string) CheckResult {
+// CheckContainerRuntimeWithOS checks for the Docker or Podman binary with OS-specific priority.
+// It does not check whether the daemon is running; see CheckContainerDaemon.
+func CheckContainerRuntimeWithOS(r Runner, goos string) CheckResult {
 	// Determine check order based on OS
 	// Linux: Podman is increasingly common, check both
 	// macOS/Windows: Docker Desktop is standard
-	var primaryTool, secondaryTool string
 	var primaryCmd, secondaryCmd string
 
 	switch goos {
 	case "linux":
 		// On Linux, try Docker first (still most common), then Podman
-		primaryTool = "docker"
 		primaryCmd = "docker"
-		secondaryTool = "podman"
 		secondaryCmd = "podman"
 	case "darwin", "windows":
 		// On macOS and Windows, Docker Desktop is standard
-		primaryTool = "docker"
 		primaryCmd = "docker"
-		secondaryTool = "podman"
 		secondaryCmd = "podman"
 	default:
 		// Unknown OS, try docker first
-		primaryTool = "docker"
 		primaryCmd = "docker"
-		secondaryTool = "podman"
 		secondaryCmd = "podman"
 	}
 
-	// Try primary tool
-	res := CheckTool(primaryCmd, "--version")
-	if res.Installed {
-		res.Name = primaryTool
-		res.HasDaemon = true
-		// Check if daemon is running
-		if err := CommandRunner.Run(primaryCmd, "info"); err != nil {
-			res.Running = false
-			res.Error = fmt.Errorf("daemon not running")
+	for _, cmd := range []string{primaryCmd, secondaryCmd} {
+		res := CheckTool(r, cmd, "--version")
+		if res.Installed {
+			res.Name = cmd
+			return res
 		}
-		return res
-	}
-
-	// Fallback to secondary tool
-	res = CheckTool(secondaryCmd, "--version")
-	if res.Installed {
-		res.Name = secondaryTool
-		res.HasDaemon = true
-		// Check if daemon is running
-		// Note: Podman on Linux often runs rootless/daemonless
-		if err := CommandRunner.Run(secondaryCmd, "info"); err != nil {
-			res.Running = false
-			res.Error = fmt.Errorf("not running or not configured")
-		}
-		return res
 	}
 
 	return CheckResult{
 		Name:      "docker/podman",
 		Installed: false,
 		Error:     fmt.Errorf("neither docker nor podman found"),
+		Severity:  SeverityFail,
+	}
+}
+
+// CheckContainerDaemon checks whether the daemon for the given container runtime (docker or podman) is running.
+func CheckContainerDaemon(r Runner, runtimeName string) CheckResult {
+	res := CheckResult{Name: fmt.Sprintf("%s Daemon", runtimeName), Installed: true}
+	if err := r.Run(runtimeName, "info"); err != nil {
+		res.Running = false
+		res.Severity = SeverityFail
+		if runtimeName == "podman" {
+			// Note: Podman on Linux often runs rootless/daemonless
+			res.Error = fmt.Errorf("not running or not configured")
+			res.Remediation = "Run `podman machine start` (or start the podman service), or enable remote build with `azd doctor configure remote-build`"
+		} else {
+			res.Error = fmt.Errorf("daemon not running")
+			res.Remediation = "Start Docker Desktop (or the docker service), or enable remote build with `azd doctor configure remote-build`"
+		}
+		return res
 	}
+
+	res.Running = true
+	res.Version = "Running"
+	res.Severity = SeverityPass
+	return res
 }
 
-func CheckNode() CheckResult {
-	return CheckTool("node", "--version")
+func CheckNode(r Runner) CheckResult {
+	return CheckTool(r, "node", "--version")
 }
 
-func CheckPython() CheckResult {
-	return CheckPythonWithOS(runtime.GOOS)
+func CheckPython(r Runner) CheckResult {
+	return CheckPythonWithOS(r, runtime.GOOS)
 }
 
-// CheckPythonWithOS checks for Python with OS-specific command priority
-func CheckPythonWithOS(goos string) CheckResult {
+// CheckPythonWithOS checks for Python 3 with OS-specific command priority.
+// A command that resolves to Python 2 is skipped in favor of the other one.
+func CheckPythonWithOS(r Runner, goos string) CheckResult {
 	var primaryCmd, secondaryCmd string
 
 	switch goos {
@@ -171,59 +344,71 @@ func CheckPythonWithOS(goos string) CheckResult {
 		secondaryCmd = "python"
 	}
 
-	res := CheckTool(primaryCmd, "--version")
-	if !res.Installed {
-		res = CheckTool(secondaryCmd, "--version")
-		if res.Installed {
-			// Update name to show which command worked
-			res.Name = secondaryCmd
+	var python2 *CheckResult
+	for _, cmd := range []string{primaryCmd, secondaryCmd} {
+		res := CheckTool(r, cmd, "--version")
+		if !res.Installed {
+			continue
 		}
-	} else {
-		res.Name = primaryCmd
+		// Update name to show which command worked
+		res.Name = cmd
+		// Python 2 prints its version to stderr, so an empty version also means Python 2.
+		if v, err := ParseVersion(res.Version); err != nil || v.Major < 3 {
+			if python2 == nil {
+				python2 = &res
+			}
+			continue
+		}
+		return res
 	}
 
-	if !res.Installed {
-		res.Name = "python"
+	if python2 != nil {
+		res := *python2
+		res.Error = fmt.Errorf("%s is Python 2, Python 3 is required", res.Name)
+		res.Severity = SeverityFail
+		res.Remediation = "Install Python 3 and make sure `python3` (or `python` on Windows) is on PATH"
+		return res
 	}
 
-	return res
+	return CheckResult{Name: "python", Installed: false, Error: fmt.Errorf("neither %s nor %s found", primaryCmd, secondaryCmd),
+		Severity: SeverityFail}
 }
 
-func CheckDotNet() CheckResult {
-	return CheckTool("dotnet", "--version")
+func CheckDotNet(r Runner) CheckResult {
+	return CheckTool(r, "dotnet", "--version")
 }
 
-func CheckBash() CheckResult {
-	return CheckBashWithOS(runtime.GOOS)
+func CheckBash(r Runner) CheckResult {
+	return CheckBashWithOS(r, runtime.GOOS)
 }
 
 // CheckBashWithOS checks for Bash with OS-specific expectations
-func CheckBashWithOS(goos string) CheckResult {
+func CheckBashWithOS(r Runner, goos string) CheckResult {
 	switch goos {
 	case "windows":
 		// On Windows, bash might be from Git Bash, WSL, or Cygwin
 		// It's optional but common for development
-		res := CheckTool("bash", "--version")
+		res := CheckTool(r, "bash", "--version")
 		res.Name = "bash"
 		return res
 	case "darwin", "linux":
 		// On macOS and Linux, bash is standard
-		res := CheckTool("bash", "--version")
+		res := CheckTool(r, "bash", "--version")
 		res.Name = "bash"
 		return res
 	default:
-		res := CheckTool("bash", "--version")
+		res := CheckTool(r, "bash", "--version")
 		res.Name = "bash"
 		return res
 	}
 }
 
-func CheckPwsh() CheckResult {
-	return CheckPwshWithOS(runtime.GOOS)
+func CheckPwsh(r Runner) CheckResult {
+	return CheckPwshWithOS(r, runtime.GOOS)
 }
 
 // CheckPwshWithOS checks for PowerShell with OS-specific logic
-func CheckPwshWithOS(goos string) CheckResult {
+func CheckPwshWithOS(r Runner, goos string) CheckResult {
 	var primaryCmd, secondaryCmd string
 
 	switch goos {
@@ -241,9 +426,9 @@ func CheckPwshWithOS(goos string) CheckResult {
 		secondaryCmd = "powershell"
 	}
 
-	res := CheckTool(primaryCmd, "--version")
+	res := CheckTool(r, primaryCmd, "--version")
 	if !res.Installed && secondaryCmd != "" {
-		res = CheckTool(secondaryCmd, "--version")
+		res = CheckTool(r, secondaryCmd, "--version")
 		if res.Installed {
 			res.Name = secondaryCmd
 		}
@@ -258,24 +443,24 @@ func CheckPwshWithOS(goos string) CheckResult {
 	return res
 }
 
-func CheckAzureFunctionsCoreTools() CheckResult {
-	return CheckTool("func", "--version")
+func CheckAzureFunctionsCoreTools(r Runner) CheckResult {
+	return CheckTool(r, "func", "--version")
 }
 
-func CheckSwaCli() CheckResult {
-	return CheckTool("swa", "--version")
+func CheckSwaCli(r Runner) CheckResult {
+	return CheckTool(r, "swa", "--version")
 }
 
-func CheckGit() CheckResult {
-	return CheckTool("git", "--version")
+func CheckGit(r Runner) CheckResult {
+	return CheckTool(r, "git", "--version")
 }
 
-func CheckGh() CheckResult {
-	return CheckTool("gh", "--version")
+func CheckGh(r Runner) CheckResult {
+	return CheckTool(r, "gh", "--version")
 }
 
-func CheckTerraform() CheckResult {
-	return CheckTool("terraform", "--version")
+func CheckTerraform(r Runner) CheckResult {
+	return CheckTool(r, "terraform", "--version")
 }
 
 type AzdExtension struct {
@@ -284,8 +469,8 @@ type AzdExtension struct {
 	Version string `json:"version"`
 }
 
-func GetInstalledExtensions() ([]AzdExtension, error) {
-	out, err := CommandRunner.Output("azd", "extension", "list", "--installed", "--output", "json")
+func GetInstalledExtensions(r Runner) ([]AzdExtension, error) {
+	out, err := r.Output("azd", "extension", "list", "--installed", "--output", "json")
 	if err != nil {
 		return nil, err
 	}
@@ -307,23 +492,29 @@ func CheckExtension(extensions []AzdExtension, id, requiredRange string) CheckRe
 		}
 	}
 
+	name := "extension " + id
 	if !found {
-		return CheckResult{Name: "extension " + id, Installed: false, Error: fmt.Errorf("extension not installed")}
+		return CheckResult{Name: name, Installed: false, Error: fmt.Errorf("extension not installed"),
+			Severity: SeverityFail, Remediation: fmt.Sprintf("Run `azd extension install %s`", id)}
 	}
 
 	if requiredRange != "" {
+		upgrade := fmt.Sprintf("Run `azd extension upgrade %s` to get a version matching %s", id, requiredRange)
 		v, err := semver.Parse(installedVer)
 		if err != nil {
-			return CheckResult{Name: "extension " + id, Installed: true, Version: installedVer, Error: fmt.Errorf("invalid installed version format: %w", err)}
+			return CheckResult{Name: name, Installed: true, Version: installedVer, Error: fmt.Errorf("invalid installed version format: %w", err),
+				Severity: SeverityFail, Remediation: upgrade}
 		}
 		expectedRange, err := semver.ParseRange(requiredRange)
 		if err != nil {
-			return CheckResult{Name: "extension " + id, Installed: true, Version: installedVer, Error: fmt.Errorf("invalid required version range: %w", err)}
+			return CheckResult{Name: name, Installed: true, Version: installedVer, Error: fmt.Errorf("invalid required version range: %w", err),
+				Severity: SeverityFail, Remediation: "Fix requiredVersions.extensions in azure.yaml"}
 		}
 		if !expectedRange(v) {
-			return CheckResult{Name: "extension " + id, Installed: true, Version: installedVer, Error: fmt.Errorf("version %s does not satisfy range %s", installedVer, requiredRange)}
+			return CheckResult{Name: name, Installed: true, Version: installedVer, Error: fmt.Errorf("version %s does not satisfy range %s", installedVer, requiredRange),
+				Severity: SeverityFail, Remediation: upgrade}
 		}
 	}
 
-	return CheckResult{Name: "extension " + id, Installed: true, Version: installedVer, Running: true}
+	return CheckResult{Name: name, Installed: true, Version: installedVer, Running: true, Severity: SeverityPass}
 }
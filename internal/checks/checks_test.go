@@ -30,6 +30,16 @@ func (m *MockRunner) Run(name string, args ...string) error {
 	return nil
 }
 
+// DirMockRunner is a MockRunner that also runs commands in a directory (DirRunner).
+type DirMockRunner struct {
+	MockRunner
+	OutputInDirFunc func(dir, name string, args ...string) ([]byte, error)
+}
+
+func (m *DirMockRunner) OutputInDir(_ context.Context, dir, name string, args ...string) ([]byte, error) {
+	return m.OutputInDirFunc(dir, name, args...)
+}
+
 // MockAzdClient implements IAzdClient
 type MockAzdClient struct {
 	DeploymentClient azdext.DeploymentServiceClient
@@ -181,18 +191,14 @@ func (m *MockProjectService) UnsetServiceConfig(ctx context.Context, in *azdext.
 }
 
 func TestCheckTool(t *testing.T) {
-	// Save original runner and restore after test
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Tool Installed", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				return []byte("1.0.0\n"), nil
 			},
 		}
 
-		res := CheckTool("test-tool", "--version")
+		res := CheckTool(mock, "test-tool", "--version")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "1.0.0", res.Version)
 		assert.True(t, res.Running)
@@ -200,13 +206,13 @@ func TestCheckTool(t *testing.T) {
 	})
 
 	t.Run("Tool Not Installed", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				return nil, fmt.Errorf("executable file not found in $PATH")
 			},
 		}
 
-		res := CheckTool("test-tool", "--version")
+		res := CheckTool(mock, "test-tool", "--version")
 		assert.False(t, res.Installed)
 		assert.Error(t, res.Error)
 	})
@@ -214,12 +220,8 @@ func TestCheckTool(t *testing.T) {
 
 func TestCheckAzdLogin(t *testing.T) {
 	ctx := context.Background()
-	// Save original runner and restore after test
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Logged In", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "azd" && args[0] == "auth" && args[1] == "login" && args[2] == "--check-status" {
 					return []byte("Logged in to Azure as user@example.com\n"), nil
@@ -232,14 +234,14 @@ func TestCheckAzdLogin(t *testing.T) {
 		// The function signature still requires it, so we pass a mock
 		mockClient := &MockAzdClient{}
 
-		res := CheckAzdLogin(ctx, mockClient)
+		res := CheckAzdLogin(ctx, mock, mockClient)
 		assert.True(t, res.Installed)
 		assert.True(t, res.Running)
 		assert.Contains(t, res.Version, "Logged in to Azure as user@example.com")
 	})
 
 	t.Run("Not Logged In (Error)", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "azd" && args[0] == "auth" && args[1] == "login" && args[2] == "--check-status" {
 					return []byte("Not logged in, run `azd auth login` to login to Azure\n"), fmt.Errorf("exit status 1")
@@ -250,7 +252,7 @@ func TestCheckAzdLogin(t *testing.T) {
 
 		mockClient := &MockAzdClient{}
 
-		res := CheckAzdLogin(ctx, mockClient)
+		res := CheckAzdLogin(ctx, mock, mockClient)
 		assert.True(t, res.Installed)
 		assert.False(t, res.Running)
 		assert.Contains(t, res.Version, "Not logged in")
@@ -297,11 +299,8 @@ func TestCheckAzdInit(t *testing.T) {
 }
 
 func TestCheckSwaCli(t *testing.T) {
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Installed", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "swa" && args[0] == "--version" {
 					return []byte("1.1.0\n"), nil
@@ -309,28 +308,25 @@ func TestCheckSwaCli(t *testing.T) {
 				return nil, fmt.Errorf("unexpected command")
 			},
 		}
-		res := CheckSwaCli()
+		res := CheckSwaCli(mock)
 		assert.True(t, res.Installed)
 		assert.Equal(t, "1.1.0", res.Version)
 	})
 
 	t.Run("Not Installed", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				return nil, fmt.Errorf("not found")
 			},
 		}
-		res := CheckSwaCli()
+		res := CheckSwaCli(mock)
 		assert.False(t, res.Installed)
 	})
 }
 
 func TestCheckTerraform(t *testing.T) {
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Installed", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "terraform" && args[0] == "--version" {
 					return []byte("Terraform v1.5.0\n"), nil
@@ -338,9 +334,9 @@ func TestCheckTerraform(t *testing.T) {
 				return nil, fmt.Errorf("unexpected command")
 			},
 		}
-		res := CheckTerraform()
+		res := CheckTerraform(mock)
 		assert.True(t, res.Installed)
-		assert.Contains(t, res.Version, "v1.5.0")
+		assert.Equal(t, "1.5.0", res.Version)
 	})
 }
 
@@ -371,11 +367,8 @@ func TestCheckExtension(t *testing.T) {
 }
 
 func TestCheckDockerWithOS(t *testing.T) {
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Docker on macOS", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "docker" && args[0] == "--version" {
 					return []byte("Docker version 24.0.0"), nil
@@ -390,14 +383,14 @@ func TestCheckDockerWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckDockerWithOS("darwin")
+		res := CheckDockerWithOS(mock, "darwin")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "docker", res.Name)
 		assert.True(t, res.Running)
 	})
 
 	t.Run("Podman on Linux", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "podman" && args[0] == "--version" {
 					return []byte("podman version 4.5.0"), nil
@@ -412,14 +405,14 @@ func TestCheckDockerWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckDockerWithOS("linux")
+		res := CheckDockerWithOS(mock, "linux")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "podman", res.Name)
 		assert.True(t, res.Running)
 	})
 
 	t.Run("Docker daemon not running on Windows", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "docker" && args[0] == "--version" {
 					return []byte("Docker version 24.0.0"), nil
@@ -434,7 +427,7 @@ func TestCheckDockerWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckDockerWithOS("windows")
+		res := CheckDockerWithOS(mock, "windows")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "docker", res.Name)
 		assert.False(t, res.Running)
@@ -442,24 +435,21 @@ func TestCheckDockerWithOS(t *testing.T) {
 	})
 
 	t.Run("Neither docker nor podman found", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				return nil, fmt.Errorf("not found")
 			},
 		}
 
-		res := CheckDockerWithOS("linux")
+		res := CheckDockerWithOS(mock, "linux")
 		assert.False(t, res.Installed)
 		assert.Equal(t, "docker/podman", res.Name)
 	})
 }
 
 func TestCheckPythonWithOS(t *testing.T) {
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Python3 on Linux", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "python3" && args[0] == "--version" {
 					return []byte("Python 3.11.0"), nil
@@ -468,13 +458,13 @@ func TestCheckPythonWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckPythonWithOS("linux")
+		res := CheckPythonWithOS(mock, "linux")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "python3", res.Name)
 	})
 
 	t.Run("Python on Windows", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "python" && args[0] == "--version" {
 					return []byte("Python 3.11.0"), nil
@@ -483,13 +473,13 @@ func TestCheckPythonWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckPythonWithOS("windows")
+		res := CheckPythonWithOS(mock, "windows")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "python", res.Name)
 	})
 
 	t.Run("Fallback from python3 to python on macOS", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "python" && args[0] == "--version" {
 					return []byte("Python 3.11.0"), nil
@@ -498,30 +488,64 @@ func TestCheckPythonWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckPythonWithOS("darwin")
+		res := CheckPythonWithOS(mock, "darwin")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "python", res.Name)
 	})
 
 	t.Run("Python not found", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				return nil, fmt.Errorf("not found")
 			},
 		}
 
-		res := CheckPythonWithOS("linux")
+		res := CheckPythonWithOS(mock, "linux")
 		assert.False(t, res.Installed)
 		assert.Equal(t, "python", res.Name)
 	})
+
+	t.Run("Python 2 behind python is rejected", func(t *testing.T) {
+		mock := &MockRunner{
+			OutputFunc: func(name string, args ...string) ([]byte, error) {
+				if name == "python" {
+					// Python 2 prints its version to stderr.
+					return []byte(""), nil
+				}
+				return nil, fmt.Errorf("not found")
+			},
+		}
+
+		res := CheckPythonWithOS(mock, "windows")
+		assert.True(t, res.Installed)
+		assert.Equal(t, SeverityFail, res.Status())
+		assert.Contains(t, res.Error.Error(), "Python 2")
+		assert.Contains(t, res.Remediation, "Python 3")
+	})
+
+	t.Run("Python 3 preferred over Python 2", func(t *testing.T) {
+		mock := &MockRunner{
+			OutputFunc: func(name string, args ...string) ([]byte, error) {
+				switch name {
+				case "python":
+					return []byte("Python 2.7.18"), nil
+				case "python3":
+					return []byte("Python 3.11.0"), nil
+				}
+				return nil, fmt.Errorf("not found")
+			},
+		}
+
+		res := CheckPythonWithOS(mock, "windows")
+		assert.Equal(t, SeverityPass, res.Status())
+		assert.Equal(t, "python3", res.Name)
+		assert.Equal(t, "3.11.0", res.Version)
+	})
 }
 
 func TestCheckPwshWithOS(t *testing.T) {
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Pwsh on Windows", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "pwsh" && args[0] == "--version" {
 					return []byte("PowerShell 7.4.0"), nil
@@ -530,13 +554,13 @@ func TestCheckPwshWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckPwshWithOS("windows")
+		res := CheckPwshWithOS(mock, "windows")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "pwsh", res.Name)
 	})
 
 	t.Run("Fallback to powershell on Windows", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "powershell" && args[0] == "--version" {
 					return []byte("5.1.0"), nil
@@ -545,13 +569,13 @@ func TestCheckPwshWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckPwshWithOS("windows")
+		res := CheckPwshWithOS(mock, "windows")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "powershell", res.Name)
 	})
 
 	t.Run("Pwsh on macOS", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "pwsh" && args[0] == "--version" {
 					return []byte("PowerShell 7.4.0"), nil
@@ -560,30 +584,27 @@ func TestCheckPwshWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckPwshWithOS("darwin")
+		res := CheckPwshWithOS(mock, "darwin")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "pwsh", res.Name)
 	})
 
 	t.Run("PowerShell not found on Linux", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				return nil, fmt.Errorf("not found")
 			},
 		}
 
-		res := CheckPwshWithOS("linux")
+		res := CheckPwshWithOS(mock, "linux")
 		assert.False(t, res.Installed)
 		assert.Equal(t, "pwsh/powershell", res.Name)
 	})
 }
 
 func TestCheckBashWithOS(t *testing.T) {
-	origRunner := CommandRunner
-	defer func() { CommandRunner = origRunner }()
-
 	t.Run("Bash on Linux", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "bash" && args[0] == "--version" {
 					return []byte("GNU bash, version 5.1.0"), nil
@@ -592,13 +613,13 @@ func TestCheckBashWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckBashWithOS("linux")
+		res := CheckBashWithOS(mock, "linux")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "bash", res.Name)
 	})
 
 	t.Run("Bash on Windows (Git Bash)", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				if name == "bash" && args[0] == "--version" {
 					return []byte("GNU bash, version 4.4.0"), nil
@@ -607,20 +628,62 @@ func TestCheckBashWithOS(t *testing.T) {
 			},
 		}
 
-		res := CheckBashWithOS("windows")
+		res := CheckBashWithOS(mock, "windows")
 		assert.True(t, res.Installed)
 		assert.Equal(t, "bash", res.Name)
 	})
 
 	t.Run("Bash not found on Windows", func(t *testing.T) {
-		CommandRunner = &MockRunner{
+		mock := &MockRunner{
 			OutputFunc: func(name string, args ...string) ([]byte, error) {
 				return nil, fmt.Errorf("not found")
 			},
 		}
 
-		res := CheckBashWithOS("windows")
+		res := CheckBashWithOS(mock, "windows")
 		assert.False(t, res.Installed)
 		assert.Equal(t, "bash", res.Name)
 	})
 }
+
+func TestCheckResultStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   CheckResult
+		expected Severity
+	}{
+		{"Explicit severity wins", CheckResult{Installed: false, Severity: SeverityWarn}, SeverityWarn},
+		{"Installed and running", CheckResult{Installed: true, Running: true}, SeverityPass},
+		{"Not installed", CheckResult{Installed: false}, SeverityFail},
+		{"Installed with error", CheckResult{Installed: true, Error: fmt.Errorf("bad version")}, SeverityFail},
+		{"Daemon not running", CheckResult{Installed: true, HasDaemon: true, Running: false}, SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.result.Status())
+			assert.Equal(t, tt.expected == SeverityFail, tt.result.Failed())
+		})
+	}
+}
+
+// pathMockRunner is a MockRunner that also resolves executable paths.
+type pathMockRunner struct {
+	MockRunner
+}
+
+func (m *pathMockRunner) LookPath(name string) (string, error) {
+	return "/usr/bin/" + name, nil
+}
+
+func TestCheckToolPath(t *testing.T) {
+	output := func(name string, args ...string) ([]byte, error) {
+		return []byte("2.0.0\n"), nil
+	}
+
+	res := CheckTool(&pathMockRunner{MockRunner{OutputFunc: output}}, "git", "--version")
+	assert.Equal(t, "/usr/bin/git", res.Path)
+
+	res = CheckTool(&MockRunner{OutputFunc: output}, "git", "--version")
+	assert.Empty(t, res.Path, "runners without PathResolver leave the path empty")
+}
@@ -0,0 +1,73 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// constrainedCheck applies a version range declared in `x-doctor.tools` on top of a registry check.
+type constrainedCheck struct {
+	Check
+	requiredRange string
+	location      *Location
+}
+
+func (c *constrainedCheck) DependsOn() []string {
+	if dc, ok := c.Check.(DependentCheck); ok {
+		return dc.DependsOn()
+	}
+	return nil
+}
+
+func (c *constrainedCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := c.Check.Run(ctx, r)
+	if res.Status() != SeverityPass {
+		return res
+	}
+	res = CheckVersionRange(res, c.requiredRange)
+	if res.Status() != SeverityPass {
+		res.Remediation = fmt.Sprintf("Install %s %s (required by x-doctor.tools in azure.yaml)", c.Description(), c.requiredRange)
+		res.Location = c.location
+	}
+	return res
+}
+
+// unknownToolCheck reports an `x-doctor.tools` entry that doesn't match a check.
+type unknownToolCheck struct {
+	tool     string
+	location *Location
+}
+
+func (c *unknownToolCheck) ID() string          { return "x-doctor.tools:" + c.tool }
+func (c *unknownToolCheck) Category() Category  { return CategoryAzd }
+func (c *unknownToolCheck) Description() string { return "Tool " + c.tool }
+
+func (c *unknownToolCheck) Applies(config *AzureYaml) bool {
+	if config == nil {
+		return false
+	}
+	_, ok := config.Doctor.Tools[c.tool]
+	return ok
+}
+
+func (c *unknownToolCheck) Run(context.Context, Runner) CheckResult {
+	return CheckResult{
+		Name:        "Unknown Tool",
+		Version:     c.tool,
+		Error:       fmt.Errorf("x-doctor.tools has no check named %q", c.tool),
+		Severity:    SeverityWarn,
+		Remediation: "Use a check ID such as node, python, dotnet, func, swa, terraform, docker, git or gh",
+		Location:    c.location,
+	}
+}
+
+// toolConstraints returns the sorted tool IDs declared in `x-doctor.tools`.
+func toolConstraints(config *AzureYaml) []string {
+	ids := make([]string, 0, len(config.Doctor.Tools))
+	for id := range config.Doctor.Tools {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,120 @@
+package checks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolConstraints(t *testing.T) {
+	content := `name: test-project
+x-doctor:
+  tools:
+    node: ">=20"
+    dotnet: "8.x"
+    terraform: ">=1.6"
+    internal-cli: ">=1"
+infra:
+  provider: terraform
+services:
+  web:
+    language: js
+    host: staticwebapp
+`
+	path := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	config, err := LoadProjectConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"node": ">=20", "dotnet": "8.x", "terraform": ">=1.6", "internal-cli": ">=1"}, config.Doctor.Tools)
+
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			switch name {
+			case "node":
+				return []byte("v18.19.0"), nil
+			case "dotnet":
+				return []byte("8.0.100"), nil
+			case "terraform":
+				return []byte("Terraform v1.7.5\non linux_amd64"), nil
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+	planner := NewPlannerWithOS(DefaultRegistryWithOS("linux"), "linux")
+
+	t.Run("Declared tools are required", func(t *testing.T) {
+		plan := planner.Plan(config, PlanOptions{})
+		assert.Equal(t, []string{"azd", "git", "gh", "node (web)", "dotnet", "swa (web)", "terraform", "x-doctor.tools:internal-cli"},
+			requirementStrings(plan))
+	})
+
+	t.Run("Declared tools follow the command scope", func(t *testing.T) {
+		plan := planner.Plan(config, PlanOptions{Command: "provision"})
+		assert.Equal(t, []string{"azd", "git", "gh", "terraform", "x-doctor.tools:internal-cli"}, requirementStrings(plan))
+	})
+
+	t.Run("Ranges are enforced", func(t *testing.T) {
+		plan := planner.Plan(config, PlanOptions{})
+		results := NewExecutor(mock, 2).Run(context.Background(), plan.Checks())
+		byID := make(map[string]CheckResult)
+		for _, res := range results {
+			byID[res.ID] = res
+		}
+
+		node := byID["node"]
+		assert.Equal(t, SeverityFail, node.Status(), "node 18 passes the built-in range but not >=20")
+		assert.Contains(t, node.Error.Error(), "does not satisfy >=20")
+		assert.Contains(t, node.Remediation, "x-doctor.tools")
+		require.NotNil(t, node.Location)
+		assert.Equal(t, 4, node.Location.Line)
+
+		assert.Equal(t, SeverityPass, byID["dotnet"].Status())
+		assert.Equal(t, SeverityPass, byID["terraform"].Status())
+
+		unknown := byID["x-doctor.tools:internal-cli"]
+		assert.Equal(t, SeverityWarn, unknown.Status())
+		require.NotNil(t, unknown.Location)
+		assert.Equal(t, 7, unknown.Location.Line)
+	})
+}
+
+func TestConstrainedCheck_RangeSyntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		output   string
+		rng      string
+		expected Severity
+	}{
+		{name: "Bare major", tool: "node", output: "v20.11.1", rng: "20", expected: SeverityPass},
+		{name: "Bare major too new", tool: "node", output: "v22.1.0", rng: "20", expected: SeverityFail},
+		{name: "Bare minor", tool: "terraform", output: "Terraform v1.6.6", rng: "1.6", expected: SeverityPass},
+		{name: "Caret", tool: "node", output: "v20.11.1", rng: "^20", expected: SeverityPass},
+		{name: "Caret too old", tool: "node", output: "v18.19.0", rng: "^20", expected: SeverityFail},
+		{name: "Tilde", tool: "terraform", output: "Terraform v1.6.6", rng: "~1.6", expected: SeverityPass},
+		{name: "Tilde too new", tool: "terraform", output: "Terraform v1.7.5", rng: "~1.6", expected: SeverityFail},
+	}
+
+	registry := DefaultRegistryWithOS("linux")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := registry.Get(tt.tool)
+			require.True(t, ok)
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					return []byte(tt.output), nil
+				},
+			}
+
+			res := (&constrainedCheck{Check: c, requiredRange: tt.rng}).Run(context.Background(), mock)
+			assert.Equal(t, tt.expected, res.Status(), res.Error)
+			if tt.expected == SeverityFail {
+				assert.ErrorContains(t, res.Error, "does not satisfy "+tt.rng)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CategoryCustom groups the project-specific checks declared in `x-doctor.checks`.
+const CategoryCustom Category = "custom"
+
+// customCheck runs a CustomCheck declared in azure.yaml through the Runner.
+type customCheck struct {
+	def CustomCheck
+	// id is the ID of the check, unique among the declared checks; defaults to def.CheckID().
+	id       string
+	location *Location
+}
+
+func (c *customCheck) ID() string {
+	if c.id != "" {
+		return "custom:" + c.id
+	}
+	return "custom:" + c.def.CheckID()
+}
+
+func (c *customCheck) Category() Category { return CategoryCustom }
+
+func (c *customCheck) Description() string {
+	if c.def.Name != "" {
+		return c.def.Name
+	}
+	if c.def.ID != "" {
+		return c.def.ID
+	}
+	return c.commandLine()
+}
+
+func (c *customCheck) Applies(config *AzureYaml) bool {
+	return config != nil
+}
+
+func (c *customCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := CheckResult{Name: c.Description(), Location: c.location}
+	if err := c.def.Validate(); err != nil {
+		res.Error = err
+		res.Severity = SeverityFail
+		res.Remediation = "Fix the check in x-doctor.checks in azure.yaml"
+		return res
+	}
+
+	out, err := runnerOutput(ctx, r, c.def.Command, c.def.Args...)
+	exitCode := 0
+	if err != nil {
+		var exitErr interface{ ExitCode() int }
+		if !errors.As(err, &exitErr) {
+			res.Error = fmt.Errorf("failed to run %s: %w", c.def.Command, err)
+			res.Severity = c.def.failSeverity()
+			res.Remediation = c.remediation()
+			return res
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	res.Installed = true
+	res.Version = cleanVersion(c.def.Command, string(out))
+	res.Path = runnerLookPath(r, c.def.Command)
+
+	switch {
+	case exitCode != c.def.ExitCode:
+		res.Error = fmt.Errorf("%s exited with code %d, expected %d", c.commandLine(), exitCode, c.def.ExitCode)
+	case c.def.Output != "" && !regexp.MustCompile(c.def.Output).Match(out):
+		res.Error = fmt.Errorf("output of %s does not match %q", c.commandLine(), c.def.Output)
+	default:
+		res.Severity = SeverityPass
+		return res
+	}
+	res.Severity = c.def.failSeverity()
+	res.Remediation = c.remediation()
+	return res
+}
+
+func (c *customCheck) commandLine() string {
+	return strings.Join(append([]string{c.def.Command}, c.def.Args...), " ")
+}
+
+func (c *customCheck) remediation() string {
+	if c.def.Remediation != "" {
+		return c.def.Remediation
+	}
+	return fmt.Sprintf("Make sure `%s` succeeds (declared in x-doctor.checks in azure.yaml)", c.commandLine())
+}
+
+// CheckID returns the ID of the check, defaulting to its command.
+func (c CustomCheck) CheckID() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return c.Command
+}
+
+// Validate reports configuration errors such as a missing command or an invalid regex.
+func (c CustomCheck) Validate() error {
+	if c.Command == "" {
+		return fmt.Errorf("custom check %q has no command", c.CheckID())
+	}
+	if c.Output != "" {
+		if _, err := regexp.Compile(c.Output); err != nil {
+			return fmt.Errorf("custom check %q has an invalid output pattern: %w", c.CheckID(), err)
+		}
+	}
+	switch c.Severity {
+	case "", SeverityFail, SeverityWarn:
+	default:
+		return fmt.Errorf("custom check %q has an invalid severity %q. Must be one of: fail, warn", c.CheckID(), c.Severity)
+	}
+	return nil
+}
+
+// AppliesTo reports whether the check runs for the given azd command. `up` runs the
+// checks of every command and an empty command (as used by `check`) runs all checks.
+func (c CustomCheck) AppliesTo(command string) bool {
+	if len(c.Commands) == 0 || command == "" || command == "up" {
+		return true
+	}
+	for _, cmd := range c.Commands {
+		if cmd == command {
+			return true
+		}
+	}
+	return false
+}
+
+func (c CustomCheck) failSeverity() Severity {
+	if c.Severity == SeverityWarn {
+		return SeverityWarn
+	}
+	return SeverityFail
+}
+
+// customChecks returns the custom checks declared in azure.yaml for the command.
+func customChecks(config *AzureYaml, command string) []Requirement {
+	var list []Requirement
+	ids := customCheckIDs(config.Doctor.Checks)
+	for i, def := range config.Doctor.Checks {
+		if !def.AppliesTo(command) {
+			continue
+		}
+		loc := config.Location("x-doctor", "checks", strconv.Itoa(i))
+		list = append(list, Requirement{Check: &customCheck{def: def, id: ids[i], location: loc}, Location: loc})
+	}
+	return list
+}
+
+// customCheckIDs returns the IDs of the declared checks. Results are keyed by check ID, so
+// checks sharing one, such as several checks of the same command without an id, get a
+// numeric suffix in declaration order: az, az-2, az-3.
+func customCheckIDs(defs []CustomCheck) []string {
+	count := make(map[string]int)
+	for _, def := range defs {
+		count[def.CheckID()]++
+	}
+	taken := make(map[string]bool)
+	ids := make([]string, len(defs))
+	for i, def := range defs {
+		id := def.CheckID()
+		for n := 2; taken[id] || (id != def.CheckID() && count[id] > 0); n++ {
+			id = fmt.Sprintf("%s-%d", def.CheckID(), n)
+		}
+		taken[id] = true
+		ids[i] = id
+	}
+	return ids
+}
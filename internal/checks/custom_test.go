@@ -0,0 +1,207 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exitCodeError mimics *exec.ExitError for commands that ran and exited non-zero.
+type exitCodeError struct{ code int }
+
+func (e *exitCodeError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+func (e *exitCodeError) ExitCode() int { return e.code }
+
+func TestCustomCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      CustomCheck
+		output   string
+		err      error
+		expected Severity
+		errMsg   string
+	}{
+		{
+			name:     "Exit code 0 passes",
+			def:      CustomCheck{ID: "internal", Command: "internal-cli", Args: []string{"--version"}},
+			output:   "internal-cli 2.1.0",
+			expected: SeverityPass,
+		},
+		{
+			name:     "Unexpected exit code fails",
+			def:      CustomCheck{ID: "internal", Command: "internal-cli", Args: []string{"status"}},
+			err:      &exitCodeError{code: 3},
+			expected: SeverityFail,
+			errMsg:   "internal-cli status exited with code 3, expected 0",
+		},
+		{
+			name:     "Expected non-zero exit code passes",
+			def:      CustomCheck{ID: "cert", Command: "certutil", ExitCode: 1},
+			err:      &exitCodeError{code: 1},
+			expected: SeverityPass,
+		},
+		{
+			name:     "Output matches",
+			def:      CustomCheck{ID: "az-ext", Command: "az", Args: []string{"extension", "list"}, Output: `"name":\s*"containerapp"`},
+			output:   `[{"name": "containerapp"}]`,
+			expected: SeverityPass,
+		},
+		{
+			name:     "Output mismatch fails",
+			def:      CustomCheck{ID: "az-ext", Command: "az", Args: []string{"extension", "list"}, Output: `"name":\s*"containerapp"`},
+			output:   `[]`,
+			expected: SeverityFail,
+			errMsg:   "does not match",
+		},
+		{
+			name:     "Warn severity",
+			def:      CustomCheck{ID: "internal", Command: "internal-cli", Severity: SeverityWarn},
+			err:      &exitCodeError{code: 1},
+			expected: SeverityWarn,
+			errMsg:   "exited with code 1",
+		},
+		{
+			name:     "Missing command fails with the declared severity",
+			def:      CustomCheck{ID: "internal", Command: "internal-cli", Severity: SeverityWarn},
+			err:      fmt.Errorf("executable file not found in $PATH"),
+			expected: SeverityWarn,
+			errMsg:   "failed to run internal-cli",
+		},
+		{
+			name:     "Invalid regex",
+			def:      CustomCheck{ID: "internal", Command: "internal-cli", Output: "("},
+			expected: SeverityFail,
+			errMsg:   "invalid output pattern",
+		},
+		{
+			name:     "Invalid severity",
+			def:      CustomCheck{ID: "internal", Command: "internal-cli", Severity: "error"},
+			expected: SeverityFail,
+			errMsg:   "invalid severity",
+		},
+		{
+			name:     "Missing command",
+			def:      CustomCheck{ID: "internal"},
+			expected: SeverityFail,
+			errMsg:   "has no command",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					assert.Equal(t, tt.def.Command, name)
+					assert.Equal(t, tt.def.Args, args)
+					return []byte(tt.output), tt.err
+				},
+			}
+			res := (&customCheck{def: tt.def}).Run(context.Background(), mock)
+			assert.Equal(t, tt.expected, res.Status())
+			if tt.errMsg != "" {
+				require.Error(t, res.Error)
+				assert.Contains(t, res.Error.Error(), tt.errMsg)
+				assert.NotEmpty(t, res.Remediation)
+			} else {
+				assert.NoError(t, res.Error)
+			}
+		})
+	}
+}
+
+func TestPlanCustomChecks(t *testing.T) {
+	content := `name: test-project
+x-doctor:
+  checks:
+    - id: internal-cli
+      name: Internal CLI
+      command: internal-cli
+      args: ["--version"]
+      output: "^internal-cli 2\\."
+      remediation: Install internal-cli 2.x from the internal feed
+    - id: signing-cert
+      command: certutil
+      args: ["-verifystore", "My", "contoso"]
+      severity: warn
+      commands: [deploy]
+`
+	path := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	config, err := LoadProjectConfig(path)
+	require.NoError(t, err)
+	require.Len(t, config.Doctor.Checks, 2)
+
+	planner := NewPlannerWithOS(NewRegistry(), "linux")
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{command: "", expected: []string{"custom:internal-cli", "custom:signing-cert"}},
+		{command: "up", expected: []string{"custom:internal-cli", "custom:signing-cert"}},
+		{command: "deploy", expected: []string{"custom:internal-cli", "custom:signing-cert"}},
+		{command: "provision", expected: []string{"custom:internal-cli"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			assert.Equal(t, tt.expected, requirementStrings(planner.Plan(config, PlanOptions{Command: tt.command})))
+		})
+	}
+
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("internal-cli 1.4.0"), nil
+		},
+	}
+	plan := planner.Plan(config, PlanOptions{Command: "provision"})
+	res := NewExecutor(mock, 1).Run(context.Background(), plan.Checks())[0]
+	assert.Equal(t, "custom:internal-cli", res.ID)
+	assert.Equal(t, "Internal CLI", res.Name)
+	assert.Equal(t, SeverityFail, res.Status())
+	assert.Equal(t, "Install internal-cli 2.x from the internal feed", res.Remediation)
+	require.NotNil(t, res.Location)
+	assert.Equal(t, 4, res.Location.Line)
+}
+
+func TestCustomChecksSharingACommand(t *testing.T) {
+	content := `name: test-project
+x-doctor:
+  checks:
+    - command: az
+      args: ["account", "show"]
+    - command: az
+      args: ["extension", "show", "--name", "containerapp"]
+    - id: az-2
+      command: az
+      args: ["bicep", "version"]
+`
+	path := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	config, err := LoadProjectConfig(path)
+	require.NoError(t, err)
+
+	plan := NewPlannerWithOS(NewRegistry(), "linux").Plan(config, PlanOptions{})
+	assert.Equal(t, []string{"custom:az", "custom:az-3", "custom:az-2"}, requirementStrings(plan))
+
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			if args[0] == "extension" {
+				return nil, &exitCodeError{code: 1}
+			}
+			return []byte("{}"), nil
+		},
+	}
+	results := NewExecutor(mock, 1).Run(context.Background(), plan.Checks())
+	require.Len(t, results, 3)
+	assert.Equal(t, "custom:az", results[0].ID)
+	assert.Equal(t, "az account show", results[0].Name)
+	assert.Equal(t, SeverityPass, results[0].Status())
+	assert.Equal(t, "custom:az-3", results[1].ID)
+	assert.Equal(t, "az extension show --name containerapp", results[1].Name)
+	assert.Equal(t, SeverityFail, results[1].Status())
+	assert.Equal(t, "custom:az-2", results[2].ID)
+}
@@ -0,0 +1,566 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver/v4"
+)
+
+// ParseDotNetSDKs parses the output of `dotnet --list-sdks`, e.g.
+// "8.0.100 [/usr/share/dotnet/sdk]", into the installed SDK versions in ascending order.
+func ParseDotNetSDKs(output string) []semver.Version {
+	var sdks []semver.Version
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if v, err := semver.Parse(fields[0]); err == nil {
+			sdks = append(sdks, v)
+		}
+	}
+	semver.Sort(sdks)
+	return sdks
+}
+
+// installedSDKs lazily lists the installed .NET SDKs once per plan.
+type installedSDKs struct {
+	once sync.Once
+	sdks []semver.Version
+	err  error
+}
+
+func (l *installedSDKs) get(ctx context.Context, r Runner) ([]semver.Version, error) {
+	l.once.Do(func() {
+		out, err := runnerOutput(ctx, r, "dotnet", "--list-sdks")
+		if err != nil {
+			l.err = fmt.Errorf("failed to list the .NET SDKs: %w", err)
+			return
+		}
+		l.sdks = ParseDotNetSDKs(string(out))
+	})
+	return l.sdks, l.err
+}
+
+// ParseDotNetWorkloads parses the table printed by `dotnet workload list` into the
+// installed workload IDs and their manifest versions.
+func ParseDotNetWorkloads(output string) map[string]string {
+	workloads := make(map[string]string)
+	table := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "---"):
+			table = true
+		case line == "":
+			if table {
+				return workloads
+			}
+		case table:
+			fields := strings.Fields(line)
+			version := ""
+			if len(fields) > 1 {
+				// The manifest version is printed as <version>/<SDK feature band>.
+				version, _, _ = strings.Cut(fields[1], "/")
+			}
+			workloads[fields[0]] = version
+		}
+	}
+	return workloads
+}
+
+// installedWorkloads lazily lists the installed .NET workloads once per plan.
+type installedWorkloads struct {
+	once      sync.Once
+	workloads map[string]string
+	err       error
+}
+
+func (l *installedWorkloads) get(ctx context.Context, r Runner) (map[string]string, error) {
+	l.once.Do(func() {
+		out, err := runnerOutput(ctx, r, "dotnet", "workload", "list")
+		if err != nil {
+			l.err = fmt.Errorf("failed to list the .NET workloads: %w", err)
+			return
+		}
+		l.workloads = ParseDotNetWorkloads(string(out))
+	})
+	return l.workloads, l.err
+}
+
+// GlobalJSON is the SDK selection of a global.json file.
+type GlobalJSON struct {
+	File string `json:"-"`
+	// Line is the line of the SDK version in File.
+	Line int `json:"-"`
+	SDK  struct {
+		Version         string `json:"version"`
+		RollForward     string `json:"rollForward"`
+		AllowPrerelease *bool  `json:"allowPrerelease"`
+	} `json:"sdk"`
+}
+
+// LoadGlobalJSON reads the global.json that applies to dir: the first one found in dir
+// or its parents, as the dotnet host does. It returns nil if there is none.
+func LoadGlobalJSON(dir string) (*GlobalJSON, error) {
+	path := findUp(dir, "", "global.json")
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	g := &GlobalJSON{File: path, Line: lineOf(content, `"version"`)}
+	if err := json.Unmarshal(content, g); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return g, nil
+}
+
+// SelectSDK returns the installed SDK the dotnet host uses with this global.json,
+// following the rollForward policy. A nil global.json selects the latest SDK.
+func (g *GlobalJSON) SelectSDK(installed []semver.Version) (semver.Version, bool) {
+	if g == nil || g.SDK.Version == "" {
+		if len(installed) == 0 {
+			return semver.Version{}, false
+		}
+		return installed[len(installed)-1], true
+	}
+	requested, err := semver.Parse(g.SDK.Version)
+	if err != nil {
+		return semver.Version{}, false
+	}
+	policy := g.SDK.RollForward
+	if policy == "" {
+		policy = "latestPatch"
+	}
+	allowPrerelease := g.SDK.AllowPrerelease == nil || *g.SDK.AllowPrerelease || len(requested.Pre) > 0
+
+	// The feature band of 8.0.303 is 8.0.3xx.
+	band := func(v semver.Version) [3]uint64 { return [3]uint64{v.Major, v.Minor, v.Patch / 100} }
+	var candidates []semver.Version
+	for _, v := range installed {
+		if v.LT(requested) || (len(v.Pre) > 0 && !allowPrerelease) {
+			continue
+		}
+		var matches bool
+		switch policy {
+		case "disable":
+			matches = v.Equals(requested)
+		case "patch", "latestPatch":
+			matches = band(v) == band(requested)
+		case "feature", "latestFeature":
+			matches = v.Major == requested.Major && v.Minor == requested.Minor
+		case "minor", "latestMinor":
+			matches = v.Major == requested.Major
+		case "major", "latestMajor":
+			matches = true
+		}
+		if matches {
+			candidates = append(candidates, v)
+		}
+	}
+	if len(candidates) == 0 {
+		return semver.Version{}, false
+	}
+
+	// Candidates are sorted. patch takes the requested version when it is installed; the
+	// latest* policies take the latest match; the others take the latest patch of the
+	// lowest matching feature band.
+	selected := candidates[len(candidates)-1]
+	if policy == "patch" && candidates[0].Equals(requested) {
+		selected = candidates[0]
+	}
+	if policy == "feature" || policy == "minor" || policy == "major" {
+		lowest := band(candidates[0])
+		for _, v := range candidates {
+			if band(v) == lowest {
+				selected = v
+			}
+		}
+	}
+	return selected, true
+}
+
+// DotNetProject is the project file of a .NET service.
+type DotNetProject struct {
+	// File is the .csproj, .fsproj or .vbproj file; empty when the service has none.
+	File             string
+	TargetFrameworks []string
+	// Line is the line of the target framework in File.
+	Line int
+
+	// AppHost reports whether the project is a .NET Aspire AppHost.
+	AppHost bool
+	// AppHostLine is the line in File that makes the project an AppHost.
+	AppHostLine int
+	// AspireSDK reports whether the AppHost references Aspire.AppHost.Sdk (Aspire 9 and
+	// later), which is restored from NuGet instead of the Aspire workload.
+	AspireSDK bool
+	// AspireVersion is the version of Aspire.AppHost.Sdk or Aspire.Hosting.AppHost, if set.
+	AspireVersion string
+	// AppHostServices are the names of the resources the AppHost adds that azd deploys as
+	// services, in the order they appear in its source files.
+	AppHostServices []string
+}
+
+// msbuildSDK is an <Sdk> element of a project file.
+type msbuildSDK struct {
+	Name    string `xml:"Name,attr"`
+	Version string `xml:"Version,attr"`
+}
+
+var dotnetProjectExtensions = []string{".csproj", ".fsproj", ".vbproj"}
+
+// LoadDotNetProject reads the project file of a .NET service. path is the `project` of
+// the service: a project file or a directory containing one.
+func LoadDotNetProject(path string) (DotNetProject, error) {
+	file := path
+	if !isDotNetProjectFile(path) {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return DotNetProject{}, nil
+		}
+		file = ""
+		for _, entry := range entries {
+			if !entry.IsDir() && isDotNetProjectFile(entry.Name()) {
+				file = filepath.Join(path, entry.Name())
+				break
+			}
+		}
+		if file == "" {
+			return DotNetProject{}, nil
+		}
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return DotNetProject{}, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var doc struct {
+		Sdk            string       `xml:"Sdk,attr"`
+		Sdks           []msbuildSDK `xml:"Sdk"`
+		PropertyGroups []struct {
+			TargetFramework  string `xml:"TargetFramework"`
+			TargetFrameworks string `xml:"TargetFrameworks"`
+			IsAspireHost     string `xml:"IsAspireHost"`
+		} `xml:"PropertyGroup"`
+		ItemGroups []struct {
+			PackageReferences []struct {
+				Include string `xml:"Include,attr"`
+				Version string `xml:"Version,attr"`
+			} `xml:"PackageReference"`
+		} `xml:"ItemGroup"`
+	}
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return DotNetProject{}, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	project := DotNetProject{File: file}
+	for _, group := range doc.PropertyGroups {
+		for _, tfm := range strings.Split(group.TargetFramework+";"+group.TargetFrameworks, ";") {
+			if tfm = strings.TrimSpace(tfm); tfm != "" {
+				project.TargetFrameworks = append(project.TargetFrameworks, tfm)
+			}
+		}
+		if strings.EqualFold(strings.TrimSpace(group.IsAspireHost), "true") {
+			project.AppHost = true
+			project.AppHostLine = lineOf(content, "<IsAspireHost")
+		}
+	}
+	project.Line = lineOf(content, "<TargetFramework")
+
+	// Aspire 9 adds <Sdk Name="Aspire.AppHost.Sdk" Version="9.0.0" />; later versions use
+	// it as the project SDK, <Project Sdk="Aspire.AppHost.Sdk/13.0.0">.
+	sdks := doc.Sdks
+	if name, version, _ := strings.Cut(doc.Sdk, "/"); name != "" {
+		sdks = append(sdks, msbuildSDK{Name: strings.TrimSpace(name), Version: strings.TrimSpace(version)})
+	}
+	for _, sdk := range sdks {
+		if sdk.Name == aspireAppHostSDK {
+			project.AppHost, project.AspireSDK, project.AspireVersion = true, true, sdk.Version
+			project.AppHostLine = lineOf(content, aspireAppHostSDK)
+		}
+	}
+	// Aspire 8 AppHosts reference the hosting package and build with the workload.
+	for _, group := range doc.ItemGroups {
+		for _, ref := range group.PackageReferences {
+			if ref.Include != "Aspire.Hosting.AppHost" {
+				continue
+			}
+			if !project.AppHost {
+				project.AppHost = true
+				project.AppHostLine = lineOf(content, `"Aspire.Hosting.AppHost"`)
+			}
+			if project.AspireVersion == "" {
+				project.AspireVersion = ref.Version
+			}
+		}
+	}
+
+	if project.AppHost {
+		services, err := appHostServices(filepath.Dir(file))
+		if err != nil {
+			return DotNetProject{}, err
+		}
+		project.AppHostServices = services
+	}
+	return project, nil
+}
+
+const aspireAppHostSDK = "Aspire.AppHost.Sdk"
+
+// appHostResourcePattern matches the resources of an AppHost that azd deploys as services,
+// e.g. builder.AddProject<Projects.Api>("apiservice") or builder.AddDockerfile("web", "../web").
+var appHostResourcePattern = regexp.MustCompile(`\.Add(?:Project|Container|Dockerfile|NpmApp|NodeApp|ViteApp|PythonApp|UvicornApp)\b\s*(?:<[^>]*>)?\s*\(\s*"([^"]+)"`)
+
+// appHostServices returns the names of the resources added by the C# files of an AppHost
+// (Program.cs, AppHost.cs). Resources built from variables aren't found.
+func appHostServices(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var services []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".cs") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for _, m := range appHostResourcePattern.FindAllStringSubmatch(string(content), -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				services = append(services, m[1])
+			}
+		}
+	}
+	return services, nil
+}
+
+func isDotNetProjectFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range dotnetProjectExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+var targetFrameworkPattern = regexp.MustCompile(`^net(?:coreapp)?(\d+)\.(\d+)`)
+
+// TargetFrameworkVersion returns the .NET version of a target framework moniker, e.g.
+// 8.0 for "net8.0" or "net8.0-windows". It returns false for monikers any SDK can build
+// (.NET Framework "net48", "netstandard2.0") or doesn't recognize.
+func TargetFrameworkVersion(tfm string) (major, minor uint64, ok bool) {
+	m := targetFrameworkPattern.FindStringSubmatch(strings.ToLower(tfm))
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.ParseUint(m[1], 10, 64)
+	minor, _ = strconv.ParseUint(m[2], 10, 64)
+	return major, minor, true
+}
+
+// dotnetSDKCheck verifies that the SDK the dotnet host selects for a service (honoring
+// global.json) can build the target frameworks of its project.
+type dotnetSDKCheck struct {
+	service string
+	dir     string
+	project DotNetProject
+	sdks    *installedSDKs
+}
+
+func (c *dotnetSDKCheck) ID() string          { return "dotnet-sdk:" + c.service }
+func (c *dotnetSDKCheck) Category() Category  { return CategoryLanguage }
+func (c *dotnetSDKCheck) Description() string { return fmt.Sprintf("Service %s .NET SDK", c.service) }
+func (c *dotnetSDKCheck) DependsOn() []string { return []string{"dotnet"} }
+
+func (c *dotnetSDKCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isDotNetLanguage(config.Services[c.service].Language)
+}
+
+func (c *dotnetSDKCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := CheckResult{Name: ".NET SDK"}
+	installed, err := c.sdks.get(ctx, r)
+	if err != nil {
+		res.Error = err
+		res.Severity = SeverityFail
+		res.Remediation = "Install the .NET SDK: https://dotnet.microsoft.com/download"
+		return res
+	}
+	res.Installed = true
+
+	global, err := LoadGlobalJSON(c.dir)
+	if err != nil {
+		res.Error = err
+		res.Severity = SeverityFail
+		res.Remediation = "Fix the syntax of global.json"
+		return res
+	}
+	sdk, ok := global.SelectSDK(installed)
+	if !ok && global != nil && global.SDK.Version != "" {
+		policy := global.SDK.RollForward
+		if policy == "" {
+			policy = "latestPatch"
+		}
+		res.Error = fmt.Errorf("no installed SDK matches global.json version %s (rollForward %s)", global.SDK.Version, policy)
+		res.Severity = SeverityFail
+		res.Remediation = fmt.Sprintf("Install .NET SDK %s (https://dotnet.microsoft.com/download) or update %s", global.SDK.Version, global.File)
+		res.Location = &Location{File: global.File, Line: global.Line}
+		return res
+	}
+	if !ok {
+		res.Installed = false
+		res.Error = fmt.Errorf("no .NET SDK installed")
+		res.Severity = SeverityFail
+		res.Remediation = "Install the .NET SDK: https://dotnet.microsoft.com/download"
+		return res
+	}
+	res.Version = sdk.String()
+
+	// The SDK must be at least as new as the newest target framework.
+	var required string
+	var requiredMajor, requiredMinor uint64
+	for _, tfm := range c.project.TargetFrameworks {
+		major, minor, ok := TargetFrameworkVersion(tfm)
+		if ok && (major > requiredMajor || (major == requiredMajor && minor > requiredMinor)) {
+			required, requiredMajor, requiredMinor = tfm, major, minor
+		}
+	}
+	if required != "" && (sdk.Major < requiredMajor || (sdk.Major == requiredMajor && sdk.Minor < requiredMinor)) {
+		channel := fmt.Sprintf("%d.%d", requiredMajor, requiredMinor)
+		if global != nil && global.SDK.Version != "" {
+			res.Error = fmt.Errorf("SDK %s selected by global.json can't build %s", sdk, required)
+			res.Remediation = fmt.Sprintf("Install the .NET %s SDK (https://dotnet.microsoft.com/download/dotnet/%s) and update %s", channel, channel, global.File)
+		} else {
+			res.Error = fmt.Errorf("no installed SDK can build %s (latest is %s)", required, sdk)
+			res.Remediation = fmt.Sprintf("Install the .NET %s SDK: https://dotnet.microsoft.com/download/dotnet/%s", channel, channel)
+		}
+		res.Severity = SeverityFail
+		res.Location = &Location{File: c.project.File, Line: c.project.Line}
+		return res
+	}
+
+	res.Severity = SeverityPass
+	return res
+}
+
+func isDotNetLanguage(language string) bool {
+	return language == "csharp" || language == "fsharp" || language == "dotnet"
+}
+
+// dotnetServiceChecks returns the SDK requirements of a .NET service, and those of the
+// Aspire workload for AppHost projects.
+func dotnetServiceChecks(name string, project DotNetProject, err error, sdks *installedSDKs, workloads *installedWorkloads) []Check {
+	if err != nil {
+		return []Check{&projectFileCheck{service: name, err: err}}
+	}
+	if project.File == "" {
+		return nil
+	}
+	dir := filepath.Dir(project.File)
+	list := []Check{&dotnetSDKCheck{service: name, dir: dir, project: project, sdks: sdks}}
+	if project.AppHost {
+		list = append(list,
+			&aspireCheck{service: name, project: project, workloads: workloads},
+			&appHostServicesCheck{service: name, project: project},
+		)
+	}
+	return list
+}
+
+// aspireCheck verifies that an Aspire AppHost can be built: it either references
+// Aspire.AppHost.Sdk, which NuGet restores, or needs the aspire workload.
+type aspireCheck struct {
+	service   string
+	project   DotNetProject
+	workloads *installedWorkloads
+}
+
+func (c *aspireCheck) ID() string         { return "aspire:" + c.service }
+func (c *aspireCheck) Category() Category { return CategoryLanguage }
+func (c *aspireCheck) Description() string {
+	return fmt.Sprintf("Service %s Aspire AppHost", c.service)
+}
+func (c *aspireCheck) DependsOn() []string { return []string{"dotnet"} }
+
+func (c *aspireCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isDotNetLanguage(config.Services[c.service].Language)
+}
+
+func (c *aspireCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := CheckResult{Name: "Aspire"}
+	if c.project.AspireSDK {
+		res.Installed = true
+		res.Version = strings.TrimSpace(aspireAppHostSDK + " " + c.project.AspireVersion)
+		res.Severity = SeverityPass
+		return res
+	}
+	if dotnet, ok := DependencyResult(ctx, "dotnet"); ok && !dotnet.Installed {
+		res.Error = fmt.Errorf("dotnet is not installed")
+		res.Severity = SeveritySkip
+		return res
+	}
+
+	workloads, err := c.workloads.get(ctx, r)
+	if err != nil {
+		res.Error = err
+		res.Severity = SeverityFail
+		res.Remediation = "Install the .NET SDK: https://dotnet.microsoft.com/download"
+		return res
+	}
+	version, ok := workloads["aspire"]
+	if !ok {
+		res.Installed = true
+		res.Error = fmt.Errorf("AppHost of service %s needs the aspire workload or Aspire.AppHost.Sdk", c.service)
+		res.Severity = SeverityFail
+		res.Remediation = "Run `dotnet workload install aspire`, or reference Aspire.AppHost.Sdk in the AppHost: https://learn.microsoft.com/dotnet/aspire/get-started/upgrade-to-aspire-9"
+		res.Location = &Location{File: c.project.File, Line: c.project.AppHostLine}
+		return res
+	}
+	res.Installed = true
+	res.Version = strings.TrimSpace("aspire workload " + version)
+	res.Severity = SeverityPass
+	return res
+}
+
+// appHostServicesCheck reports the services an Aspire AppHost generates when azd deploys it.
+type appHostServicesCheck struct {
+	service string
+	project DotNetProject
+}
+
+func (c *appHostServicesCheck) ID() string         { return "aspire-services:" + c.service }
+func (c *appHostServicesCheck) Category() Category { return CategoryLanguage }
+func (c *appHostServicesCheck) Description() string {
+	return fmt.Sprintf("Service %s AppHost services", c.service)
+}
+
+func (c *appHostServicesCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isDotNetLanguage(config.Services[c.service].Language)
+}
+
+func (c *appHostServicesCheck) Run(context.Context, Runner) CheckResult {
+	res := CheckResult{Name: "AppHost Services", Installed: true, Severity: SeverityInfo}
+	res.Version = strings.Join(c.project.AppHostServices, ", ")
+	if res.Version == "" {
+		res.Version = "none found"
+	}
+	return res
+}
@@ -0,0 +1,458 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testListSDKs = `6.0.420 [/usr/share/dotnet/sdk]
+8.0.100 [/usr/share/dotnet/sdk]
+8.0.110 [/usr/share/dotnet/sdk]
+8.0.303 [/usr/share/dotnet/sdk]
+9.0.100-rc.2.24474.11 [/usr/share/dotnet/sdk]
+`
+
+func TestParseDotNetSDKs(t *testing.T) {
+	sdks := ParseDotNetSDKs(testListSDKs)
+	require.Len(t, sdks, 5)
+	assert.Equal(t, "6.0.420", sdks[0].String())
+	assert.Equal(t, "9.0.100-rc.2.24474.11", sdks[4].String())
+	assert.Empty(t, ParseDotNetSDKs(""))
+}
+
+func TestSelectSDK(t *testing.T) {
+	installed := ParseDotNetSDKs(testListSDKs)
+	noPrerelease := false
+
+	tests := []struct {
+		name            string
+		version         string
+		rollForward     string
+		allowPrerelease *bool
+		expected        string
+	}{
+		{name: "No version selects the latest", expected: "9.0.100-rc.2.24474.11"},
+		{name: "Default rolls to the latest patch", version: "8.0.100", expected: "8.0.110"},
+		{name: "Latest patch stays in the feature band", version: "8.0.200", expected: ""},
+		{name: "Patch prefers the requested version", version: "8.0.100", rollForward: "patch", expected: "8.0.100"},
+		{name: "Patch rolls to the latest patch when missing", version: "8.0.101", rollForward: "patch", expected: "8.0.110"},
+		{name: "Disable requires the exact version", version: "8.0.101", rollForward: "disable", expected: ""},
+		{name: "Disable", version: "8.0.100", rollForward: "disable", expected: "8.0.100"},
+		{name: "Feature takes the lowest higher band", version: "8.0.200", rollForward: "feature", expected: "8.0.303"},
+		{name: "Latest feature", version: "8.0.100", rollForward: "latestFeature", expected: "8.0.303"},
+		{name: "Minor stays in the major", version: "6.0.100", rollForward: "minor", expected: "6.0.420"},
+		{name: "Major takes the lowest match", version: "7.0.100", rollForward: "major", expected: "8.0.110"},
+		{name: "Latest major", version: "7.0.100", rollForward: "latestMajor", expected: "9.0.100-rc.2.24474.11"},
+		{name: "Latest major without prereleases", version: "7.0.100", rollForward: "latestMajor", allowPrerelease: &noPrerelease, expected: "8.0.303"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &GlobalJSON{}
+			g.SDK.Version = tt.version
+			g.SDK.RollForward = tt.rollForward
+			g.SDK.AllowPrerelease = tt.allowPrerelease
+
+			sdk, ok := g.SelectSDK(installed)
+			if tt.expected == "" {
+				assert.False(t, ok, "selected %s", sdk)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, sdk.String())
+		})
+	}
+
+	sdk, ok := (*GlobalJSON)(nil).SelectSDK(installed)
+	require.True(t, ok)
+	assert.Equal(t, "9.0.100-rc.2.24474.11", sdk.String())
+	_, ok = (*GlobalJSON)(nil).SelectSDK(nil)
+	assert.False(t, ok)
+}
+
+func TestTargetFrameworkVersion(t *testing.T) {
+	tests := []struct {
+		tfm          string
+		major, minor uint64
+		ok           bool
+	}{
+		{tfm: "net8.0", major: 8, minor: 0, ok: true},
+		{tfm: "net9.0-windows", major: 9, minor: 0, ok: true},
+		{tfm: "netcoreapp3.1", major: 3, minor: 1, ok: true},
+		{tfm: "netstandard2.0"},
+		{tfm: "net48"},
+	}
+	for _, tt := range tests {
+		major, minor, ok := TargetFrameworkVersion(tt.tfm)
+		assert.Equal(t, tt.ok, ok, tt.tfm)
+		assert.Equal(t, tt.major, major, tt.tfm)
+		assert.Equal(t, tt.minor, minor, tt.tfm)
+	}
+}
+
+func TestLoadDotNetProject(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFrameworks>net6.0;net8.0</TargetFrameworks>
+  </PropertyGroup>
+</Project>`,
+		"lib/Lib.fsproj": `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		"bad/Bad.csproj": `<Project>`,
+	})
+
+	project, err := LoadDotNetProject(filepath.Join(dir, "api"))
+	require.NoError(t, err)
+	assert.Equal(t, DotNetProject{File: filepath.Join(dir, "api", "Api.csproj"), TargetFrameworks: []string{"net6.0", "net8.0"}, Line: 3}, project)
+
+	project, err = LoadDotNetProject(filepath.Join(dir, "lib", "Lib.fsproj"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"net8.0"}, project.TargetFrameworks)
+
+	project, err = LoadDotNetProject(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, project.File)
+
+	_, err = LoadDotNetProject(filepath.Join(dir, "bad"))
+	assert.ErrorContains(t, err, "failed to parse")
+}
+
+func TestDotNetSDKCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		globalJSON string
+		listSDKs   string
+		listErr    error
+		frameworks []string
+		status     Severity
+		version    string
+		errMsg     string
+		// location is the base name of the file the failure points at, and its line.
+		location string
+		line     int
+	}{
+		{
+			name:       "Latest SDK builds the target",
+			listSDKs:   testListSDKs,
+			frameworks: []string{"net8.0"},
+			status:     SeverityPass,
+			version:    "9.0.100-rc.2.24474.11",
+		},
+		{
+			name:       "No SDK for the target",
+			listSDKs:   "6.0.420 [/usr/share/dotnet/sdk]\n",
+			frameworks: []string{"net6.0", "net8.0"},
+			status:     SeverityFail,
+			errMsg:     "no installed SDK can build net8.0 (latest is 6.0.420)",
+			location:   "Api.csproj",
+			line:       3,
+		},
+		{
+			name:       "global.json pins an older SDK",
+			globalJSON: `{"sdk": {"version": "6.0.400", "rollForward": "latestFeature"}}`,
+			listSDKs:   testListSDKs,
+			frameworks: []string{"net8.0"},
+			status:     SeverityFail,
+			errMsg:     "SDK 6.0.420 selected by global.json can't build net8.0",
+			location:   "Api.csproj",
+			line:       3,
+		},
+		{
+			name:       "global.json rolls forward",
+			globalJSON: `{"sdk": {"version": "8.0.100"}}`,
+			listSDKs:   testListSDKs,
+			frameworks: []string{"net8.0"},
+			status:     SeverityPass,
+			version:    "8.0.110",
+		},
+		{
+			name:       "global.json version not installed",
+			globalJSON: "{\n  \"sdk\": {\n    \"version\": \"8.0.400\",\n    \"rollForward\": \"disable\"\n  }\n}",
+			listSDKs:   testListSDKs,
+			frameworks: []string{"net8.0"},
+			status:     SeverityFail,
+			errMsg:     "no installed SDK matches global.json version 8.0.400 (rollForward disable)",
+			location:   "global.json",
+			line:       3,
+		},
+		{
+			name:    "dotnet not installed",
+			listErr: fmt.Errorf("executable file not found"),
+			status:  SeverityFail,
+			errMsg:  "failed to list the .NET SDKs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "src", "api")
+			files := map[string]string{"src/api/Api.csproj": "<Project />"}
+			if tt.globalJSON != "" {
+				files["global.json"] = tt.globalJSON
+			}
+			writeFiles(t, root, files)
+
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					assert.Equal(t, []string{"--list-sdks"}, args)
+					return []byte(tt.listSDKs), tt.listErr
+				},
+			}
+			c := &dotnetSDKCheck{
+				service: "api",
+				dir:     dir,
+				project: DotNetProject{File: filepath.Join(dir, "Api.csproj"), TargetFrameworks: tt.frameworks, Line: 3},
+				sdks:    &installedSDKs{},
+			}
+			res := c.Run(context.Background(), mock)
+			assert.Equal(t, tt.status, res.Status())
+			if tt.version != "" {
+				assert.Equal(t, tt.version, res.Version)
+			}
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.ErrorContains(t, res.Error, tt.errMsg)
+				assert.NotEmpty(t, res.Remediation)
+			}
+			if tt.location != "" {
+				require.NotNil(t, res.Location)
+				assert.Equal(t, tt.location, filepath.Base(res.Location.File))
+				assert.Equal(t, tt.line, res.Location.Line)
+			}
+		})
+	}
+}
+
+func TestPlanDotNetService(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"azure.yaml":            "name: test-project\nservices:\n  api:\n    project: ./src/api\n    language: csharp\n    host: appservice\n  web:\n    project: ./src/web/Web.csproj\n    language: dotnet\n    host: appservice\n",
+		"src/api/Api.csproj":    `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		"src/web/Web.csproj":    `<Project Sdk="Microsoft.NET.Sdk.Web"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		"src/worker/Program.cs": "",
+	})
+	config, err := LoadProjectConfig(filepath.Join(dir, "azure.yaml"))
+	require.NoError(t, err)
+
+	plan := NewPlannerWithOS(NewRegistry(), "linux").Plan(config, PlanOptions{Command: "package"})
+	assert.Equal(t, []string{"dotnet-sdk:api (api)", "dotnet-sdk:web (web)"}, requirementStrings(plan))
+
+	// The services share a single `dotnet --list-sdks` call.
+	calls := 0
+	mock := &MockRunner{OutputFunc: func(name string, args ...string) ([]byte, error) {
+		calls++
+		return []byte(testListSDKs), nil
+	}}
+	for _, id := range []string{"dotnet-sdk:api", "dotnet-sdk:web"} {
+		req, ok := plan.Find(id)
+		require.True(t, ok, id)
+		assert.Equal(t, SeverityPass, req.Check.Run(context.Background(), mock).Status(), id)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestLoadDotNetAppHost(t *testing.T) {
+	program := `var builder = DistributedApplication.CreateBuilder(args);
+
+var cache = builder.AddRedis("cache");
+var api = builder.AddProject<Projects.Api>("apiservice");
+builder.AddNpmApp("web", "../web")
+    .WithReference(api);
+builder.AddDockerfile("worker", "../worker");
+builder.AddProject<Projects.Api>("apiservice");
+
+builder.Build().Run();
+`
+	tests := []struct {
+		name     string
+		csproj   string
+		expected DotNetProject
+	}{
+		{
+			name: "Aspire.AppHost.Sdk element",
+			csproj: `<Project Sdk="Microsoft.NET.Sdk">
+  <Sdk Name="Aspire.AppHost.Sdk" Version="9.0.0" />
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <IsAspireHost>true</IsAspireHost>
+  </PropertyGroup>
+</Project>`,
+			expected: DotNetProject{AppHost: true, AppHostLine: 2, AspireSDK: true, AspireVersion: "9.0.0"},
+		},
+		{
+			name:     "Aspire.AppHost.Sdk project SDK",
+			csproj:   `<Project Sdk="Aspire.AppHost.Sdk/13.0.0"><PropertyGroup><TargetFramework>net9.0</TargetFramework></PropertyGroup></Project>`,
+			expected: DotNetProject{AppHost: true, AppHostLine: 1, AspireSDK: true, AspireVersion: "13.0.0"},
+		},
+		{
+			name: "Aspire 8 workload",
+			csproj: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <IsAspireHost>true</IsAspireHost>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Aspire.Hosting.AppHost" Version="8.2.2" />
+  </ItemGroup>
+</Project>`,
+			expected: DotNetProject{AppHost: true, AppHostLine: 4, AspireVersion: "8.2.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"AppHost.csproj": tt.csproj, "Program.cs": program, "obj/Generated.cs": `builder.AddProject("ignored")`})
+
+			project, err := LoadDotNetProject(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.AppHost, project.AppHost)
+			assert.Equal(t, tt.expected.AppHostLine, project.AppHostLine)
+			assert.Equal(t, tt.expected.AspireSDK, project.AspireSDK)
+			assert.Equal(t, tt.expected.AspireVersion, project.AspireVersion)
+			assert.Equal(t, []string{"apiservice", "web", "worker"}, project.AppHostServices)
+		})
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web" />`, "Program.cs": program})
+	project, err := LoadDotNetProject(dir)
+	require.NoError(t, err)
+	assert.False(t, project.AppHost)
+	assert.Empty(t, project.AppHostServices)
+}
+
+func TestParseDotNetWorkloads(t *testing.T) {
+	output := `
+Installed Workload Id      Manifest Version       Installation Source
+--------------------------------------------------------------------
+aspire                     8.2.2/8.0.100          SDK 8.0.400
+wasm-tools                 8.0.8/8.0.100          SDK 8.0.400
+
+Use ` + "`dotnet workload search`" + ` to find additional workloads to install.
+`
+	assert.Equal(t, map[string]string{"aspire": "8.2.2", "wasm-tools": "8.0.8"}, ParseDotNetWorkloads(output))
+
+	empty := `
+Installed Workload Id      Manifest Version      Installation Source
+---------------------------------------------------------------------
+
+Use ` + "`dotnet workload search`" + ` to find additional workloads to install.
+`
+	assert.Empty(t, ParseDotNetWorkloads(empty))
+}
+
+func TestAspireCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		project   DotNetProject
+		workloads string
+		dotnet    CheckResult
+		status    Severity
+		version   string
+		errMsg    string
+	}{
+		{
+			name:    "Aspire.AppHost.Sdk",
+			project: DotNetProject{AppHost: true, AspireSDK: true, AspireVersion: "9.0.0"},
+			status:  SeverityPass,
+			version: "Aspire.AppHost.Sdk 9.0.0",
+		},
+		{
+			name:      "Workload installed",
+			project:   DotNetProject{AppHost: true, AspireVersion: "8.2.2"},
+			workloads: "Installed Workload Id  Manifest Version  Installation Source\n---\naspire  8.2.2/8.0.100  SDK 8.0.400\n",
+			dotnet:    CheckResult{Installed: true},
+			status:    SeverityPass,
+			version:   "aspire workload 8.2.2",
+		},
+		{
+			name:      "Workload missing",
+			project:   DotNetProject{File: "AppHost.csproj", AppHost: true, AppHostLine: 4},
+			workloads: "Installed Workload Id  Manifest Version  Installation Source\n---\n",
+			dotnet:    CheckResult{Installed: true},
+			status:    SeverityFail,
+			errMsg:    "AppHost of service app needs the aspire workload or Aspire.AppHost.Sdk",
+		},
+		{
+			name:    "dotnet not installed",
+			project: DotNetProject{AppHost: true},
+			status:  SeveritySkip,
+			errMsg:  "dotnet is not installed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					assert.Equal(t, []string{"dotnet", "workload", "list"}, append([]string{name}, args...))
+					return []byte(tt.workloads), nil
+				},
+			}
+			ctx := context.WithValue(context.Background(), dependencyResultsKey{}, map[string]CheckResult{"dotnet": tt.dotnet})
+			res := (&aspireCheck{service: "app", project: tt.project, workloads: &installedWorkloads{}}).Run(ctx, mock)
+			assert.Equal(t, tt.status, res.Status())
+			if tt.version != "" {
+				assert.Equal(t, tt.version, res.Version)
+			}
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.ErrorContains(t, res.Error, tt.errMsg)
+			}
+			if tt.status == SeverityFail {
+				assert.Equal(t, &Location{File: "AppHost.csproj", Line: 4}, res.Location)
+				assert.Contains(t, res.Remediation, "dotnet workload install aspire")
+			}
+		})
+	}
+}
+
+func TestPlanAspireAppHost(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"azure.yaml": "name: test-project\nservices:\n  app:\n    project: ./AppHost/AppHost.csproj\n    language: dotnet\n    host: appservice\n",
+		"AppHost/AppHost.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <Sdk Name="Aspire.AppHost.Sdk" Version="9.0.0" />
+  <PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup>
+</Project>`,
+		"AppHost/Program.cs": `var builder = DistributedApplication.CreateBuilder(args);
+builder.AddProject<Projects.Api>("apiservice");
+builder.AddProject<Projects.Web>("webfrontend");
+builder.Build().Run();`,
+	})
+	config, err := LoadProjectConfig(filepath.Join(dir, "azure.yaml"))
+	require.NoError(t, err)
+
+	// The AppHost needs a container runtime although its host doesn't, in `check` (no
+	// command) and when verifying deploy.
+	planner := NewPlannerWithOS(DefaultRegistryWithOS("linux"), "linux")
+	for _, command := range []string{"", "deploy"} {
+		plan := planner.Plan(config, PlanOptions{Command: command})
+		for _, id := range []string{"docker", "docker-daemon", "dotnet", "dotnet-sdk:app", "aspire:app", "aspire-services:app"} {
+			req, ok := plan.Find(id)
+			if assert.True(t, ok, "%s planned for %q", id, command) {
+				assert.Equal(t, []string{"app"}, req.Services, id)
+			}
+		}
+	}
+
+	// Provisioning doesn't build the AppHost.
+	_, ok := planner.Plan(config, PlanOptions{Command: "provision"}).Find("docker")
+	assert.False(t, ok)
+
+	req, ok := planner.Plan(config, PlanOptions{}).Find("aspire-services:app")
+	require.True(t, ok)
+	res := req.Check.Run(context.Background(), &MockRunner{})
+	assert.Equal(t, SeverityInfo, res.Status())
+	assert.Equal(t, "apiservice, webfrontend", res.Version)
+}
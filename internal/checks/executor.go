@@ -0,0 +1,105 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the default number of checks executed at the same time.
+const DefaultConcurrency = 4
+
+// Executor runs checks concurrently with a bounded number of workers.
+// Results are always returned in the order the checks were given, so output is deterministic.
+type Executor struct {
+	Runner      Runner
+	Concurrency int
+}
+
+func NewExecutor(r Runner, concurrency int) *Executor {
+	return &Executor{Runner: r, Concurrency: concurrency}
+}
+
+type dependencyResultsKey struct{}
+
+// DependencyResult returns the result of a dependency declared through DependentCheck.
+// It is only available from within Run of the dependent check.
+func DependencyResult(ctx context.Context, id string) (CheckResult, bool) {
+	deps, _ := ctx.Value(dependencyResultsKey{}).(map[string]CheckResult)
+	res, ok := deps[id]
+	return res, ok
+}
+
+// Run executes the checks and returns their results in input order, with ID and Duration set.
+// A check declaring dependencies waits for those that appear earlier in the list and is
+// skipped if any of them did not pass; dependencies that are absent or appear later are
+// ignored, which also rules out cycles.
+func (e *Executor) Run(ctx context.Context, list []Check) []CheckResult {
+	concurrency := e.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]CheckResult, len(list))
+	done := make([]chan struct{}, len(list))
+	index := make(map[string]int, len(list))
+	for i, c := range list {
+		done[i] = make(chan struct{})
+		index[c.ID()] = i
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, c := range list {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			defer close(done[i])
+
+			// Wait for dependencies before taking a worker slot so that waiting
+			// checks never starve the checks they depend on.
+			var deps map[string]CheckResult
+			if dc, ok := c.(DependentCheck); ok {
+				deps = make(map[string]CheckResult)
+				for _, id := range dc.DependsOn() {
+					j, ok := index[id]
+					if !ok || j >= i {
+						continue
+					}
+					<-done[j]
+					deps[id] = results[j]
+				}
+				for _, id := range dc.DependsOn() {
+					if dep, ok := deps[id]; ok && (dep.Failed() || dep.Status() == SeveritySkip) {
+						results[i] = CheckResult{ID: c.ID(), Name: c.Description(), Severity: SeveritySkip,
+							Error: fmt.Errorf("skipped because %s did not pass", id)}
+						return
+					}
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = CheckResult{ID: c.ID(), Name: c.Description(), Error: ctx.Err(), Severity: SeverityFail}
+				return
+			}
+			defer func() { <-sem }()
+
+			runCtx := ctx
+			if deps != nil {
+				runCtx = context.WithValue(ctx, dependencyResultsKey{}, deps)
+			}
+			start := time.Now()
+			res := c.Run(runCtx, e.Runner)
+			res.ID = c.ID()
+			res.Duration = time.Since(start)
+			results[i] = res
+		}(i, c)
+	}
+
+	wg.Wait()
+	return results
+}
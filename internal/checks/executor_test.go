@@ -0,0 +1,139 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorRun(t *testing.T) {
+	t.Run("Results keep input order", func(t *testing.T) {
+		var list []Check
+		for i := 0; i < 10; i++ {
+			id := fmt.Sprintf("check-%d", i)
+			delay := time.Duration(10-i) * time.Millisecond
+			list = append(list, &toolCheck{id: id, run: func(context.Context, Runner) CheckResult {
+				time.Sleep(delay)
+				return CheckResult{Name: id, Installed: true}
+			}})
+		}
+
+		results := NewExecutor(&MockRunner{}, 4).Run(context.Background(), list)
+		for i, res := range results {
+			assert.Equal(t, fmt.Sprintf("check-%d", i), res.Name)
+		}
+	})
+
+	t.Run("Concurrency is bounded", func(t *testing.T) {
+		var running, peak int32
+		var list []Check
+		for i := 0; i < 8; i++ {
+			list = append(list, &toolCheck{id: fmt.Sprintf("check-%d", i), run: func(context.Context, Runner) CheckResult {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return CheckResult{Name: "ok"}
+			}})
+		}
+
+		NewExecutor(&MockRunner{}, 2).Run(context.Background(), list)
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+		assert.Greater(t, atomic.LoadInt32(&peak), int32(0))
+	})
+
+	t.Run("Dependencies run first and expose results", func(t *testing.T) {
+		var binaryDone int32
+		list := []Check{
+			&toolCheck{id: "bin", run: func(context.Context, Runner) CheckResult {
+				time.Sleep(10 * time.Millisecond)
+				atomic.StoreInt32(&binaryDone, 1)
+				return CheckResult{Name: "podman", Installed: true}
+			}},
+			&toolCheck{id: "daemon", dependsOn: []string{"bin", "missing"}, run: func(ctx context.Context, r Runner) CheckResult {
+				assert.Equal(t, int32(1), atomic.LoadInt32(&binaryDone))
+				bin, ok := DependencyResult(ctx, "bin")
+				assert.True(t, ok)
+				_, ok = DependencyResult(ctx, "missing")
+				assert.False(t, ok)
+				return CheckResult{Name: bin.Name + " Daemon", Installed: true, Running: true}
+			}},
+		}
+
+		results := NewExecutor(&MockRunner{}, 4).Run(context.Background(), list)
+		assert.Equal(t, "podman Daemon", results[1].Name)
+	})
+
+	t.Run("Runner is passed to checks", func(t *testing.T) {
+		mock := &MockRunner{
+			OutputFunc: func(name string, args ...string) ([]byte, error) {
+				return []byte("v20.0.0\n"), nil
+			},
+		}
+		list := []Check{&toolCheck{id: "node", run: func(_ context.Context, r Runner) CheckResult {
+			return CheckNode(r)
+		}}}
+
+		results := NewExecutor(mock, 0).Run(context.Background(), list)
+		assert.Equal(t, "20.0.0", results[0].Version)
+		assert.Equal(t, "node", results[0].ID, "executor sets the check ID")
+	})
+
+	t.Run("Duration is recorded", func(t *testing.T) {
+		list := []Check{&toolCheck{id: "slow", run: func(context.Context, Runner) CheckResult {
+			time.Sleep(5 * time.Millisecond)
+			return CheckResult{Name: "slow", Installed: true}
+		}}}
+
+		results := NewExecutor(&MockRunner{}, 1).Run(context.Background(), list)
+		assert.GreaterOrEqual(t, results[0].Duration, 5*time.Millisecond)
+	})
+
+	t.Run("Canceled context still returns a result per check", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		blocker := make(chan struct{})
+		list := []Check{
+			&toolCheck{id: "a", description: "A", run: func(context.Context, Runner) CheckResult {
+				<-blocker
+				return CheckResult{Name: "a"}
+			}},
+			&toolCheck{id: "b", description: "B", run: func(context.Context, Runner) CheckResult {
+				<-blocker
+				return CheckResult{Name: "b"}
+			}},
+		}
+		close(blocker)
+
+		results := NewExecutor(&MockRunner{}, 1).Run(ctx, list)
+		assert.Len(t, results, 2)
+	})
+}
+
+func TestContainerDaemonDependency(t *testing.T) {
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return nil, fmt.Errorf("not found")
+		},
+	}
+
+	r := DefaultRegistryWithOS("linux")
+	bin, _ := r.Get("docker")
+	daemon, _ := r.Get("docker-daemon")
+
+	results := NewExecutor(mock, 2).Run(context.Background(), []Check{bin, daemon})
+	assert.False(t, results[0].Installed)
+	assert.Equal(t, SeverityFail, results[0].Status())
+	assert.Equal(t, SeveritySkip, results[1].Status(), "daemon check is skipped without a binary")
+	assert.Equal(t, "docker-daemon", results[1].ID, "skipped results keep their check ID")
+}
@@ -0,0 +1,215 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// placeholderPattern matches the `${VAR}` placeholders azd substitutes in parameter files,
+// including the `${VAR=default}`, `${VAR:=default}`, `${VAR-default}` and `${VAR:-default}` forms.
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:?[-=][^}]*)?\}`)
+
+// readEnvironmentVariablePattern matches `readEnvironmentVariable('VAR'[, default])` in .bicepparam files.
+var readEnvironmentVariablePattern = regexp.MustCompile(`readEnvironmentVariable\(\s*'([^']+)'\s*(,[^)]*)?\)`)
+
+// ParameterReference is an environment variable referenced by an infra parameters file.
+type ParameterReference struct {
+	Name string
+	// HasDefault is set for references with a default value, which don't need the variable.
+	HasDefault bool
+	Line       int
+}
+
+// ParameterReferences returns the environment variables referenced by a parameters file:
+// `${VAR}` placeholders in main.parameters.json, or readEnvironmentVariable calls in a
+// .bicepparam file.
+func ParameterReferences(file string, content []byte) []ParameterReference {
+	pattern := placeholderPattern
+	if strings.HasSuffix(file, ".bicepparam") {
+		pattern = readEnvironmentVariablePattern
+	}
+
+	var refs []ParameterReference
+	for _, m := range pattern.FindAllSubmatchIndex(content, -1) {
+		refs = append(refs, ParameterReference{
+			Name:       string(content[m[2]:m[3]]),
+			HasDefault: m[4] >= 0,
+			Line:       1 + strings.Count(string(content[:m[0]]), "\n"),
+		})
+	}
+	return refs
+}
+
+// infraParameterFiles returns the parameters files of a bicep project that exist.
+func infraParameterFiles(config *AzureYaml) []string {
+	if config == nil || config.file == "" || (config.Infra.Provider != "" && config.Infra.Provider != "bicep") {
+		return nil
+	}
+	dir := config.Infra.Path
+	if dir == "" {
+		dir = "infra"
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(config.file), dir)
+	}
+	module := config.Infra.Module
+	if module == "" {
+		module = "main"
+	}
+
+	var files []string
+	for _, name := range []string{module + ".parameters.json", module + ".bicepparam"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// infraParametersCheck verifies that the environment variables required by an infra
+// parameters file are set, since azd silently substitutes missing ones with an empty string.
+type infraParametersCheck struct {
+	file string
+	// environment is the azd environment to read the values of; the default environment if empty.
+	environment string
+	// lookupEnv finds variables azd takes from the process environment; os.LookupEnv if nil.
+	lookupEnv func(string) (string, bool)
+}
+
+func (c *infraParametersCheck) ID() string         { return "infra-parameters:" + filepath.Base(c.file) }
+func (c *infraParametersCheck) Category() Category { return CategoryInfra }
+func (c *infraParametersCheck) Description() string {
+	return "Infra parameters " + filepath.Base(c.file)
+}
+
+func (c *infraParametersCheck) Applies(config *AzureYaml) bool {
+	for _, file := range infraParameterFiles(config) {
+		if file == c.file {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *infraParametersCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := CheckResult{Name: "Infra Parameters", Version: filepath.Base(c.file)}
+
+	content, err := os.ReadFile(c.file)
+	if err != nil {
+		res.Error = fmt.Errorf("failed to read %s: %w", c.file, err)
+		res.Severity = SeverityFail
+		return res
+	}
+	if strings.HasSuffix(c.file, ".json") && !json.Valid(content) {
+		res.Error = fmt.Errorf("%s is not valid JSON", c.file)
+		res.Severity = SeverityFail
+		res.Remediation = "Fix the syntax of the parameters file"
+		res.Location = &Location{File: c.file, Line: 1, Column: 1}
+		return res
+	}
+
+	refs := ParameterReferences(c.file, content)
+	if len(refs) == 0 {
+		res.Installed = true
+		res.Severity = SeverityPass
+		return res
+	}
+
+	values, err := environmentValues(ctx, r, c.environment)
+	if err != nil {
+		res.Error = fmt.Errorf("failed to read the environment values: %w", err)
+		res.Severity = SeverityWarn
+		res.Remediation = "Select an environment with `azd env select` or create one with `azd env new`"
+		return res
+	}
+
+	lookupEnv := c.lookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	missing := make(map[string]bool)
+	provided := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.HasDefault || missing[ref.Name] || provided[ref.Name] {
+			continue
+		}
+		if _, ok := values[ref.Name]; ok {
+			continue
+		}
+		if _, ok := lookupEnv(ref.Name); ok {
+			continue
+		}
+		if azdProvidedVariables[ref.Name] {
+			provided[ref.Name] = true
+			continue
+		}
+		missing[ref.Name] = true
+		if res.Location == nil {
+			res.Location = &Location{File: c.file, Line: ref.Line}
+		}
+	}
+
+	res.Installed = true
+	if len(missing) == 0 {
+		res.Severity = SeverityPass
+		if len(provided) > 0 {
+			res.Severity = SeverityInfo
+			res.Version = "azd sets or prompts for " + strings.Join(sortedNames(provided), ", ")
+		}
+		return res
+	}
+	names := sortedNames(missing)
+	res.Error = fmt.Errorf("%s references variables missing from the environment: %s",
+		filepath.Base(c.file), strings.Join(names, ", "))
+	res.Severity = SeverityFail
+	res.Remediation = "Set them with `azd env set <name> <value>`"
+	if len(names) == 1 {
+		res.Remediation = fmt.Sprintf("Set it with `azd env set %s <value>`", names[0])
+	}
+	return res
+}
+
+// azdProvidedVariables are the environment variables azd sets itself at provision time,
+// or prompts for when they are missing, so a new environment doesn't need them yet.
+var azdProvidedVariables = map[string]bool{
+	"AZURE_ENV_NAME":        true,
+	"AZURE_LOCATION":        true,
+	"AZURE_SUBSCRIPTION_ID": true,
+	"AZURE_RESOURCE_GROUP":  true,
+	"AZURE_PRINCIPAL_ID":    true,
+	"AZURE_PRINCIPAL_TYPE":  true,
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// environmentValues returns the values of the given azd environment, or of the
+// default environment if name is empty.
+func environmentValues(ctx context.Context, r Runner, name string) (map[string]string, error) {
+	args := []string{"env", "get-values", "--output", "json"}
+	if name != "" {
+		args = append(args, "--environment", name)
+	}
+	out, err := runnerOutput(ctx, r, "azd", args...)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(out, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse azd env get-values: %w", err)
+	}
+	return values, nil
+}
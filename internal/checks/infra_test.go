@@ -0,0 +1,221 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testParameters = `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+  "contentVersion": "1.0.0.0",
+  "parameters": {
+    "environmentName": { "value": "${AZURE_ENV_NAME}" },
+    "location": { "value": "${AZURE_LOCATION}" },
+    "principalId": { "value": "${AZURE_PRINCIPAL_ID}" },
+    "skuName": { "value": "${SKU_NAME=B1}" },
+    "apiImage": { "value": "${SERVICE_API_IMAGE:-nginx}" },
+    "dbPassword": { "value": "${DB_PASSWORD}" }
+  }
+}
+`
+
+func TestParameterReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []ParameterReference
+	}{
+		{
+			name:    "Parameters JSON",
+			file:    "main.parameters.json",
+			content: testParameters,
+			expected: []ParameterReference{
+				{Name: "AZURE_ENV_NAME", Line: 5},
+				{Name: "AZURE_LOCATION", Line: 6},
+				{Name: "AZURE_PRINCIPAL_ID", Line: 7},
+				{Name: "SKU_NAME", HasDefault: true, Line: 8},
+				{Name: "SERVICE_API_IMAGE", HasDefault: true, Line: 9},
+				{Name: "DB_PASSWORD", Line: 10},
+			},
+		},
+		{
+			name: "Bicepparam",
+			file: "main.bicepparam",
+			content: `using './main.bicep'
+
+param environmentName = readEnvironmentVariable('AZURE_ENV_NAME')
+param sku = readEnvironmentVariable( 'SKU_NAME', 'B1' )
+`,
+			expected: []ParameterReference{
+				{Name: "AZURE_ENV_NAME", Line: 3},
+				{Name: "SKU_NAME", HasDefault: true, Line: 4},
+			},
+		},
+		{
+			name:     "No references",
+			file:     "main.parameters.json",
+			content:  `{"parameters": {"location": {"value": "eastus"}}}`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParameterReferences(tt.file, []byte(tt.content)))
+		})
+	}
+}
+
+func writeInfraProject(t *testing.T, parameters string) (*AzureYaml, string) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "azure.yaml"), []byte("name: test-project\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "infra"), 0755))
+	file := filepath.Join(dir, "infra", "main.parameters.json")
+	require.NoError(t, os.WriteFile(file, []byte(parameters), 0644))
+	config, err := LoadProjectConfig(filepath.Join(dir, "azure.yaml"))
+	require.NoError(t, err)
+	return config, file
+}
+
+func TestInfraParametersCheck(t *testing.T) {
+	_, file := writeInfraProject(t, testParameters)
+	noEnv := func(string) (string, bool) { return "", false }
+
+	tests := []struct {
+		name        string
+		values      string
+		valuesErr   error
+		lookupEnv   func(string) (string, bool)
+		status      Severity
+		errMsg      string
+		remediation string
+		version     string
+		line        int
+	}{
+		{
+			name:      "All variables set",
+			values:    `{"AZURE_ENV_NAME":"dev","AZURE_LOCATION":"eastus","AZURE_PRINCIPAL_ID":"id","DB_PASSWORD":"pw"}`,
+			lookupEnv: noEnv,
+			status:    SeverityPass,
+		},
+		{
+			name:        "Missing variables",
+			values:      `{"AZURE_ENV_NAME":"dev","AZURE_LOCATION":"eastus"}`,
+			lookupEnv:   noEnv,
+			status:      SeverityFail,
+			errMsg:      "main.parameters.json references variables missing from the environment: DB_PASSWORD",
+			remediation: "Set it with `azd env set DB_PASSWORD <value>`",
+			line:        10,
+		},
+		{
+			name:   "Process environment",
+			values: `{"AZURE_ENV_NAME":"dev","AZURE_LOCATION":"eastus","AZURE_PRINCIPAL_ID":"id"}`,
+			lookupEnv: func(name string) (string, bool) {
+				return "pw", name == "DB_PASSWORD"
+			},
+			status: SeverityPass,
+		},
+		{
+			name:      "Variables azd sets or prompts for",
+			values:    `{"AZURE_ENV_NAME":"dev","DB_PASSWORD":""}`,
+			lookupEnv: noEnv,
+			status:    SeverityInfo,
+			version:   "azd sets or prompts for AZURE_LOCATION, AZURE_PRINCIPAL_ID",
+		},
+		{
+			name:        "No environment",
+			valuesErr:   fmt.Errorf("no default environment"),
+			lookupEnv:   noEnv,
+			status:      SeverityWarn,
+			errMsg:      "failed to read the environment values",
+			remediation: "Select an environment with `azd env select` or create one with `azd env new`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					assert.Equal(t, "azd", name)
+					assert.Equal(t, []string{"env", "get-values", "--output", "json"}, args)
+					return []byte(tt.values), tt.valuesErr
+				},
+			}
+			c := &infraParametersCheck{file: file, lookupEnv: tt.lookupEnv}
+			res := c.Run(context.Background(), mock)
+
+			assert.Equal(t, tt.status, res.Status())
+			assert.Equal(t, tt.remediation, res.Remediation)
+			if tt.version != "" {
+				assert.Equal(t, tt.version, res.Version)
+			}
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.ErrorContains(t, res.Error, tt.errMsg)
+			}
+			if tt.line > 0 {
+				require.NotNil(t, res.Location)
+				assert.Equal(t, file, res.Location.File)
+				assert.Equal(t, tt.line, res.Location.Line)
+			}
+		})
+	}
+}
+
+func TestInfraParametersCheck_Environment(t *testing.T) {
+	_, file := writeInfraProject(t, testParameters)
+
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			assert.Equal(t, []string{"env", "get-values", "--output", "json", "--environment", "staging"}, args)
+			return []byte(`{"DB_PASSWORD":"pw"}`), nil
+		},
+	}
+	c := &infraParametersCheck{file: file, environment: "staging", lookupEnv: func(string) (string, bool) { return "", false }}
+	res := c.Run(context.Background(), mock)
+	assert.Equal(t, SeverityInfo, res.Status())
+	assert.NoError(t, res.Error)
+}
+
+func TestInfraParametersCheck_InvalidJSON(t *testing.T) {
+	_, file := writeInfraProject(t, `{"parameters": {`)
+
+	res := (&infraParametersCheck{file: file}).Run(context.Background(), &MockRunner{})
+	assert.Equal(t, SeverityFail, res.Status())
+	assert.ErrorContains(t, res.Error, "is not valid JSON")
+}
+
+func TestPlanInfraParameters(t *testing.T) {
+	config, _ := writeInfraProject(t, testParameters)
+	planner := NewPlannerWithOS(NewRegistry(), "linux")
+
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{command: "", expected: []string{"infra-parameters:main.parameters.json"}},
+		{command: "up", expected: []string{"infra-parameters:main.parameters.json"}},
+		{command: "provision", expected: []string{"infra-parameters:main.parameters.json"}},
+		{command: "deploy", expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			assert.Equal(t, tt.expected, requirementStrings(planner.Plan(config, PlanOptions{Command: tt.command})))
+		})
+	}
+
+	req, ok := planner.Plan(config, PlanOptions{Command: "provision", Environment: "staging"}).Find("infra-parameters:main.parameters.json")
+	require.True(t, ok)
+	assert.Equal(t, "staging", req.Check.(*infraParametersCheck).environment)
+
+	config.Infra.Provider = "terraform"
+	assert.Empty(t, requirementStrings(planner.Plan(config, PlanOptions{Command: "provision"})), "terraform doesn't use main.parameters.json")
+}
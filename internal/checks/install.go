@@ -0,0 +1,199 @@
+package checks
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// PackageManager is a system package manager used to install missing tools.
+type PackageManager string
+
+const (
+	PackageManagerApt    PackageManager = "apt"
+	PackageManagerDnf    PackageManager = "dnf"
+	PackageManagerSnap   PackageManager = "snap"
+	PackageManagerBrew   PackageManager = "brew"
+	PackageManagerWinget PackageManager = "winget"
+	PackageManagerChoco  PackageManager = "choco"
+)
+
+// packageManagersByOS lists the package managers looked for on each OS, in order of preference.
+var packageManagersByOS = map[string][]PackageManager{
+	"linux":   {PackageManagerApt, PackageManagerDnf, PackageManagerSnap, PackageManagerBrew},
+	"darwin":  {PackageManagerBrew},
+	"windows": {PackageManagerWinget, PackageManagerChoco},
+}
+
+// packageManagerBinaries maps package managers to their executable when it differs.
+var packageManagerBinaries = map[PackageManager]string{
+	PackageManagerApt: "apt-get",
+}
+
+// installCatalog maps check IDs to the command installing the tool with each package manager.
+// Managers are left out when the tool isn't in their default repositories.
+var installCatalog = map[string]map[PackageManager]string{
+	"azd": {
+		PackageManagerBrew:   "brew tap azure/azd && brew install azd",
+		PackageManagerWinget: "winget install microsoft.azd",
+		PackageManagerChoco:  "choco install azd",
+	},
+	"git": {
+		PackageManagerApt:    "sudo apt-get install -y git",
+		PackageManagerDnf:    "sudo dnf install -y git",
+		PackageManagerBrew:   "brew install git",
+		PackageManagerWinget: "winget install --id Git.Git -e",
+		PackageManagerChoco:  "choco install git",
+	},
+	"gh": {
+		PackageManagerApt:    "sudo apt-get install -y gh",
+		PackageManagerDnf:    "sudo dnf install -y gh",
+		PackageManagerBrew:   "brew install gh",
+		PackageManagerWinget: "winget install --id GitHub.cli -e",
+		PackageManagerChoco:  "choco install gh",
+	},
+	"docker": {
+		PackageManagerApt:    "sudo apt-get install -y docker.io",
+		PackageManagerDnf:    "sudo dnf install -y podman",
+		PackageManagerSnap:   "sudo snap install docker",
+		PackageManagerBrew:   "brew install --cask docker",
+		PackageManagerWinget: "winget install --id Docker.DockerDesktop -e",
+		PackageManagerChoco:  "choco install docker-desktop",
+	},
+	"node": {
+		// The Debian and Ubuntu nodejs package is older than the supported range.
+		PackageManagerApt:    "curl -fsSL https://deb.nodesource.com/setup_lts.x | sudo -E bash - && sudo apt-get install -y nodejs",
+		PackageManagerDnf:    "sudo dnf install -y nodejs",
+		PackageManagerSnap:   "sudo snap install node --classic",
+		PackageManagerBrew:   "brew install node",
+		PackageManagerWinget: "winget install --id OpenJS.NodeJS.LTS -e",
+		PackageManagerChoco:  "choco install nodejs-lts",
+	},
+	"python": {
+		PackageManagerApt:    "sudo apt-get install -y python3",
+		PackageManagerDnf:    "sudo dnf install -y python3",
+		PackageManagerBrew:   "brew install python",
+		PackageManagerWinget: "winget install --id Python.Python.3.12 -e",
+		PackageManagerChoco:  "choco install python",
+	},
+	"dotnet": {
+		PackageManagerApt:    "sudo apt-get install -y dotnet-sdk-8.0",
+		PackageManagerDnf:    "sudo dnf install -y dotnet-sdk-8.0",
+		PackageManagerSnap:   "sudo snap install dotnet-sdk --classic",
+		PackageManagerBrew:   "brew install --cask dotnet-sdk",
+		PackageManagerWinget: "winget install --id Microsoft.DotNet.SDK.8 -e",
+		PackageManagerChoco:  "choco install dotnet-sdk",
+	},
+	"java": {
+		PackageManagerApt:    "sudo apt-get install -y openjdk-21-jdk",
+		PackageManagerDnf:    "sudo dnf install -y java-21-openjdk-devel",
+		PackageManagerBrew:   "brew install --cask microsoft-openjdk@21",
+		PackageManagerWinget: "winget install --id Microsoft.OpenJDK.21 -e",
+		PackageManagerChoco:  "choco install microsoft-openjdk",
+	},
+	"bash": {
+		PackageManagerApt:  "sudo apt-get install -y bash",
+		PackageManagerDnf:  "sudo dnf install -y bash",
+		PackageManagerBrew: "brew install bash",
+		// Git for Windows ships Git Bash.
+		PackageManagerWinget: "winget install --id Git.Git -e",
+		PackageManagerChoco:  "choco install git",
+	},
+	"pwsh": {
+		PackageManagerSnap:   "sudo snap install powershell --classic",
+		PackageManagerBrew:   "brew install --cask powershell",
+		PackageManagerWinget: "winget install --id Microsoft.PowerShell -e",
+		PackageManagerChoco:  "choco install powershell-core",
+	},
+	"func": {
+		PackageManagerBrew:   "brew tap azure/functions && brew install azure-functions-core-tools@4",
+		PackageManagerWinget: "winget install --id Microsoft.Azure.FunctionsCoreTools -e",
+		PackageManagerChoco:  "choco install azure-functions-core-tools",
+	},
+	"terraform": {
+		PackageManagerSnap:   "sudo snap install terraform --classic",
+		PackageManagerBrew:   "brew tap hashicorp/tap && brew install hashicorp/tap/terraform",
+		PackageManagerWinget: "winget install --id Hashicorp.Terraform -e",
+		PackageManagerChoco:  "choco install terraform",
+	},
+}
+
+// installFallbacks are install commands that don't need a package manager, keyed by
+// check ID and OS ("" applies to every OS).
+var installFallbacks = map[string]map[string]string{
+	"azd": {
+		"linux":   "curl -fsSL https://aka.ms/install-azd.sh | bash",
+		"darwin":  "curl -fsSL https://aka.ms/install-azd.sh | bash",
+		"windows": `powershell -ex AllSigned -c "Invoke-RestMethod 'https://aka.ms/install-azd.ps1' | Invoke-Expression"`,
+	},
+	"func": {"": "npm install -g azure-functions-core-tools@4"},
+	"swa":  {"": "npm install -g @azure/static-web-apps-cli"},
+}
+
+// DetectPackageManagers returns the package managers available on the current OS.
+func DetectPackageManagers(r Runner) []PackageManager {
+	return DetectPackageManagersWithOS(r, runtime.GOOS)
+}
+
+// DetectPackageManagersWithOS returns the package managers of goos that are installed,
+// in order of preference.
+func DetectPackageManagersWithOS(r Runner, goos string) []PackageManager {
+	var found []PackageManager
+	for _, pm := range packageManagersByOS[goos] {
+		bin := string(pm)
+		if b, ok := packageManagerBinaries[pm]; ok {
+			bin = b
+		}
+		if _, err := r.Output(bin, "--version"); err == nil {
+			found = append(found, pm)
+		}
+	}
+	return found
+}
+
+// InstallCommandWithOS returns the command installing the tool with the check ID on goos,
+// using the first of the available package managers that provides it. Managers that
+// don't exist on goos are ignored.
+func InstallCommandWithOS(id, goos string, managers []PackageManager) (string, bool) {
+	commands := installCatalog[id]
+	for _, pm := range managers {
+		if !supportsPackageManager(goos, pm) {
+			continue
+		}
+		if cmd, ok := commands[pm]; ok {
+			return cmd, true
+		}
+	}
+	if cmd, ok := installFallbacks[id][goos]; ok {
+		return cmd, true
+	}
+	if cmd, ok := installFallbacks[id][""]; ok {
+		return cmd, true
+	}
+	return "", false
+}
+
+func supportsPackageManager(goos string, pm PackageManager) bool {
+	for _, candidate := range packageManagersByOS[goos] {
+		if candidate == pm {
+			return true
+		}
+	}
+	return false
+}
+
+// installer resolves install commands for missing tools. Package managers are detected
+// once, the first time a tool is missing, and shared by the checks of a registry.
+type installer struct {
+	goos     string
+	once     sync.Once
+	managers []PackageManager
+}
+
+func (i *installer) command(_ context.Context, r Runner, id string) string {
+	i.once.Do(func() {
+		i.managers = DetectPackageManagersWithOS(r, i.goos)
+	})
+	cmd, _ := InstallCommandWithOS(id, i.goos, i.managers)
+	return cmd
+}
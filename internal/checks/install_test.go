@@ -0,0 +1,110 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// managersRunner reports the given binaries as installed and every other command as missing.
+func managersRunner(installed ...string) *MockRunner {
+	return &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			for _, bin := range installed {
+				if name == bin {
+					return []byte("1.0.0"), nil
+				}
+			}
+			return nil, fmt.Errorf("executable file not found in $PATH")
+		},
+	}
+}
+
+func TestDetectPackageManagersWithOS(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		installed []string
+		expected  []PackageManager
+	}{
+		{name: "Ubuntu", goos: "linux", installed: []string{"apt-get", "snap"}, expected: []PackageManager{PackageManagerApt, PackageManagerSnap}},
+		{name: "Fedora", goos: "linux", installed: []string{"dnf"}, expected: []PackageManager{PackageManagerDnf}},
+		{name: "macOS", goos: "darwin", installed: []string{"brew"}, expected: []PackageManager{PackageManagerBrew}},
+		{name: "macOS ignores winget", goos: "darwin", installed: []string{"winget"}, expected: nil},
+		{name: "Windows", goos: "windows", installed: []string{"winget", "choco"}, expected: []PackageManager{PackageManagerWinget, PackageManagerChoco}},
+		{name: "Windows ignores apt", goos: "windows", installed: []string{"apt-get", "choco"}, expected: []PackageManager{PackageManagerChoco}},
+		{name: "Nothing installed", goos: "linux", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectPackageManagersWithOS(managersRunner(tt.installed...), tt.goos))
+		})
+	}
+}
+
+func TestInstallCommandWithOS(t *testing.T) {
+	tests := []struct {
+		tool     string
+		goos     string
+		managers []PackageManager
+		expected string
+	}{
+		{tool: "git", goos: "linux", managers: []PackageManager{PackageManagerApt}, expected: "sudo apt-get install -y git"},
+		{tool: "git", goos: "linux", managers: []PackageManager{PackageManagerDnf}, expected: "sudo dnf install -y git"},
+		{tool: "node", goos: "darwin", managers: []PackageManager{PackageManagerBrew}, expected: "brew install node"},
+		{tool: "node", goos: "windows", managers: []PackageManager{PackageManagerWinget, PackageManagerChoco}, expected: "winget install --id OpenJS.NodeJS.LTS -e"},
+		{tool: "node", goos: "windows", managers: []PackageManager{PackageManagerChoco}, expected: "choco install nodejs-lts"},
+		{tool: "docker", goos: "linux", managers: []PackageManager{PackageManagerDnf}, expected: "sudo dnf install -y podman"},
+		{tool: "bash", goos: "windows", managers: []PackageManager{PackageManagerWinget}, expected: "winget install --id Git.Git -e"},
+		{tool: "pwsh", goos: "linux", managers: []PackageManager{PackageManagerApt, PackageManagerSnap}, expected: "sudo snap install powershell --classic"},
+		{tool: "terraform", goos: "darwin", managers: []PackageManager{PackageManagerBrew}, expected: "brew tap hashicorp/tap && brew install hashicorp/tap/terraform"},
+		{tool: "azd", goos: "linux", managers: []PackageManager{PackageManagerApt}, expected: "curl -fsSL https://aka.ms/install-azd.sh | bash"},
+		{tool: "azd", goos: "darwin", managers: []PackageManager{PackageManagerBrew}, expected: "brew tap azure/azd && brew install azd"},
+		{tool: "swa", goos: "windows", managers: []PackageManager{PackageManagerWinget}, expected: "npm install -g @azure/static-web-apps-cli"},
+		{tool: "func", goos: "linux", managers: []PackageManager{PackageManagerApt}, expected: "npm install -g azure-functions-core-tools@4"},
+		{tool: "git", goos: "darwin", managers: []PackageManager{PackageManagerWinget}, expected: ""},
+		{tool: "pwsh", goos: "linux", managers: []PackageManager{PackageManagerApt}, expected: ""},
+		{tool: "unknown", goos: "linux", managers: []PackageManager{PackageManagerApt}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s/%v", tt.tool, tt.goos, tt.managers), func(t *testing.T) {
+			cmd, ok := InstallCommandWithOS(tt.tool, tt.goos, tt.managers)
+			assert.Equal(t, tt.expected != "", ok)
+			assert.Equal(t, tt.expected, cmd)
+		})
+	}
+}
+
+func TestToolCheckInstallCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		managers []string
+		expected string
+	}{
+		{goos: "linux", managers: []string{"apt-get"}, expected: "curl -fsSL https://deb.nodesource.com/setup_lts.x | sudo -E bash - && sudo apt-get install -y nodejs"},
+		{goos: "darwin", managers: []string{"brew"}, expected: "brew install node"},
+		{goos: "windows", managers: []string{"choco"}, expected: "choco install nodejs-lts"},
+		{goos: "windows", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			node, ok := DefaultRegistryWithOS(tt.goos).Get("node")
+			assert.True(t, ok)
+			res := node.Run(context.Background(), managersRunner(tt.managers...))
+			assert.Equal(t, SeverityFail, res.Status())
+			assert.Equal(t, tt.expected, res.Install)
+		})
+	}
+
+	t.Run("Installed tools have no install command", func(t *testing.T) {
+		node, _ := DefaultRegistryWithOS("darwin").Get("node")
+		res := node.Run(context.Background(), managersRunner("brew", "node"))
+		assert.Equal(t, SeverityFail, res.Status(), "1.0.0 is outside the supported range")
+		assert.Empty(t, res.Install)
+	})
+}
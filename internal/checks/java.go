@@ -0,0 +1,302 @@
+package checks
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// javaVersionPattern matches the version in the output of `java -version`, e.g.
+// `openjdk version "17.0.2" 2022-01-18` or `java version "1.8.0_361"`.
+var javaVersionPattern = regexp.MustCompile(`version "([^"]+)"`)
+
+// CheckJava checks for a JDK. `java -version` prints to stderr, so the combined output is parsed.
+func CheckJava(ctx context.Context, r Runner) CheckResult {
+	out, err := runnerCombinedOutput(ctx, r, "java", "-version")
+	if err != nil {
+		return CheckResult{Name: "java", Installed: false, Error: err, Severity: SeverityFail}
+	}
+	version := cleanVersion("java", string(out))
+	if m := javaVersionPattern.FindStringSubmatch(string(out)); m != nil {
+		version = normalizeJavaVersion(m[1])
+	}
+	return CheckResult{Name: "java", Installed: true, Version: version, Running: true, Severity: SeverityPass,
+		Path: runnerLookPath(r, "java")}
+}
+
+// normalizeJavaVersion turns a Java version string into a semantic version: the legacy
+// "1.8.0_361" scheme becomes "8.0.361" and a bare feature release such as "21" becomes "21.0.0".
+func normalizeJavaVersion(version string) string {
+	version = strings.SplitN(version, "+", 2)[0]
+	if rest, ok := strings.CutPrefix(version, "1."); ok {
+		version = strings.Replace(rest, "_", ".", 1)
+	}
+	version = strings.SplitN(version, "_", 2)[0]
+	for strings.Count(version, ".") < 2 {
+		version += ".0"
+	}
+	return version
+}
+
+// JavaMajorVersion returns the feature release of a Java version, e.g. 8 for "1.8.0_361"
+// and 17 for "17.0.2".
+func JavaMajorVersion(version string) (int, error) {
+	major, _, _ := strings.Cut(normalizeJavaVersion(strings.TrimSpace(version)), ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Java version %q", version)
+	}
+	return n, nil
+}
+
+// Java build tools.
+const (
+	JavaBuildMaven  = "maven"
+	JavaBuildGradle = "gradle"
+)
+
+// JavaProject is the build configuration of a Java service project.
+type JavaProject struct {
+	// BuildTool is JavaBuildMaven or JavaBuildGradle; empty when there is no build file.
+	BuildTool string
+	// File is the build file (pom.xml, build.gradle or build.gradle.kts).
+	File string
+	// Release is the Java release required by the build file; 0 when it doesn't declare one.
+	Release int
+	// Line is the line of the build file that declares the release.
+	Line int
+}
+
+// LoadJavaProject reads the build file of the Java project in dir. A directory without
+// a build file returns an empty project.
+func LoadJavaProject(dir string) (JavaProject, error) {
+	for _, name := range []string{"pom.xml", "build.gradle.kts", "build.gradle"} {
+		file := filepath.Join(dir, name)
+		content, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return JavaProject{}, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if name == "pom.xml" {
+			project := JavaProject{BuildTool: JavaBuildMaven, File: file}
+			project.Release, project.Line, err = mavenRelease(content)
+			if err != nil {
+				return JavaProject{}, fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			return project, nil
+		}
+		project := JavaProject{BuildTool: JavaBuildGradle, File: file}
+		project.Release, project.Line = gradleRelease(content)
+		return project, nil
+	}
+	return JavaProject{}, nil
+}
+
+// mavenReleaseProperties are the pom.xml properties that set the Java release, by priority.
+var mavenReleaseProperties = []string{"maven.compiler.release", "java.version", "maven.compiler.source"}
+
+var mavenPropertyReference = regexp.MustCompile(`^\$\{([^}]+)\}$`)
+
+// mavenRelease returns the Java release set by the properties of a pom.xml, resolving
+// references to other properties (e.g. `<maven.compiler.release>${java.version}</...>`).
+func mavenRelease(content []byte) (int, int, error) {
+	var pom struct {
+		Properties struct {
+			Entries []struct {
+				XMLName xml.Name
+				Value   string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"properties"`
+	}
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return 0, 0, err
+	}
+	properties := make(map[string]string, len(pom.Properties.Entries))
+	for _, entry := range pom.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+
+	for _, name := range mavenReleaseProperties {
+		value, ok := properties[name]
+		// Follow a bounded number of references so cycles can't loop forever.
+		for i := 0; ok && i < 10; i++ {
+			m := mavenPropertyReference.FindStringSubmatch(value)
+			if m == nil {
+				break
+			}
+			value, ok = properties[m[1]]
+		}
+		if !ok {
+			continue
+		}
+		if release, err := JavaMajorVersion(value); err == nil {
+			return release, lineOf(content, "<"+name+">"), nil
+		}
+	}
+	return 0, 0, nil
+}
+
+// gradleReleasePatterns match the Java release of a Gradle build, by priority: the toolchain
+// (`JavaLanguageVersion.of(17)`), then sourceCompatibility (`JavaVersion.VERSION_17`,
+// `JavaVersion.VERSION_1_8`, `'17'` or `1.8`).
+var gradleReleasePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`JavaLanguageVersion\.of\(\s*["']?(\d+)["']?\s*\)`),
+	regexp.MustCompile(`sourceCompatibility\s*=?\s*JavaVersion\.VERSION_(\d+(?:_\d+)?)`),
+	regexp.MustCompile(`sourceCompatibility\s*=?\s*["']?(\d+(?:\.\d+)?)["']?`),
+}
+
+func gradleRelease(content []byte) (int, int) {
+	for _, pattern := range gradleReleasePatterns {
+		m := pattern.FindSubmatchIndex(content)
+		if m == nil {
+			continue
+		}
+		value := strings.ReplaceAll(string(content[m[2]:m[3]]), "_", ".")
+		if release, err := JavaMajorVersion(value); err == nil {
+			return release, 1 + strings.Count(string(content[:m[0]]), "\n")
+		}
+	}
+	return 0, 0
+}
+
+// lineOf returns the line of the first occurrence of s in content, or 0 if it doesn't occur.
+func lineOf(content []byte, s string) int {
+	i := strings.Index(string(content), s)
+	if i < 0 {
+		return 0
+	}
+	return 1 + strings.Count(string(content[:i]), "\n")
+}
+
+// javaReleaseCheck verifies that the installed JDK can build the release a service requires.
+// It reuses the result of the java check.
+type javaReleaseCheck struct {
+	service string
+	project JavaProject
+}
+
+func (c *javaReleaseCheck) ID() string         { return "java-release:" + c.service }
+func (c *javaReleaseCheck) Category() Category { return CategoryLanguage }
+func (c *javaReleaseCheck) Description() string {
+	return fmt.Sprintf("Service %s Java release", c.service)
+}
+func (c *javaReleaseCheck) DependsOn() []string { return []string{"java"} }
+
+func (c *javaReleaseCheck) Applies(config *AzureYaml) bool {
+	return config != nil && config.Services[c.service].Language == "java"
+}
+
+func (c *javaReleaseCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := CheckResult{Name: "Java Release", Version: fmt.Sprintf("%d", c.project.Release)}
+	java, ok := DependencyResult(ctx, "java")
+	if !ok {
+		java = CheckJava(ctx, r)
+	}
+	if !java.Installed {
+		res.Severity = SeveritySkip
+		res.Error = fmt.Errorf("java is not installed")
+		return res
+	}
+
+	res.Installed = true
+	installed, err := JavaMajorVersion(java.Version)
+	if err != nil {
+		res.Error = err
+		res.Severity = SeverityWarn
+		return res
+	}
+	if installed < c.project.Release {
+		res.Error = fmt.Errorf("service %s requires Java %d, found Java %d", c.service, c.project.Release, installed)
+		res.Severity = SeverityFail
+		res.Remediation = fmt.Sprintf("Install JDK %d or later: https://learn.microsoft.com/java/openjdk/download", c.project.Release)
+		res.Location = &Location{File: c.project.File, Line: c.project.Line}
+		return res
+	}
+	res.Severity = SeverityPass
+	return res
+}
+
+// javaBuildCheck verifies that a service's Maven or Gradle build can run: either the
+// project has a build wrapper or the build tool is installed.
+type javaBuildCheck struct {
+	service string
+	dir     string
+	// root is the project root; wrappers are looked up from dir up to root.
+	root    string
+	project JavaProject
+	goos    string
+}
+
+func (c *javaBuildCheck) ID() string          { return "java-build:" + c.service }
+func (c *javaBuildCheck) Category() Category  { return CategoryLanguage }
+func (c *javaBuildCheck) Description() string { return fmt.Sprintf("Service %s Java build", c.service) }
+
+func (c *javaBuildCheck) Applies(config *AzureYaml) bool {
+	return config != nil && config.Services[c.service].Language == "java"
+}
+
+func (c *javaBuildCheck) Run(ctx context.Context, r Runner) CheckResult {
+	var tool, wrapper, remediation string
+	var args []string
+	switch c.project.BuildTool {
+	case JavaBuildMaven:
+		tool, wrapper, args = "mvn", "mvnw", []string{"-v"}
+		remediation = "Install Maven (https://maven.apache.org/install.html) or add the Maven wrapper with `mvn wrapper:wrapper`"
+	case JavaBuildGradle:
+		tool, wrapper, args = "gradle", "gradlew", []string{"--version"}
+		remediation = "Install Gradle (https://gradle.org/install) or add the Gradle wrapper with `gradle wrapper`"
+	default:
+		return CheckResult{
+			Name:        "Java Build",
+			Installed:   true,
+			Error:       fmt.Errorf("no pom.xml or build.gradle found in %s", c.dir),
+			Severity:    SeverityWarn,
+			Remediation: "Set the `project` of the service to the directory of its Maven or Gradle build",
+		}
+	}
+	if c.goos == "windows" {
+		wrapper += map[string]string{"mvnw": ".cmd", "gradlew": ".bat"}[wrapper]
+	}
+
+	if path := findUp(c.dir, c.root, wrapper); path != "" {
+		return CheckResult{Name: "Java Build", Installed: true, Version: wrapper, Severity: SeverityPass, Path: path}
+	}
+	out, err := runnerOutput(ctx, r, tool, args...)
+	if err != nil {
+		return CheckResult{
+			Name:        "Java Build",
+			Error:       fmt.Errorf("%s is not installed and %s has no %s", tool, c.service, wrapper),
+			Severity:    SeverityFail,
+			Remediation: remediation,
+		}
+	}
+	return CheckResult{Name: "Java Build", Installed: true, Version: tool + " " + cleanVersion(tool, string(out)),
+		Severity: SeverityPass, Path: runnerLookPath(r, tool)}
+}
+
+// javaServiceChecks returns the build requirements of a Java service.
+func javaServiceChecks(config *AzureYaml, name, goos string) []Check {
+	dir := config.ServiceDir(name)
+	project, err := LoadJavaProject(dir)
+	if err != nil {
+		return []Check{&projectFileCheck{service: name, err: err}}
+	}
+	var list []Check
+	if project.Release > 0 {
+		list = append(list, &javaReleaseCheck{service: name, project: project})
+	}
+	return append(list, &javaBuildCheck{
+		service: name,
+		dir:     dir,
+		root:    filepath.Dir(config.file),
+		project: project,
+		goos:    goos,
+	})
+}
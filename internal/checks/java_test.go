@@ -0,0 +1,288 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// combinedMockRunner is a MockRunner that also captures stderr, like RealRunner.
+type combinedMockRunner struct {
+	MockRunner
+	CombinedOutputFunc func(name string, args ...string) ([]byte, error)
+}
+
+func (m *combinedMockRunner) CombinedOutput(_ context.Context, name string, args ...string) ([]byte, error) {
+	return m.CombinedOutputFunc(name, args...)
+}
+
+func TestCheckJava(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		err       error
+		installed bool
+		version   string
+	}{
+		{
+			name:      "OpenJDK 17",
+			output:    "openjdk version \"17.0.2\" 2022-01-18\nOpenJDK Runtime Environment (build 17.0.2+8-86)\n",
+			installed: true,
+			version:   "17.0.2",
+		},
+		{
+			name:      "Java 8",
+			output:    "java version \"1.8.0_361\"\nJava(TM) SE Runtime Environment (build 1.8.0_361-b09)\n",
+			installed: true,
+			version:   "8.0.361",
+		},
+		{
+			name:      "Feature release",
+			output:    "openjdk version \"21\" 2023-09-19\n",
+			installed: true,
+			version:   "21.0.0",
+		},
+		{
+			name: "Not installed",
+			err:  fmt.Errorf("executable file not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &combinedMockRunner{
+				MockRunner: MockRunner{OutputFunc: func(string, ...string) ([]byte, error) {
+					// java -version writes nothing to stdout.
+					return nil, nil
+				}},
+				CombinedOutputFunc: func(name string, args ...string) ([]byte, error) {
+					assert.Equal(t, "java", name)
+					assert.Equal(t, []string{"-version"}, args)
+					return []byte(tt.output), tt.err
+				},
+			}
+			res := CheckJava(context.Background(), mock)
+			assert.Equal(t, tt.installed, res.Installed)
+			assert.Equal(t, tt.version, res.Version)
+		})
+	}
+}
+
+func TestJavaMajorVersion(t *testing.T) {
+	tests := map[string]int{
+		"1.8.0_361": 8,
+		"1.8":       8,
+		"11":        11,
+		"17.0.2":    17,
+		"21.0.1+12": 21,
+	}
+	for version, expected := range tests {
+		major, err := JavaMajorVersion(version)
+		require.NoError(t, err, version)
+		assert.Equal(t, expected, major, version)
+	}
+
+	_, err := JavaMajorVersion("${java.version}")
+	assert.Error(t, err)
+}
+
+func TestLoadJavaProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected JavaProject
+	}{
+		{
+			name: "Maven release",
+			file: "pom.xml",
+			content: `<project>
+  <properties>
+    <java.version>17</java.version>
+    <maven.compiler.release>${java.version}</maven.compiler.release>
+  </properties>
+</project>`,
+			expected: JavaProject{BuildTool: JavaBuildMaven, Release: 17, Line: 4},
+		},
+		{
+			name: "Maven java.version",
+			file: "pom.xml",
+			content: `<project>
+  <properties>
+    <java.version>1.8</java.version>
+  </properties>
+</project>`,
+			expected: JavaProject{BuildTool: JavaBuildMaven, Release: 8, Line: 3},
+		},
+		{
+			name:     "Maven without release",
+			file:     "pom.xml",
+			content:  `<project><artifactId>api</artifactId></project>`,
+			expected: JavaProject{BuildTool: JavaBuildMaven},
+		},
+		{
+			name: "Gradle toolchain",
+			file: "build.gradle.kts",
+			content: `plugins { java }
+
+java {
+    toolchain {
+        languageVersion = JavaLanguageVersion.of(21)
+    }
+}`,
+			expected: JavaProject{BuildTool: JavaBuildGradle, Release: 21, Line: 5},
+		},
+		{
+			name:     "Gradle sourceCompatibility",
+			file:     "build.gradle",
+			content:  "sourceCompatibility = JavaVersion.VERSION_1_8\n",
+			expected: JavaProject{BuildTool: JavaBuildGradle, Release: 8, Line: 1},
+		},
+		{
+			name:     "Gradle quoted sourceCompatibility",
+			file:     "build.gradle",
+			content:  "group = 'com.example'\nsourceCompatibility = '17'\n",
+			expected: JavaProject{BuildTool: JavaBuildGradle, Release: 17, Line: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, tt.file)
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0644))
+			tt.expected.File = file
+
+			project, err := LoadJavaProject(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, project)
+		})
+	}
+
+	t.Run("No build file", func(t *testing.T) {
+		project, err := LoadJavaProject(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, JavaProject{}, project)
+	})
+
+	t.Run("Invalid pom.xml", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pom.xml"), []byte("<project><properties>"), 0644))
+		_, err := LoadJavaProject(dir)
+		assert.ErrorContains(t, err, "failed to parse")
+	})
+}
+
+func TestJavaReleaseCheck(t *testing.T) {
+	project := JavaProject{BuildTool: JavaBuildMaven, File: "pom.xml", Release: 17, Line: 4}
+	tests := []struct {
+		name   string
+		java   CheckResult
+		status Severity
+		errMsg string
+	}{
+		{name: "Newer JDK", java: CheckResult{Installed: true, Version: "21.0.1"}, status: SeverityPass},
+		{name: "Same release", java: CheckResult{Installed: true, Version: "17.0.2"}, status: SeverityPass},
+		{
+			name:   "Older JDK",
+			java:   CheckResult{Installed: true, Version: "11.0.20"},
+			status: SeverityFail,
+			errMsg: "service api requires Java 17, found Java 11",
+		},
+		{name: "No JDK", java: CheckResult{}, status: SeveritySkip, errMsg: "java is not installed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), dependencyResultsKey{}, map[string]CheckResult{"java": tt.java})
+			res := (&javaReleaseCheck{service: "api", project: project}).Run(ctx, &MockRunner{})
+			assert.Equal(t, tt.status, res.Status())
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+				return
+			}
+			assert.ErrorContains(t, res.Error, tt.errMsg)
+			if tt.status == SeverityFail {
+				assert.Equal(t, &Location{File: "pom.xml", Line: 4}, res.Location)
+				assert.Contains(t, res.Remediation, "Install JDK 17 or later")
+			}
+		})
+	}
+}
+
+func TestJavaBuildCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		buildTool string
+		wrapper   string
+		goos      string
+		toolErr   error
+		status    Severity
+		version   string
+	}{
+		{name: "Maven wrapper", buildTool: JavaBuildMaven, wrapper: "mvnw", goos: "linux", status: SeverityPass, version: "mvnw"},
+		{name: "Maven wrapper on Windows", buildTool: JavaBuildMaven, wrapper: "mvnw.cmd", goos: "windows", status: SeverityPass, version: "mvnw.cmd"},
+		{name: "Maven installed", buildTool: JavaBuildMaven, goos: "linux", status: SeverityPass, version: "mvn 3.9.6"},
+		{name: "Maven missing", buildTool: JavaBuildMaven, goos: "linux", toolErr: fmt.Errorf("not found"), status: SeverityFail},
+		{name: "Gradle wrapper in project root", buildTool: JavaBuildGradle, wrapper: "../gradlew", goos: "linux", status: SeverityPass, version: "gradlew"},
+		{name: "Gradle missing", buildTool: JavaBuildGradle, goos: "linux", toolErr: fmt.Errorf("not found"), status: SeverityFail},
+		{name: "No build file", goos: "linux", status: SeverityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "src", "api")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			if tt.wrapper != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, tt.wrapper), []byte("#!/bin/sh"), 0755))
+			}
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					return []byte("Apache Maven 3.9.6 (bc0240f3c744dd6b6ec2920b3cd08dcc295161ae)"), tt.toolErr
+				},
+			}
+			c := &javaBuildCheck{service: "api", dir: dir, root: root, project: JavaProject{BuildTool: tt.buildTool}, goos: tt.goos}
+			res := c.Run(context.Background(), mock)
+			assert.Equal(t, tt.status, res.Status())
+			if tt.version != "" {
+				assert.Equal(t, tt.version, res.Version)
+			}
+			if tt.status != SeverityPass {
+				assert.NotEmpty(t, res.Remediation)
+			}
+		})
+	}
+}
+
+func TestPlanJavaService(t *testing.T) {
+	dir := t.TempDir()
+	content := `name: test-project
+services:
+  api:
+    project: ./src/api
+    language: java
+    host: containerapp
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "azure.yaml"), []byte(content), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src", "api"), 0755))
+	pom := "<project>\n  <properties>\n    <maven.compiler.release>17</maven.compiler.release>\n  </properties>\n</project>\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "api", "pom.xml"), []byte(pom), 0644))
+	config, err := LoadProjectConfig(filepath.Join(dir, "azure.yaml"))
+	require.NoError(t, err)
+
+	planner := NewPlannerWithOS(NewRegistry(), "linux")
+	assert.Equal(t, []string{"java-release:api (api)", "java-build:api (api)"},
+		requirementStrings(planner.Plan(config, PlanOptions{Command: "package"})))
+	assert.Equal(t, []string{}, requirementStrings(planner.Plan(config, PlanOptions{Command: "provision"})))
+
+	req, ok := planner.Plan(config, PlanOptions{}).Find("java-build:api")
+	require.True(t, ok)
+	require.NotNil(t, req.Location)
+	assert.Equal(t, 4, req.Location.Line)
+}
@@ -0,0 +1,72 @@
+package checks
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Location points at a position in a project file. It lets reports such as SARIF
+// annotate azure.yaml problems (e.g. an unknown host) at the offending line.
+type Location struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// Location returns the position of the key at the given path in azure.yaml, e.g.
+// Location("services", "api", "host"). Sequence items are addressed by their index,
+// e.g. Location("x-doctor", "checks", "0"). It returns nil if the config was not loaded
+// from a file or the key does not exist.
+func (c *AzureYaml) Location(path ...string) *Location {
+	if c == nil || c.root == nil {
+		return nil
+	}
+
+	node := c.root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	var key *yaml.Node
+	for _, name := range path {
+		if node.Kind == yaml.SequenceNode {
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			key, node = node.Content[i], node.Content[i]
+			continue
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		key = nil
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name {
+				key, node = node.Content[i], node.Content[i+1]
+				break
+			}
+		}
+		if key == nil {
+			return nil
+		}
+	}
+	if key == nil {
+		return &Location{File: c.file, Line: 1, Column: 1}
+	}
+	return &Location{File: c.file, Line: key.Line, Column: key.Column}
+}
+
+// HookLocation returns the position of a hook's shell, or of the hook itself when it
+// doesn't set one. An empty service refers to the project hooks.
+func (c *AzureYaml) HookLocation(service, hook string) *Location {
+	path := []string{"hooks", hook}
+	if service != "" {
+		path = append([]string{"services", service}, path...)
+	}
+	if loc := c.Location(append(path, "shell")...); loc != nil {
+		return loc
+	}
+	return c.Location(path...)
+}
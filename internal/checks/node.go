@@ -0,0 +1,422 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Node.js package managers.
+const (
+	PackageManagerNpm  = "npm"
+	PackageManagerPnpm = "pnpm"
+	PackageManagerYarn = "yarn"
+)
+
+// nodeLockfiles maps the lockfiles of the Node.js package managers to their manager, by priority.
+var nodeLockfiles = []struct{ file, manager string }{
+	{"pnpm-lock.yaml", PackageManagerPnpm},
+	{"yarn.lock", PackageManagerYarn},
+	{"package-lock.json", PackageManagerNpm},
+	{"npm-shrinkwrap.json", PackageManagerNpm},
+}
+
+// nodeModulesMarkers are the files each package manager writes into node_modules on
+// install; their modification time is the time of the last install.
+var nodeModulesMarkers = map[string][]string{
+	PackageManagerNpm:  {".package-lock.json"},
+	PackageManagerPnpm: {".modules.yaml"},
+	PackageManagerYarn: {".yarn-state.yml", ".yarn-integrity"},
+}
+
+// NodeProject is the package configuration of a Node.js service project.
+type NodeProject struct {
+	// PackageJSON is the path of package.json; empty when the project has none.
+	PackageJSON string
+	// PackageManager is npm, pnpm or yarn, from the packageManager field or the lockfile.
+	PackageManager string
+	// PackageManagerVersion is the version pinned by the packageManager field, if any.
+	PackageManagerVersion string
+	PackageManagerLine    int
+	// Lockfile is the path of the lockfile, if any.
+	Lockfile string
+	// Engines is the engines.node range of package.json, if any.
+	Engines     string
+	EnginesLine int
+	// VersionFile is the .nvmrc or .node-version file pinning the Node.js version, if any.
+	VersionFile string
+	// PinnedVersion is the version in VersionFile, e.g. "20" or "20.11.0". Aliases such as
+	// "lts/*" are not pinned versions and leave it empty.
+	PinnedVersion string
+	// HasDependencies is set when package.json declares dependencies to install.
+	HasDependencies bool
+}
+
+var pinnedNodeVersionPattern = regexp.MustCompile(`^v?(\d+(?:\.\d+){0,2})$`)
+
+// LoadNodeProject reads package.json, the lockfile and the version file of the Node.js
+// project in dir. A directory without package.json returns a project without PackageJSON.
+func LoadNodeProject(dir string) (NodeProject, error) {
+	project := NodeProject{PackageManager: PackageManagerNpm}
+	for _, lock := range nodeLockfiles {
+		path := filepath.Join(dir, lock.file)
+		if _, err := os.Stat(path); err == nil {
+			project.Lockfile, project.PackageManager = path, lock.manager
+			break
+		}
+	}
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		project.VersionFile = path
+		if m := pinnedNodeVersionPattern.FindStringSubmatch(strings.TrimSpace(string(content))); m != nil {
+			project.PinnedVersion = m[1]
+		}
+		break
+	}
+
+	path := filepath.Join(dir, "package.json")
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return project, nil
+	}
+	if err != nil {
+		return NodeProject{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var pkg struct {
+		PackageManager  string            `json:"packageManager"`
+		Engines         map[string]string `json:"engines"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return NodeProject{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	project.PackageJSON = path
+	project.HasDependencies = len(pkg.Dependencies)+len(pkg.DevDependencies) > 0
+	if pkg.PackageManager != "" {
+		// e.g. "pnpm@8.15.4+sha256.abc..."
+		name, version, _ := strings.Cut(pkg.PackageManager, "@")
+		project.PackageManager = name
+		project.PackageManagerVersion, _, _ = strings.Cut(version, "+")
+		project.PackageManagerLine = lineOf(content, `"packageManager"`)
+	}
+	if engines := strings.TrimSpace(pkg.Engines["node"]); engines != "" {
+		project.Engines = engines
+		project.EnginesLine = lineOf(content, `"engines"`)
+	}
+	return project, nil
+}
+
+var (
+	npmHyphenRange = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+	npmOperator    = regexp.MustCompile(`([<>=~^]+)\s+`)
+	npmComparator  = regexp.MustCompile(`^([<>=~^]*)v?(.*)$`)
+)
+
+// NpmRange converts an npm semver range such as "^18 || >=20.1" or "18.x" (as used
+// by engines.node) to a range of full versions that semver.ParseRange understands.
+func NpmRange(npmRange string) string {
+	var alternatives []string
+	for _, alt := range strings.Split(npmRange, "||") {
+		alt = npmOperator.ReplaceAllString(strings.TrimSpace(alt), "$1")
+		if m := npmHyphenRange.FindStringSubmatch(alt); m != nil {
+			alt = ">=" + m[1] + " <=" + m[2]
+		}
+		var comparators []string
+		for _, token := range strings.Fields(alt) {
+			comparators = append(comparators, npmComparatorRange(token)...)
+		}
+		if len(comparators) == 0 {
+			comparators = []string{">=0.0.0"}
+		}
+		alternatives = append(alternatives, strings.Join(comparators, " "))
+	}
+	return strings.Join(alternatives, " || ")
+}
+
+// npmComparatorRange converts a single npm comparator (e.g. "^18.2", "~1.2", ">=20",
+// "18.x") to one or two comparators with full versions.
+func npmComparatorRange(token string) []string {
+	m := npmComparator.FindStringSubmatch(token)
+	op, version := m[1], m[2]
+
+	// Wildcards and missing parts make a partial version.
+	var parts []int
+	prerelease := ""
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version, prerelease = version[:i], version[i:]
+	}
+	for _, part := range strings.Split(version, ".") {
+		if part == "x" || part == "X" || part == "*" || part == "" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return []string{token}
+		}
+		parts = append(parts, n)
+	}
+	if len(parts) == 0 {
+		return []string{">=0.0.0"}
+	}
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+
+	lower := fullVersion(parts)
+	if len(parts) == 3 {
+		lower += prerelease
+	}
+
+	switch op {
+	case "^":
+		// Compatible with the first non-zero part.
+		upper := parts[:1]
+		for i, n := range parts {
+			upper = parts[:i+1]
+			if n != 0 {
+				break
+			}
+		}
+		return []string{">=" + lower, "<" + nextVersion(upper)}
+	case "~":
+		if len(parts) == 1 {
+			return []string{">=" + lower, "<" + nextVersion(parts)}
+		}
+		return []string{">=" + lower, "<" + nextVersion(parts[:2])}
+	case ">":
+		if len(parts) < 3 {
+			return []string{">=" + nextVersion(parts)}
+		}
+		return []string{">" + lower}
+	case "<=":
+		if len(parts) < 3 {
+			return []string{"<" + nextVersion(parts)}
+		}
+		return []string{"<=" + lower}
+	case ">=", "<":
+		return []string{op + lower}
+	case "", "=":
+		if len(parts) < 3 {
+			return []string{">=" + lower, "<" + nextVersion(parts)}
+		}
+		return []string{"=" + lower}
+	}
+	return []string{token}
+}
+
+// nodeVersionCheck verifies the installed Node.js version against engines.node and the
+// version pinned by .nvmrc or .node-version. It reuses the result of the node check, and
+// asks node for its version again in the service directory, where a version manager may
+// select another release.
+type nodeVersionCheck struct {
+	service string
+	dir     string
+	project NodeProject
+}
+
+func (c *nodeVersionCheck) ID() string         { return "node-version:" + c.service }
+func (c *nodeVersionCheck) Category() Category { return CategoryLanguage }
+func (c *nodeVersionCheck) Description() string {
+	return fmt.Sprintf("Service %s Node.js version", c.service)
+}
+func (c *nodeVersionCheck) DependsOn() []string { return []string{"node"} }
+
+func (c *nodeVersionCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isNodeLanguage(config.Services[c.service].Language)
+}
+
+func (c *nodeVersionCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := CheckResult{Name: "Node.js Version"}
+	node, ok := DependencyResult(ctx, "node")
+	if !ok {
+		node = CheckNode(r)
+	}
+	if !node.Installed {
+		res.Severity = SeveritySkip
+		res.Error = fmt.Errorf("node is not installed")
+		return res
+	}
+	res.Installed = true
+	res.Version = serviceVersion(ctx, r, c.dir, "node", node)
+	installed, err := ParseVersion(res.Version)
+	if err != nil {
+		res.Error = fmt.Errorf("could not determine the node version")
+		res.Severity = SeverityWarn
+		return res
+	}
+
+	if c.project.Engines != "" {
+		ok, err := SatisfiesRange(installed, c.project.Engines)
+		location := &Location{File: c.project.PackageJSON, Line: c.project.EnginesLine}
+		if err != nil {
+			res.Error = fmt.Errorf("unsupported engines.node range %q", c.project.Engines)
+			res.Severity = SeverityWarn
+			res.Location = location
+			return res
+		}
+		if !ok {
+			res.Error = fmt.Errorf("node %s does not satisfy engines.node %q of service %s", installed, c.project.Engines, c.service)
+			res.Severity = SeverityFail
+			res.Remediation = fmt.Sprintf("Install a Node.js version matching %s: https://nodejs.org", c.project.Engines)
+			res.Location = location
+			return res
+		}
+	}
+
+	if c.project.PinnedVersion != "" && !matchesPinnedVersion([]uint64{installed.Major, installed.Minor, installed.Patch}, c.project.PinnedVersion) {
+		res.Error = fmt.Errorf("node %s does not match %s in %s", installed, c.project.PinnedVersion, filepath.Base(c.project.VersionFile))
+		res.Severity = SeverityWarn
+		res.Remediation = fmt.Sprintf("Switch to Node.js %s (e.g. `nvm use`)", c.project.PinnedVersion)
+		res.Location = &Location{File: c.project.VersionFile, Line: 1}
+		return res
+	}
+
+	res.Severity = SeverityPass
+	return res
+}
+
+// nodePackageManagerCheck verifies that the package manager of a service is installed,
+// either globally or through corepack. It runs in the service directory, since corepack
+// and volta shims resolve the version from the service's package.json.
+type nodePackageManagerCheck struct {
+	service string
+	dir     string
+	project NodeProject
+}
+
+func (c *nodePackageManagerCheck) ID() string         { return "node-package-manager:" + c.service }
+func (c *nodePackageManagerCheck) Category() Category { return CategoryLanguage }
+func (c *nodePackageManagerCheck) Description() string {
+	return fmt.Sprintf("Service %s package manager", c.service)
+}
+
+func (c *nodePackageManagerCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isNodeLanguage(config.Services[c.service].Language)
+}
+
+func (c *nodePackageManagerCheck) Run(ctx context.Context, r Runner) CheckResult {
+	pm := c.project.PackageManager
+	out, err := runnerOutputInDir(ctx, r, c.dir, pm, "--version")
+	if err != nil {
+		res := CheckResult{Name: "Package Manager", Version: pm, Error: fmt.Errorf("%s is not installed", pm), Severity: SeverityFail}
+		switch {
+		case pm == PackageManagerNpm:
+			res.Remediation = "Reinstall Node.js, which includes npm: https://nodejs.org"
+		case c.corepack(ctx, r):
+			res.Remediation = fmt.Sprintf("Run `corepack enable` to install %s", pm)
+		default:
+			res.Remediation = fmt.Sprintf("Install %s: npm install -g %s", pm, pm)
+		}
+		return res
+	}
+
+	version := cleanVersion(pm, string(out))
+	res := CheckResult{Name: "Package Manager", Installed: true, Version: pm + " " + version, Severity: SeverityPass,
+		Path: runnerLookPath(r, pm)}
+	if c.project.PackageManagerVersion != "" {
+		want, errWant := ParseVersion(c.project.PackageManagerVersion)
+		got, errGot := ParseVersion(version)
+		if errWant == nil && errGot == nil && want.Major != got.Major {
+			res.Error = fmt.Errorf("packageManager requires %s %s, found %s", pm, c.project.PackageManagerVersion, version)
+			res.Severity = SeverityWarn
+			res.Remediation = "Run `corepack enable` so the version pinned by packageManager is used"
+			res.Location = &Location{File: c.project.PackageJSON, Line: c.project.PackageManagerLine}
+		}
+	}
+	return res
+}
+
+// corepack reports whether corepack is available to provide pnpm or yarn.
+func (c *nodePackageManagerCheck) corepack(ctx context.Context, r Runner) bool {
+	_, err := runnerOutputInDir(ctx, r, c.dir, "corepack", "--version")
+	return err == nil
+}
+
+// nodeModulesCheck warns when the dependencies of a service are not installed or were
+// installed before the lockfile last changed.
+type nodeModulesCheck struct {
+	service string
+	dir     string
+	project NodeProject
+}
+
+func (c *nodeModulesCheck) ID() string         { return "node-modules:" + c.service }
+func (c *nodeModulesCheck) Category() Category { return CategoryLanguage }
+func (c *nodeModulesCheck) Description() string {
+	return fmt.Sprintf("Service %s node_modules", c.service)
+}
+
+func (c *nodeModulesCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isNodeLanguage(config.Services[c.service].Language)
+}
+
+func (c *nodeModulesCheck) Run(context.Context, Runner) CheckResult {
+	res := CheckResult{Name: "node_modules", Installed: true}
+	remediation := fmt.Sprintf("Run `%s install` in %s", c.project.PackageManager, c.dir)
+
+	modules := filepath.Join(c.dir, "node_modules")
+	info, err := os.Stat(modules)
+	if err != nil {
+		res.Error = fmt.Errorf("node_modules is missing")
+		res.Severity = SeverityWarn
+		res.Remediation = remediation
+		return res
+	}
+	res.Path = modules
+	if c.project.Lockfile == "" {
+		res.Severity = SeverityPass
+		return res
+	}
+
+	installed := info.ModTime()
+	for _, marker := range nodeModulesMarkers[c.project.PackageManager] {
+		if markerInfo, err := os.Stat(filepath.Join(modules, marker)); err == nil {
+			installed = markerInfo.ModTime()
+			break
+		}
+	}
+	lock, err := os.Stat(c.project.Lockfile)
+	if err == nil && lock.ModTime().After(installed) {
+		res.Error = fmt.Errorf("node_modules is older than %s", filepath.Base(c.project.Lockfile))
+		res.Severity = SeverityWarn
+		res.Remediation = remediation
+		return res
+	}
+	res.Severity = SeverityPass
+	return res
+}
+
+func isNodeLanguage(language string) bool {
+	return language == "js" || language == "ts"
+}
+
+// nodeServiceChecks returns the package requirements of a Node.js service.
+func nodeServiceChecks(config *AzureYaml, name string) []Check {
+	dir := config.ServiceDir(name)
+	project, err := LoadNodeProject(dir)
+	if err != nil {
+		return []Check{&projectFileCheck{service: name, err: err}}
+	}
+	if project.PackageJSON == "" {
+		// Nothing to install; azd reports the missing package.json itself.
+		return nil
+	}
+	list := []Check{&nodePackageManagerCheck{service: name, dir: dir, project: project}}
+	if project.Engines != "" || project.PinnedVersion != "" {
+		list = append(list, &nodeVersionCheck{service: name, dir: dir, project: project})
+	}
+	if project.HasDependencies {
+		list = append(list, &nodeModulesCheck{service: name, dir: dir, project: project})
+	}
+	return list
+}
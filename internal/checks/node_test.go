@@ -0,0 +1,357 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNpmRange(t *testing.T) {
+	tests := []struct {
+		npmRange string
+		expected string
+		matches  []string
+		rejects  []string
+	}{
+		{npmRange: ">=18", expected: ">=18.0.0", matches: []string{"18.0.0", "22.1.0"}, rejects: []string{"16.20.2"}},
+		{npmRange: "^18.17.0", expected: ">=18.17.0 <19.0.0", matches: []string{"18.19.1"}, rejects: []string{"18.16.0", "20.0.0"}},
+		{npmRange: "~20.1", expected: ">=20.1.0 <20.2.0", matches: []string{"20.1.5"}, rejects: []string{"20.2.0"}},
+		{npmRange: "18.x || 20.x", expected: ">=18.0.0 <19.0.0 || >=20.0.0 <21.0.0", matches: []string{"18.1.0", "20.11.0"}, rejects: []string{"19.0.0"}},
+		{npmRange: ">= 18 < 21", expected: ">=18.0.0 <21.0.0", matches: []string{"20.11.0"}, rejects: []string{"21.0.0"}},
+		{npmRange: "18 - 20", expected: ">=18.0.0 <21.0.0", matches: []string{"20.11.0"}, rejects: []string{"21.0.0"}},
+		{npmRange: "^0.2.3", expected: ">=0.2.3 <0.3.0"},
+		{npmRange: ">16", expected: ">=17.0.0"},
+		{npmRange: "*", expected: ">=0.0.0", matches: []string{"20.11.0"}},
+		{npmRange: "v20.11.0", expected: "=20.11.0", matches: []string{"20.11.0"}, rejects: []string{"20.11.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.npmRange, func(t *testing.T) {
+			converted := NpmRange(tt.npmRange)
+			assert.Equal(t, tt.expected, converted)
+			for _, v := range tt.matches {
+				ok, err := SatisfiesRange(mustParseVersion(t, v), converted)
+				require.NoError(t, err)
+				assert.True(t, ok, v)
+			}
+			for _, v := range tt.rejects {
+				ok, err := SatisfiesRange(mustParseVersion(t, v), converted)
+				require.NoError(t, err)
+				assert.False(t, ok, v)
+			}
+		})
+	}
+}
+
+func mustParseVersion(t *testing.T, version string) semver.Version {
+	v, err := ParseVersion(version)
+	require.NoError(t, err)
+	return v
+}
+
+// writeFiles writes files relative to dir, creating their directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestLoadNodeProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected NodeProject
+	}{
+		{
+			name: "npm lockfile",
+			files: map[string]string{
+				"package.json":      `{"dependencies": {"express": "^4.19.0"}}`,
+				"package-lock.json": `{}`,
+			},
+			expected: NodeProject{PackageManager: PackageManagerNpm, Lockfile: "package-lock.json", HasDependencies: true},
+		},
+		{
+			name: "packageManager field and engines",
+			files: map[string]string{
+				"package.json":   "{\n  \"packageManager\": \"pnpm@8.15.4+sha256.abc\",\n  \"engines\": {\n    \"node\": \">=20\"\n  }\n}",
+				"pnpm-lock.yaml": "lockfileVersion: '6.0'",
+				".nvmrc":         "v20.11.0\n",
+			},
+			expected: NodeProject{
+				PackageManager:        PackageManagerPnpm,
+				PackageManagerVersion: "8.15.4",
+				PackageManagerLine:    2,
+				Lockfile:              "pnpm-lock.yaml",
+				Engines:               ">=20",
+				EnginesLine:           3,
+				VersionFile:           ".nvmrc",
+				PinnedVersion:         "20.11.0",
+			},
+		},
+		{
+			name: "yarn lockfile and version alias",
+			files: map[string]string{
+				"package.json":  `{"devDependencies": {"typescript": "^5"}}`,
+				"yarn.lock":     "",
+				".node-version": "lts/iron",
+			},
+			expected: NodeProject{PackageManager: PackageManagerYarn, Lockfile: "yarn.lock", VersionFile: ".node-version", HasDependencies: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			tt.expected.PackageJSON = filepath.Join(dir, "package.json")
+			if tt.expected.Lockfile != "" {
+				tt.expected.Lockfile = filepath.Join(dir, tt.expected.Lockfile)
+			}
+			if tt.expected.VersionFile != "" {
+				tt.expected.VersionFile = filepath.Join(dir, tt.expected.VersionFile)
+			}
+
+			project, err := LoadNodeProject(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, project)
+		})
+	}
+
+	t.Run("Invalid package.json", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"package.json": "{"})
+		_, err := LoadNodeProject(dir)
+		assert.ErrorContains(t, err, "failed to parse")
+	})
+}
+
+func TestNodeVersionCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		project NodeProject
+		node    CheckResult
+		status  Severity
+		errMsg  string
+	}{
+		{
+			name:    "Satisfies engines",
+			project: NodeProject{PackageJSON: "package.json", Engines: "^20.9.0", EnginesLine: 5},
+			node:    CheckResult{Installed: true, Version: "20.11.0"},
+			status:  SeverityPass,
+		},
+		{
+			name:    "Outside engines",
+			project: NodeProject{PackageJSON: "package.json", Engines: ">=20", EnginesLine: 5},
+			node:    CheckResult{Installed: true, Version: "18.19.1"},
+			status:  SeverityFail,
+			errMsg:  `node 18.19.1 does not satisfy engines.node ">=20" of service web`,
+		},
+		{
+			name:    "Matches .nvmrc major",
+			project: NodeProject{VersionFile: ".nvmrc", PinnedVersion: "20"},
+			node:    CheckResult{Installed: true, Version: "20.11.0"},
+			status:  SeverityPass,
+		},
+		{
+			name:    "Differs from .nvmrc",
+			project: NodeProject{VersionFile: ".nvmrc", PinnedVersion: "20.11.0"},
+			node:    CheckResult{Installed: true, Version: "20.10.0"},
+			status:  SeverityWarn,
+			errMsg:  "node 20.10.0 does not match 20.11.0 in .nvmrc",
+		},
+		{
+			name:    "Node not installed",
+			project: NodeProject{PackageJSON: "package.json", Engines: ">=20"},
+			status:  SeveritySkip,
+			errMsg:  "node is not installed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), dependencyResultsKey{}, map[string]CheckResult{"node": tt.node})
+			res := (&nodeVersionCheck{service: "web", project: tt.project}).Run(ctx, &MockRunner{})
+			assert.Equal(t, tt.status, res.Status())
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.ErrorContains(t, res.Error, tt.errMsg)
+			}
+			if tt.status == SeverityFail {
+				assert.Equal(t, &Location{File: "package.json", Line: 5}, res.Location)
+			}
+		})
+	}
+}
+
+func TestNodeServiceChecks_ServiceDir(t *testing.T) {
+	// With volta or corepack, the versions depend on the pins of the service directory.
+	dir := t.TempDir()
+	project := NodeProject{PackageJSON: "package.json", PackageManager: PackageManagerPnpm, PackageManagerVersion: "8.15.4",
+		Engines: ">=20", EnginesLine: 5}
+	mock := &DirMockRunner{
+		MockRunner: MockRunner{OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("9.1.0"), nil
+		}},
+		OutputInDirFunc: func(d, name string, args ...string) ([]byte, error) {
+			assert.Equal(t, dir, d)
+			if name == "node" {
+				return []byte("v20.11.1"), nil
+			}
+			return []byte("8.15.4"), nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), dependencyResultsKey{}, map[string]CheckResult{
+		"node": {Installed: true, Version: "18.19.0"},
+	})
+
+	res := (&nodeVersionCheck{service: "web", dir: dir, project: project}).Run(ctx, mock)
+	assert.Equal(t, SeverityPass, res.Status(), res.Error)
+	assert.Equal(t, "20.11.1", res.Version)
+
+	res = (&nodePackageManagerCheck{service: "web", dir: dir, project: project}).Run(ctx, mock)
+	assert.Equal(t, SeverityPass, res.Status(), res.Error)
+	assert.Equal(t, "pnpm 8.15.4", res.Version)
+}
+
+func TestNodePackageManagerCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		project     NodeProject
+		installed   map[string]string
+		status      Severity
+		version     string
+		remediation string
+	}{
+		{
+			name:      "npm installed",
+			project:   NodeProject{PackageJSON: "package.json", PackageManager: PackageManagerNpm},
+			installed: map[string]string{"npm": "10.2.4"},
+			status:    SeverityPass,
+			version:   "npm 10.2.4",
+		},
+		{
+			name:        "pnpm through corepack",
+			project:     NodeProject{PackageJSON: "package.json", PackageManager: PackageManagerPnpm},
+			installed:   map[string]string{"npm": "10.2.4", "corepack": "0.24.0"},
+			status:      SeverityFail,
+			remediation: "Run `corepack enable` to install pnpm",
+		},
+		{
+			name:        "yarn missing",
+			project:     NodeProject{PackageJSON: "package.json", PackageManager: PackageManagerYarn},
+			installed:   map[string]string{"npm": "10.2.4"},
+			status:      SeverityFail,
+			remediation: "Install yarn: npm install -g yarn",
+		},
+		{
+			name:        "Pinned major differs",
+			project:     NodeProject{PackageJSON: "package.json", PackageManager: PackageManagerPnpm, PackageManagerVersion: "8.15.4", PackageManagerLine: 2},
+			installed:   map[string]string{"pnpm": "9.1.0"},
+			status:      SeverityWarn,
+			version:     "pnpm 9.1.0",
+			remediation: "Run `corepack enable` so the version pinned by packageManager is used",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					assert.Equal(t, []string{"--version"}, args)
+					if version, ok := tt.installed[name]; ok {
+						return []byte(version + "\n"), nil
+					}
+					return nil, fmt.Errorf("executable file not found")
+				},
+			}
+			res := (&nodePackageManagerCheck{service: "web", project: tt.project}).Run(context.Background(), mock)
+			assert.Equal(t, tt.status, res.Status())
+			assert.Equal(t, tt.remediation, res.Remediation)
+			if tt.version != "" {
+				assert.Equal(t, tt.version, res.Version)
+			}
+		})
+	}
+}
+
+func TestNodeModulesCheck(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, dir string)
+		status Severity
+		errMsg string
+	}{
+		{
+			name:   "Missing",
+			setup:  func(t *testing.T, dir string) {},
+			status: SeverityWarn,
+			errMsg: "node_modules is missing",
+		},
+		{
+			name: "Up to date",
+			setup: func(t *testing.T, dir string) {
+				require.NoError(t, os.Chtimes(filepath.Join(dir, "package-lock.json"), old, old))
+				writeFiles(t, dir, map[string]string{"node_modules/.package-lock.json": "{}"})
+			},
+			status: SeverityPass,
+		},
+		{
+			name: "Older than the lockfile",
+			setup: func(t *testing.T, dir string) {
+				writeFiles(t, dir, map[string]string{"node_modules/.package-lock.json": "{}"})
+				require.NoError(t, os.Chtimes(filepath.Join(dir, "node_modules", ".package-lock.json"), old, old))
+			},
+			status: SeverityWarn,
+			errMsg: "node_modules is older than package-lock.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"package-lock.json": "{}"})
+			tt.setup(t, dir)
+			project := NodeProject{PackageManager: PackageManagerNpm, Lockfile: filepath.Join(dir, "package-lock.json")}
+
+			res := (&nodeModulesCheck{service: "web", dir: dir, project: project}).Run(context.Background(), &MockRunner{})
+			assert.Equal(t, tt.status, res.Status())
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.ErrorContains(t, res.Error, tt.errMsg)
+				assert.Equal(t, "Run `npm install` in "+dir, res.Remediation)
+			}
+		})
+	}
+}
+
+func TestPlanNodeService(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"azure.yaml":                   "name: test-project\nservices:\n  web:\n    project: ./src/web\n    language: ts\n    host: staticwebapp\n  worker:\n    project: ./src/worker\n    language: js\n    host: appservice\n",
+		"src/web/package.json":         `{"engines": {"node": ">=20"}, "dependencies": {"react": "^18"}}`,
+		"src/web/package-lock.json":    `{}`,
+		"src/worker/nothing-to-see.md": "",
+	})
+	config, err := LoadProjectConfig(filepath.Join(dir, "azure.yaml"))
+	require.NoError(t, err)
+
+	planner := NewPlannerWithOS(NewRegistry(), "linux")
+	assert.Equal(t, []string{
+		"node-package-manager:web (web)",
+		"node-version:web (web)",
+		"node-modules:web (web)",
+	}, requirementStrings(planner.Plan(config, PlanOptions{Command: "deploy"})))
+}
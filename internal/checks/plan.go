@@ -0,0 +1,493 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CategoryExtension groups the azd extension checks derived from requiredVersions.extensions.
+const CategoryExtension Category = "extension"
+
+// Requirement is a single, deduplicated entry of a Plan.
+type Requirement struct {
+	Check Check
+	// Services lists the services that need the requirement; empty for project-wide requirements.
+	Services []string
+	// Location points at the azure.yaml entry that introduced the requirement, if known.
+	Location *Location
+}
+
+// Plan is the ordered list of requirements to verify for a project and azd command.
+type Plan struct {
+	Command      string
+	Requirements []Requirement
+}
+
+// Checks returns the checks of the plan in order.
+func (p *Plan) Checks() []Check {
+	list := make([]Check, 0, len(p.Requirements))
+	for _, req := range p.Requirements {
+		list = append(list, req.Check)
+	}
+	return list
+}
+
+// Find returns the requirement for the given check ID.
+func (p *Plan) Find(id string) (Requirement, bool) {
+	for _, req := range p.Requirements {
+		if req.Check.ID() == id {
+			return req, true
+		}
+	}
+	return Requirement{}, false
+}
+
+// PlanOptions controls which requirements are planned.
+type PlanOptions struct {
+	// Command is the target azd command (up, package, provision, deploy).
+	// An empty command plans every requirement of the project, as used by `check`.
+	Command string
+	// Service limits service requirements to a single service (e.g. for prepackage events).
+	Service string
+	// Environment is the azd environment the command targets; the default environment if empty.
+	Environment string
+}
+
+// Planner turns an azure.yaml into a requirement plan. It is shared by `check`,
+// `verify` and the lifecycle event handlers so they all enforce the same requirements.
+type Planner struct {
+	registry *Registry
+	goos     string
+}
+
+func NewPlanner(registry *Registry) *Planner {
+	return NewPlannerWithOS(registry, runtime.GOOS)
+}
+
+// NewPlannerWithOS returns a planner resolving OS-dependent defaults (such as the hook shell) for goos.
+func NewPlannerWithOS(registry *Registry, goos string) *Planner {
+	return &Planner{registry: registry, goos: goos}
+}
+
+// Plan builds the requirement plan. A nil config plans the general health checks.
+func (p *Planner) Plan(config *AzureYaml, opts PlanOptions) *Plan {
+	plan := &Plan{Command: opts.Command}
+
+	if config == nil {
+		for _, c := range p.registry.Applicable(nil) {
+			plan.Requirements = append(plan.Requirements, Requirement{Check: c})
+		}
+		return plan
+	}
+
+	categories := commandCategories(opts.Command)
+	services := make(map[string][]string)
+	needed := make(map[string]bool)
+	projects := newServiceChecks(config, p.goos)
+
+	// Project-wide requirements: azd tooling, infra and project hooks.
+	project := &AzureYaml{
+		Name:             config.Name,
+		Hooks:            config.Hooks.ForCommand(opts.Command),
+		Infra:            config.Infra,
+		RequiredVersions: config.RequiredVersions,
+	}
+	for _, c := range FilterCategory(p.registry.Applicable(project), categories...) {
+		needed[c.ID()] = true
+	}
+
+	// Service requirements: runtimes, container and hosting tools, service hooks.
+	for _, name := range config.ServiceNames() {
+		if opts.Service != "" && name != opts.Service {
+			continue
+		}
+		svc := config.Services[name]
+		svc.Hooks = svc.Hooks.ForCommand(opts.Command)
+		if !includesServiceSteps(opts.Command) {
+			// Only the hooks of a service matter when the command doesn't build it.
+			svc = Service{Hooks: svc.Hooks}
+		}
+		scope := &AzureYaml{Services: map[string]Service{name: svc}}
+		for _, c := range FilterCategory(p.registry.Applicable(scope), categories...) {
+			if c.Category() == CategoryAzd {
+				continue
+			}
+			needed[c.ID()] = true
+			services[c.ID()] = append(services[c.ID()], name)
+		}
+		// Aspire AppHosts need a container runtime whatever their host.
+		if includesServiceSteps(opts.Command) && !svc.NeedsLocalBuild() && projects.needsContainerRuntime(name) {
+			for _, id := range []string{"docker", "docker-daemon"} {
+				needed[id] = true
+				services[id] = append(services[id], name)
+			}
+		}
+	}
+
+	// Tools declared in x-doctor.tools are required by the project.
+	var unknownTools []string
+	for _, id := range toolConstraints(config) {
+		c, ok := p.registry.Get(id)
+		if !ok {
+			unknownTools = append(unknownTools, id)
+			continue
+		}
+		for _, category := range categories {
+			if c.Category() == category {
+				needed[id] = true
+			}
+		}
+	}
+
+	hooks := p.hookRefs(config, opts)
+	for _, c := range p.registry.Checks() {
+		if needed[c.ID()] {
+			req := Requirement{Check: c, Services: services[c.ID()]}
+			if requiredRange, ok := config.Doctor.Tools[c.ID()]; ok {
+				req.Check = &constrainedCheck{
+					Check:         c,
+					requiredRange: requiredRange,
+					location:      config.Location("x-doctor", "tools", c.ID()),
+				}
+			}
+			if c.Category() == CategoryShell {
+				req.Location = hookLocation(config, hooks, shellCheckShells[c.ID()]...)
+			}
+			plan.Requirements = append(plan.Requirements, req)
+		}
+	}
+
+	// The azd version required by the project.
+	if config.RequiredVersions.Azd != "" {
+		plan.Requirements = append(plan.Requirements, Requirement{
+			Check:    &azdVersionCheck{requiredRange: config.RequiredVersions.Azd},
+			Location: config.Location("requiredVersions", "azd"),
+		})
+	}
+
+	for _, tool := range unknownTools {
+		loc := config.Location("x-doctor", "tools", tool)
+		plan.Requirements = append(plan.Requirements, Requirement{
+			Check:    &unknownToolCheck{tool: tool, location: loc},
+			Location: loc,
+		})
+	}
+
+	// Hooks using a shell azd doesn't support.
+	for _, shell := range SortedShells(p.hookShells(config, opts)) {
+		if !IsSupportedShell(shell) {
+			loc := hookLocation(config, hooks, shell)
+			plan.Requirements = append(plan.Requirements, Requirement{
+				Check:    &hookShellCheck{shell: shell, goos: p.goos, location: loc},
+				Location: loc,
+			})
+		}
+	}
+
+	// Services using a host azd doesn't know.
+	if includesServiceSteps(opts.Command) {
+		for _, name := range config.ServiceNames() {
+			svc := config.Services[name]
+			if (opts.Service != "" && name != opts.Service) || svc.Host == "" || IsKnownHost(svc.Host) {
+				continue
+			}
+			loc := config.Location("services", name, "host")
+			plan.Requirements = append(plan.Requirements, Requirement{
+				Check:    &hostCheck{service: name, host: svc.Host, location: loc},
+				Services: []string{name},
+				Location: loc,
+			})
+		}
+
+		// Build requirements read from the services' project files.
+		for _, name := range config.ServiceNames() {
+			if opts.Service != "" && name != opts.Service {
+				continue
+			}
+			loc := config.Location("services", name, "project")
+			for _, c := range projects.checks(name) {
+				plan.Requirements = append(plan.Requirements, Requirement{Check: c, Services: []string{name}, Location: loc})
+			}
+		}
+	}
+
+	// Required extensions share a single `azd extension list` call.
+	if len(config.RequiredVersions.Extensions) > 0 {
+		installed := &installedExtensions{}
+		ids := make([]string, 0, len(config.RequiredVersions.Extensions))
+		for id := range config.RequiredVersions.Extensions {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			plan.Requirements = append(plan.Requirements, Requirement{Check: &extensionCheck{
+				id:            id,
+				requiredRange: config.RequiredVersions.Extensions[id],
+				installed:     installed,
+			}})
+		}
+	}
+
+	// Environment variables referenced by the infra parameters files.
+	if includesProvision(opts.Command) {
+		for _, file := range infraParameterFiles(config) {
+			plan.Requirements = append(plan.Requirements, Requirement{Check: &infraParametersCheck{file: file, environment: opts.Environment}})
+		}
+	}
+
+	// Project-specific checks declared in x-doctor.checks.
+	plan.Requirements = append(plan.Requirements, customChecks(config, opts.Command)...)
+
+	return plan
+}
+
+// hookShells returns the shells used by the hooks that run for the planned command.
+func (p *Planner) hookShells(config *AzureYaml, opts PlanOptions) map[string]bool {
+	scoped := &AzureYaml{Hooks: config.Hooks.ForCommand(opts.Command), Services: map[string]Service{}}
+	for name, svc := range config.Services {
+		if opts.Service != "" && name != opts.Service {
+			continue
+		}
+		scoped.Services[name] = Service{Hooks: svc.Hooks.ForCommand(opts.Command)}
+	}
+	return HookShells(scoped, p.goos)
+}
+
+// shellCheckShells maps the registry shell checks to the hook shells they cover.
+var shellCheckShells = map[string][]string{
+	"bash": {"bash"},
+	"pwsh": {"pwsh", "powershell"},
+}
+
+// hookRef identifies a hook in azure.yaml; service is empty for project hooks.
+type hookRef struct {
+	service string
+	name    string
+	shell   string
+}
+
+// hookRefs returns the hooks that run for the planned command, in a stable order.
+func (p *Planner) hookRefs(config *AzureYaml, opts PlanOptions) []hookRef {
+	var refs []hookRef
+	add := func(service string, hooks Hooks) {
+		names := make([]string, 0, len(hooks))
+		for name := range hooks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			refs = append(refs, hookRef{service: service, name: name, shell: hooks[name].EffectiveShell(p.goos)})
+		}
+	}
+
+	add("", config.Hooks.ForCommand(opts.Command))
+	for _, name := range config.ServiceNames() {
+		if opts.Service == "" || name == opts.Service {
+			add(name, config.Services[name].Hooks.ForCommand(opts.Command))
+		}
+	}
+	return refs
+}
+
+// hookLocation returns the location of the first hook using one of the shells.
+func hookLocation(config *AzureYaml, hooks []hookRef, shells ...string) *Location {
+	for _, ref := range hooks {
+		for _, shell := range shells {
+			if ref.shell == shell {
+				return config.HookLocation(ref.service, ref.name)
+			}
+		}
+	}
+	return nil
+}
+
+// commandCategories returns the check categories relevant for an azd command.
+func commandCategories(command string) []Category {
+	categories := []Category{CategoryAzd, CategoryShell}
+	if includesProvision(command) {
+		categories = append(categories, CategoryInfra)
+	}
+	if includesServiceSteps(command) {
+		categories = append(categories, CategoryLanguage, CategoryContainer, CategoryHosting)
+	}
+	return categories
+}
+
+// includesProvision reports whether the command provisions the infrastructure.
+func includesProvision(command string) bool {
+	return command == "" || command == "up" || command == "provision"
+}
+
+// includesServiceSteps reports whether the command packages or deploys services.
+func includesServiceSteps(command string) bool {
+	return command == "" || command == "up" || command == "package" || command == "deploy"
+}
+
+// ForCommand returns the hooks that run as part of the given azd command
+// (e.g. preprovision and postprovision for provision). An empty command returns all hooks.
+func (h Hooks) ForCommand(command string) Hooks {
+	if command == "" {
+		return h
+	}
+
+	commands := []string{command}
+	if command == "up" {
+		commands = append(commands, "package", "provision", "deploy")
+	}
+
+	result := make(Hooks)
+	for name, hook := range h {
+		for _, cmd := range commands {
+			if name == "pre"+cmd || name == "post"+cmd {
+				result[name] = hook
+			}
+		}
+	}
+	return result
+}
+
+// IsSupportedShell reports whether azd can run hooks with the given shell.
+func IsSupportedShell(shell string) bool {
+	switch shell {
+	case "sh", "bash", "pwsh", "powershell":
+		return true
+	}
+	return false
+}
+
+// hookShellCheck reports hooks configured with a shell azd cannot run.
+type hookShellCheck struct {
+	shell    string
+	goos     string
+	location *Location
+}
+
+func (c *hookShellCheck) ID() string          { return "hook-shell:" + c.shell }
+func (c *hookShellCheck) Category() Category  { return CategoryShell }
+func (c *hookShellCheck) Description() string { return fmt.Sprintf("Hook shell %s", c.shell) }
+
+func (c *hookShellCheck) Applies(config *AzureYaml) bool {
+	return config != nil && HookShells(config, c.goos)[c.shell]
+}
+
+func (c *hookShellCheck) Run(context.Context, Runner) CheckResult {
+	return CheckResult{
+		Name:        "Unknown Shell",
+		Version:     c.shell,
+		Error:       fmt.Errorf("unsupported hook shell %q", c.shell),
+		Severity:    SeverityWarn,
+		Remediation: "Set `shell: sh` or `shell: pwsh` on the hook",
+		Location:    c.location,
+	}
+}
+
+// hostCheck reports services configured with a host azd doesn't know. Hosts can be
+// added by azd extensions, so an unknown host is a warning rather than a failure.
+type hostCheck struct {
+	service  string
+	host     string
+	location *Location
+}
+
+func (c *hostCheck) ID() string          { return "host:" + c.service }
+func (c *hostCheck) Category() Category  { return CategoryHosting }
+func (c *hostCheck) Description() string { return fmt.Sprintf("Service %s host", c.service) }
+
+func (c *hostCheck) Applies(config *AzureYaml) bool {
+	if config == nil {
+		return false
+	}
+	svc, ok := config.Services[c.service]
+	return ok && svc.Host == c.host
+}
+
+func (c *hostCheck) Run(context.Context, Runner) CheckResult {
+	return CheckResult{
+		Name:        "Unknown Host",
+		Version:     c.host,
+		Error:       fmt.Errorf("service %s uses unknown host %q", c.service, c.host),
+		Severity:    SeverityWarn,
+		Remediation: "Use a supported host (appservice, containerapp, function, staticwebapp, aks, springapp) or install the azd extension that provides it",
+		Location:    c.location,
+	}
+}
+
+// azdVersionCheck enforces requiredVersions.azd. It reuses the result of the azd check.
+type azdVersionCheck struct {
+	requiredRange string
+}
+
+func (c *azdVersionCheck) ID() string          { return "azd-version" }
+func (c *azdVersionCheck) Category() Category  { return CategoryAzd }
+func (c *azdVersionCheck) Description() string { return "azd version" }
+func (c *azdVersionCheck) DependsOn() []string { return []string{"azd"} }
+
+func (c *azdVersionCheck) Applies(config *AzureYaml) bool {
+	return config != nil && config.RequiredVersions.Azd != ""
+}
+
+func (c *azdVersionCheck) Run(ctx context.Context, r Runner) CheckResult {
+	azd, ok := DependencyResult(ctx, "azd")
+	if !ok {
+		azd = CheckAzdVersion(r)
+	}
+	return CheckAzdRequiredVersion(azd, c.requiredRange)
+}
+
+// installedExtensions lazily lists the installed azd extensions once per plan.
+type installedExtensions struct {
+	once       sync.Once
+	extensions []AzdExtension
+	err        error
+}
+
+func (l *installedExtensions) get(r Runner) ([]AzdExtension, error) {
+	l.once.Do(func() {
+		l.extensions, l.err = GetInstalledExtensions(r)
+	})
+	return l.extensions, l.err
+}
+
+// extensionCheck verifies an extension listed in requiredVersions.extensions.
+type extensionCheck struct {
+	id            string
+	requiredRange string
+	installed     *installedExtensions
+}
+
+func (c *extensionCheck) ID() string          { return "extension:" + c.id }
+func (c *extensionCheck) Category() Category  { return CategoryExtension }
+func (c *extensionCheck) Description() string { return "extension " + c.id }
+
+func (c *extensionCheck) Applies(config *AzureYaml) bool {
+	if config == nil {
+		return false
+	}
+	_, ok := config.RequiredVersions.Extensions[c.id]
+	return ok
+}
+
+func (c *extensionCheck) Run(_ context.Context, r Runner) CheckResult {
+	extensions, err := c.installed.get(r)
+	if err != nil {
+		return CheckResult{
+			Name:        c.Description(),
+			Error:       fmt.Errorf("failed to list installed extensions: %w", err),
+			Severity:    SeverityFail,
+			Remediation: "Run `azd extension list --installed` to diagnose",
+		}
+	}
+	return CheckExtension(extensions, c.id, c.requiredRange)
+}
+
+// String renders the requirement for debug logs.
+func (r Requirement) String() string {
+	if len(r.Services) == 0 {
+		return r.Check.ID()
+	}
+	return fmt.Sprintf("%s (%s)", r.Check.ID(), strings.Join(r.Services, ", "))
+}
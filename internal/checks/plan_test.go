@@ -0,0 +1,247 @@
+package checks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requirementStrings(plan *Plan) []string {
+	list := make([]string, 0, len(plan.Requirements))
+	for _, req := range plan.Requirements {
+		list = append(list, req.String())
+	}
+	return list
+}
+
+func TestPlannerPlan(t *testing.T) {
+	config := &AzureYaml{
+		Name:  "sample",
+		Infra: Infra{Provider: "terraform"},
+		Hooks: Hooks{
+			"preprovision": {Shell: "bash", Run: "./setup.sh"},
+			"postdeploy":   {Shell: "pwsh", Run: "./post.ps1"},
+		},
+		Services: map[string]Service{
+			"api": {Language: "python", Host: "containerapp"},
+			"web": {Language: "ts", Host: "containerapp", Hooks: Hooks{
+				"prepackage": {Shell: "zsh", Run: "./build.sh"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		config   *AzureYaml
+		opts     PlanOptions
+		expected []string
+	}{
+		{
+			name:     "No project plans generic checks",
+			config:   nil,
+			expected: []string{"azd", "git", "gh", "docker", "docker-daemon", "node", "python", "dotnet", "bash", "pwsh", "func"},
+		},
+		{
+			name:   "Check plans every requirement",
+			config: config,
+			expected: []string{"azd", "git", "gh", "docker (api, web)", "docker-daemon (api, web)",
+				"node (web)", "python (api)", "bash", "pwsh", "terraform", "hook-shell:zsh"},
+		},
+		{
+			name:     "Provision only needs infra and provision hooks",
+			config:   config,
+			opts:     PlanOptions{Command: "provision"},
+			expected: []string{"azd", "git", "gh", "bash", "terraform"},
+		},
+		{
+			name:   "Deploy skips infra",
+			config: config,
+			opts:   PlanOptions{Command: "deploy"},
+			expected: []string{"azd", "git", "gh", "docker (api, web)", "docker-daemon (api, web)",
+				"node (web)", "python (api)", "pwsh"},
+		},
+		{
+			name:   "Package for a single service",
+			config: config,
+			opts:   PlanOptions{Command: "package", Service: "web"},
+			expected: []string{"azd", "git", "gh", "docker (web)", "docker-daemon (web)",
+				"node (web)", "hook-shell:zsh"},
+		},
+		{
+			name: "Required extensions",
+			config: &AzureYaml{RequiredVersions: RequiredVersions{Extensions: map[string]string{
+				"microsoft.azd.extensions": ">= 0.5.0",
+				"azure.ai.agents":          "",
+			}}},
+			opts:     PlanOptions{Command: "up"},
+			expected: []string{"azd", "git", "gh", "extension:azure.ai.agents", "extension:microsoft.azd.extensions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := NewPlannerWithOS(DefaultRegistryWithOS("linux"), "linux")
+			plan := planner.Plan(tt.config, tt.opts)
+			assert.Equal(t, tt.opts.Command, plan.Command)
+			assert.Equal(t, tt.expected, requirementStrings(plan))
+		})
+	}
+}
+
+func TestHooksForCommand(t *testing.T) {
+	hooks := Hooks{
+		"preprovision": {Run: "a"},
+		"postdeploy":   {Run: "b"},
+		"prepackage":   {Run: "c"},
+		"preup":        {Run: "d"},
+	}
+
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{command: "", expected: []string{"postdeploy", "prepackage", "preprovision", "preup"}},
+		{command: "provision", expected: []string{"preprovision"}},
+		{command: "deploy", expected: []string{"postdeploy"}},
+		{command: "up", expected: []string{"postdeploy", "prepackage", "preprovision", "preup"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			var names []string
+			for name := range hooks.ForCommand(tt.command) {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
+
+func TestPlanExtensionChecks(t *testing.T) {
+	calls := 0
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			calls++
+			return []byte(`[{"id":"a.ext","name":"a","version":"0.4.0"},{"id":"b.ext","name":"b","version":"1.0.0"}]`), nil
+		},
+	}
+	config := &AzureYaml{RequiredVersions: RequiredVersions{Extensions: map[string]string{
+		"a.ext": ">= 0.5.0",
+		"b.ext": ">= 1.0.0",
+	}}}
+
+	plan := NewPlannerWithOS(NewRegistry(), "linux").Plan(config, PlanOptions{})
+	results := NewExecutor(mock, 1).Run(context.Background(), plan.Checks())
+
+	assert.Equal(t, SeverityFail, results[0].Status(), "outdated extension fails")
+	assert.Contains(t, results[0].Remediation, "azd extension upgrade a.ext")
+	assert.Equal(t, SeverityPass, results[1].Status())
+	assert.Equal(t, 1, calls, "installed extensions are listed once per plan")
+}
+
+func TestHookShellCheck(t *testing.T) {
+	config := &AzureYaml{Hooks: Hooks{"preprovision": {Shell: "fish", Run: "./setup.fish"}}}
+
+	plan := NewPlannerWithOS(NewRegistry(), "linux").Plan(config, PlanOptions{Command: "provision"})
+	req, ok := plan.Find("hook-shell:fish")
+	assert.True(t, ok)
+	assert.Equal(t, CategoryShell, req.Check.Category())
+
+	res := req.Check.Run(context.Background(), &MockRunner{})
+	assert.Equal(t, SeverityWarn, res.Status())
+	assert.NotEmpty(t, res.Remediation)
+
+	_, ok = NewPlannerWithOS(NewRegistry(), "linux").Plan(config, PlanOptions{Command: "deploy"}).Find("hook-shell:fish")
+	assert.False(t, ok, "hooks of other commands are ignored")
+}
+
+func TestPlanUnknownHost(t *testing.T) {
+	config := &AzureYaml{Services: map[string]Service{
+		"api": {Language: "js", Host: "containerapps"},
+		"web": {Language: "js", Host: "staticwebapp"},
+	}}
+
+	planner := NewPlannerWithOS(NewRegistry(), "linux")
+	req, ok := planner.Plan(config, PlanOptions{Command: "deploy"}).Find("host:api")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"api"}, req.Services)
+
+	res := req.Check.Run(context.Background(), &MockRunner{})
+	assert.Equal(t, SeverityWarn, res.Status())
+	assert.Contains(t, res.Error.Error(), "containerapps")
+
+	_, ok = planner.Plan(config, PlanOptions{Command: "deploy"}).Find("host:web")
+	assert.False(t, ok, "known hosts are not reported")
+	_, ok = planner.Plan(config, PlanOptions{Command: "provision"}).Find("host:api")
+	assert.False(t, ok, "hosts are only checked when services are packaged or deployed")
+}
+
+func TestPlanLocations(t *testing.T) {
+	content := `name: test-project
+hooks:
+  preprovision:
+    shell: bash
+    run: ./setup.sh
+services:
+  api:
+    host: containerapps
+    hooks:
+      prepackage:
+        shell: fish
+        run: ./build.fish
+`
+	path := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	config, err := LoadProjectConfig(path)
+	require.NoError(t, err)
+
+	plan := NewPlannerWithOS(DefaultRegistryWithOS("linux"), "linux").Plan(config, PlanOptions{})
+
+	tests := []struct {
+		id   string
+		line int
+	}{
+		{id: "bash", line: 4},
+		{id: "hook-shell:fish", line: 11},
+		{id: "host:api", line: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			req, ok := plan.Find(tt.id)
+			require.True(t, ok)
+			require.NotNil(t, req.Location)
+			assert.Equal(t, path, req.Location.File)
+			assert.Equal(t, tt.line, req.Location.Line)
+		})
+	}
+
+	res := NewExecutor(&MockRunner{}, 1).Run(context.Background(), []Check{mustFind(t, plan, "hook-shell:fish").Check})
+	require.NotNil(t, res[0].Location, "plan-generated checks carry their location in the result")
+	assert.Equal(t, 11, res[0].Location.Line)
+}
+
+func mustFind(t *testing.T, plan *Plan, id string) Requirement {
+	req, ok := plan.Find(id)
+	require.True(t, ok, id)
+	return req
+}
+
+func TestPlanRequiredAzdVersion(t *testing.T) {
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("azd version 1.9.0 (commit 1234567)"), nil
+		},
+	}
+	config := &AzureYaml{RequiredVersions: RequiredVersions{Azd: ">= 1.10.0"}}
+
+	plan := NewPlannerWithOS(DefaultRegistryWithOS("linux"), "linux").Plan(config, PlanOptions{Command: "provision"})
+	assert.Equal(t, []string{"azd", "git", "gh", "azd-version"}, requirementStrings(plan))
+
+	results := NewExecutor(mock, 2).Run(context.Background(), plan.Checks())
+	assert.Equal(t, SeverityFail, results[3].Status())
+	assert.Contains(t, results[3].Error.Error(), "azd 1.9.0 does not satisfy requiredVersions.azd >= 1.10.0")
+}
@@ -3,6 +3,8 @@ package checks
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,6 +15,12 @@ type AzureYaml struct {
 	Hooks            Hooks              `yaml:"hooks"`
 	Infra            Infra              `yaml:"infra"`
 	RequiredVersions RequiredVersions   `yaml:"requiredVersions"`
+	// Doctor holds the doctor-specific settings of the `x-doctor` extension section.
+	Doctor DoctorConfig `yaml:"x-doctor"`
+
+	// file and root record where the config was loaded from, for Location.
+	file string
+	root *yaml.Node
 }
 
 type RequiredVersions struct {
@@ -20,8 +28,41 @@ type RequiredVersions struct {
 	Extensions map[string]string `yaml:"extensions"`
 }
 
+// DoctorConfig is the `x-doctor` section of azure.yaml.
+type DoctorConfig struct {
+	// Tools maps check IDs (e.g. node, dotnet, terraform) to the version range the project requires.
+	Tools map[string]string `yaml:"tools"`
+	// Checks are project-specific checks run alongside the built-in checks.
+	Checks []CustomCheck `yaml:"checks"`
+}
+
+// CustomCheck is a project-specific check declared under `x-doctor.checks`. The command
+// passes when it exits with ExitCode and, if set, its output matches the Output regex.
+type CustomCheck struct {
+	// ID identifies the check in reports as `custom:<id>`; defaults to the command. Checks
+	// sharing an ID get a numeric suffix (az, az-2).
+	ID      string   `yaml:"id"`
+	Name    string   `yaml:"name"`
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	// ExitCode is the expected exit code (default 0).
+	ExitCode int `yaml:"exitCode"`
+	// Output is a regular expression the command output must match.
+	Output string `yaml:"output"`
+	// Severity is the severity of a failed check: fail (default) or warn.
+	Severity Severity `yaml:"severity"`
+	// Commands limits the check to the given azd commands (e.g. provision, deploy).
+	// An empty list applies the check to every command.
+	Commands    []string `yaml:"commands"`
+	Remediation string   `yaml:"remediation"`
+}
+
 type Infra struct {
 	Provider string `yaml:"provider"`
+	// Path is the infra directory, relative to azure.yaml (default infra).
+	Path string `yaml:"path"`
+	// Module is the name of the main module (default main).
+	Module string `yaml:"module"`
 }
 
 type Service struct {
@@ -33,6 +74,34 @@ type Service struct {
 	Docker   DockerConfig `yaml:"docker"`
 }
 
+// knownHosts are the service hosts supported by azd.
+var knownHosts = map[string]bool{
+	"appservice":     true,
+	"containerapp":   true,
+	"function":       true,
+	"staticwebapp":   true,
+	"aks":            true,
+	"springapp":      true,
+	"ai.endpoint":    true,
+	"azure.ai.agent": true,
+}
+
+// IsKnownHost reports whether azd supports the given service host.
+func IsKnownHost(host string) bool {
+	return knownHosts[host]
+}
+
+// IsContainerHost reports whether the service is hosted in a container platform.
+func (s Service) IsContainerHost() bool {
+	return s.Host == "containerapp" || s.Host == "aks"
+}
+
+// NeedsLocalBuild reports whether the service image is built with a local container runtime.
+// Services with a pre-built image or remote build enabled don't need one.
+func (s Service) NeedsLocalBuild() bool {
+	return s.IsContainerHost() && !s.Docker.Remote && s.Image == ""
+}
+
 type DockerConfig struct {
 	Remote bool `yaml:"remoteBuild"`
 }
@@ -44,6 +113,18 @@ type HookConfig struct {
 	Run   string `yaml:"run"`
 }
 
+// EffectiveShell returns the shell used to run the hook, falling back to the OS default
+// (pwsh on Windows, sh elsewhere) when none is configured.
+func (h HookConfig) EffectiveShell(goos string) string {
+	if h.Shell != "" {
+		return h.Shell
+	}
+	if goos == "windows" {
+		return "pwsh"
+	}
+	return "sh"
+}
+
 // UnmarshalYAML implements custom unmarshaling for HookConfig to handle both string and object formats
 func (h *HookConfig) UnmarshalYAML(value *yaml.Node) error {
 	// Case 1: hook is just a string (the command to run)
@@ -63,10 +144,73 @@ func LoadProjectConfig(path string) (*AzureYaml, error) {
 		return nil, err
 	}
 
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse azure.yaml: %w", err)
+	}
+
 	var config AzureYaml
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if err := root.Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to parse azure.yaml: %w", err)
 	}
+	config.file = path
+	config.root = &root
 
 	return &config, nil
 }
+
+// ServiceNames returns the service names in sorted order so that iteration is deterministic.
+func (c *AzureYaml) ServiceNames() []string {
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServiceDir returns the project directory of a service, resolved against the directory of
+// azure.yaml. It returns "" if the config was not loaded from a file.
+func (c *AzureYaml) ServiceDir(name string) string {
+	if c == nil || c.file == "" {
+		return ""
+	}
+	project := c.Services[name].Project
+	if filepath.IsAbs(project) {
+		return project
+	}
+	return filepath.Join(filepath.Dir(c.file), project)
+}
+
+// HasLanguage reports whether any service uses one of the given languages.
+func (c *AzureYaml) HasLanguage(languages ...string) bool {
+	for _, svc := range c.Services {
+		for _, lang := range languages {
+			if svc.Language == lang {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasHost reports whether any service uses the given host.
+func (c *AzureYaml) HasHost(host string) bool {
+	for _, svc := range c.Services {
+		if svc.Host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalBuildServices returns the sorted names of services that need a local container runtime.
+func (c *AzureYaml) LocalBuildServices() []string {
+	var names []string
+	for _, name := range c.ServiceNames() {
+		if c.Services[name].NeedsLocalBuild() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
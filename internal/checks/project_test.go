@@ -2,6 +2,7 @@ package checks
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -104,3 +105,70 @@ services:
 		assert.Error(t, err)
 	})
 }
+
+func TestLocalBuildServices(t *testing.T) {
+	config := &AzureYaml{Services: map[string]Service{
+		"web":    {Host: "containerapp"},
+		"api":    {Host: "aks"},
+		"remote": {Host: "containerapp", Docker: DockerConfig{Remote: true}},
+		"image":  {Host: "containerapp", Image: "nginx:latest"},
+		"fn":     {Host: "function"},
+	}}
+
+	assert.Equal(t, []string{"api", "web"}, config.LocalBuildServices())
+	assert.Equal(t, []string{"api", "fn", "image", "remote", "web"}, config.ServiceNames())
+	assert.True(t, config.HasHost("function"))
+	assert.False(t, config.HasHost("staticwebapp"))
+}
+
+func TestAzureYamlLocation(t *testing.T) {
+	content := `name: test-project
+hooks:
+  preprovision:
+    shell: bash
+    run: ./setup.sh
+  postdeploy: echo done
+services:
+  api:
+    language: js
+    host: containerapps
+`
+	path := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	config, err := LoadProjectConfig(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		location *Location
+		line     int
+		column   int
+	}{
+		{name: "Service host", location: config.Location("services", "api", "host"), line: 10, column: 5},
+		{name: "Hook shell", location: config.HookLocation("", "preprovision"), line: 4, column: 5},
+		{name: "Hook without shell", location: config.HookLocation("", "postdeploy"), line: 6, column: 3},
+		{name: "Document", location: config.Location(), line: 1, column: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NotNil(t, tt.location)
+			assert.Equal(t, path, tt.location.File)
+			assert.Equal(t, tt.line, tt.location.Line)
+			assert.Equal(t, tt.column, tt.location.Column)
+		})
+	}
+
+	assert.Nil(t, config.Location("services", "web"), "missing keys have no location")
+	assert.Nil(t, config.Location("name", "nested"), "scalars have no children")
+	assert.Nil(t, (&AzureYaml{}).Location("services"), "configs not loaded from a file have no location")
+}
+
+func TestLoadProjectConfigEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+
+	config, err := LoadProjectConfig(path)
+	require.NoError(t, err)
+	assert.Empty(t, config.Name)
+}
@@ -0,0 +1,391 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Python package managers.
+const (
+	PackageManagerPip    = "pip"
+	PackageManagerUv     = "uv"
+	PackageManagerPoetry = "poetry"
+)
+
+// PythonProject is the environment configuration of a Python service project.
+type PythonProject struct {
+	// Requirements is the path of requirements.txt, if any.
+	Requirements string
+	// PyProject is the path of pyproject.toml, if any.
+	PyProject string
+	// RequiresPython is the requires-python specifier of pyproject.toml, if any.
+	RequiresPython     string
+	RequiresPythonLine int
+	// VersionFile is the .python-version or runtime.txt file pinning the Python version, if any.
+	VersionFile string
+	// PinnedVersion is the version in VersionFile, e.g. "3.11" or "3.11.4".
+	PinnedVersion string
+	// PackageManager is pip, uv or poetry, from the lockfile or the pyproject.toml tool section.
+	PackageManager string
+	// Lockfile is the path of uv.lock or poetry.lock, if any.
+	Lockfile string
+}
+
+var (
+	requiresPythonPattern = regexp.MustCompile(`(?m)^\s*requires-python\s*=\s*["']([^"']+)["']`)
+	toolSectionPattern    = regexp.MustCompile(`(?m)^\s*\[tool\.(uv|poetry)[\].]`)
+	// pythonVersionFiles are the files pinning the Python version, with the pattern of the version.
+	pythonVersionFiles = []struct {
+		name    string
+		pattern *regexp.Regexp
+	}{
+		{".python-version", regexp.MustCompile(`^(\d+(?:\.\d+){0,2})$`)},
+		{"runtime.txt", regexp.MustCompile(`^python-(\d+(?:\.\d+){0,2})$`)},
+	}
+)
+
+// LoadPythonProject reads requirements.txt, pyproject.toml and the version files of the
+// Python project in dir.
+func LoadPythonProject(dir string) (PythonProject, error) {
+	project := PythonProject{PackageManager: PackageManagerPip}
+	if path := filepath.Join(dir, "requirements.txt"); fileExists(path) {
+		project.Requirements = path
+	}
+
+	path := filepath.Join(dir, "pyproject.toml")
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		project.PyProject = path
+		if m := requiresPythonPattern.FindSubmatchIndex(content); m != nil {
+			project.RequiresPython = strings.TrimSpace(string(content[m[2]:m[3]]))
+			project.RequiresPythonLine = 1 + strings.Count(string(content[:m[2]]), "\n")
+		}
+		if m := toolSectionPattern.FindSubmatch(content); m != nil {
+			project.PackageManager = string(m[1])
+		}
+	case !os.IsNotExist(err):
+		return PythonProject{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	// A lockfile is the strongest sign of the package manager in use.
+	for _, lock := range []struct{ file, manager string }{
+		{"uv.lock", PackageManagerUv},
+		{"poetry.lock", PackageManagerPoetry},
+	} {
+		if path := filepath.Join(dir, lock.file); fileExists(path) {
+			project.Lockfile, project.PackageManager = path, lock.manager
+			break
+		}
+	}
+
+	for _, file := range pythonVersionFiles {
+		path := filepath.Join(dir, file.name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// pyenv allows several versions, one per line; the first one is the default.
+		line, _, _ := strings.Cut(strings.TrimSpace(string(content)), "\n")
+		if m := file.pattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			project.VersionFile, project.PinnedVersion = path, m[1]
+			break
+		}
+	}
+	return project, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+var pep440Clause = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)?\s*v?([0-9][0-9.]*)(\.\*)?$`)
+
+// PythonRange converts a PEP 440 version specifier such as ">=3.9,<3.13" or "~=3.10"
+// (as used by requires-python) to the range syntax understood by SatisfiesRange.
+func PythonRange(specifier string) (string, error) {
+	var comparators []string
+	for _, clause := range strings.Split(specifier, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		m := pep440Clause.FindStringSubmatch(clause)
+		if m == nil {
+			return "", fmt.Errorf("unsupported version specifier %q", clause)
+		}
+		op, wildcard := m[1], m[3] != ""
+		var parts []int
+		for _, part := range strings.Split(strings.TrimSuffix(m[2], "."), ".") {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return "", fmt.Errorf("unsupported version specifier %q", clause)
+			}
+			parts = append(parts, n)
+		}
+		if len(parts) > 3 {
+			parts = parts[:3]
+		}
+		version := fullVersion(parts)
+
+		switch {
+		case wildcard && (op == "==" || op == ""):
+			comparators = append(comparators, ">="+version, "<"+nextVersion(parts))
+		case wildcard:
+			return "", fmt.Errorf("unsupported version specifier %q", clause)
+		case op == "~=":
+			if len(parts) < 2 {
+				return "", fmt.Errorf("invalid compatible release %q", clause)
+			}
+			comparators = append(comparators, ">="+version, "<"+nextVersion(parts[:len(parts)-1]))
+		case op == "==" || op == "===" || op == "":
+			comparators = append(comparators, "="+version)
+		default:
+			comparators = append(comparators, op+version)
+		}
+	}
+	if len(comparators) == 0 {
+		return ">=0.0.0", nil
+	}
+	return strings.Join(comparators, " "), nil
+}
+
+// pythonVersionCheck verifies the interpreter version against requires-python and the
+// version pinned by .python-version or runtime.txt. It reuses the result of the python check.
+type pythonVersionCheck struct {
+	service string
+	dir     string
+	project PythonProject
+	goos    string
+}
+
+func (c *pythonVersionCheck) ID() string         { return "python-version:" + c.service }
+func (c *pythonVersionCheck) Category() Category { return CategoryLanguage }
+func (c *pythonVersionCheck) Description() string {
+	return fmt.Sprintf("Service %s Python version", c.service)
+}
+func (c *pythonVersionCheck) DependsOn() []string { return []string{"python"} }
+
+func (c *pythonVersionCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isPythonLanguage(config.Services[c.service].Language)
+}
+
+func (c *pythonVersionCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := CheckResult{Name: "Python Version"}
+	python := pythonResult(ctx, r, c.goos)
+	if !python.Installed {
+		res.Severity = SeveritySkip
+		res.Error = fmt.Errorf("python is not installed")
+		return res
+	}
+	res.Installed = true
+	// pyenv and asdf shims select the interpreter from the service's .python-version.
+	res.Version = serviceVersion(ctx, r, c.dir, python.Name, python)
+	installed, err := ParseVersion(res.Version)
+	if err != nil {
+		res.Error = fmt.Errorf("could not determine the python version")
+		res.Severity = SeverityWarn
+		return res
+	}
+
+	if c.project.RequiresPython != "" {
+		location := &Location{File: c.project.PyProject, Line: c.project.RequiresPythonLine}
+		requiredRange, err := PythonRange(c.project.RequiresPython)
+		var ok bool
+		if err == nil {
+			ok, err = SatisfiesRange(installed, requiredRange)
+		}
+		if err != nil {
+			res.Error = fmt.Errorf("unsupported requires-python %q", c.project.RequiresPython)
+			res.Severity = SeverityWarn
+			res.Location = location
+			return res
+		}
+		if !ok {
+			res.Error = fmt.Errorf("python %s does not satisfy requires-python %q of service %s", installed, c.project.RequiresPython, c.service)
+			res.Severity = SeverityFail
+			res.Remediation = fmt.Sprintf("Install a Python version matching %s: https://www.python.org/downloads", c.project.RequiresPython)
+			res.Location = location
+			return res
+		}
+	}
+
+	if c.project.PinnedVersion != "" && !matchesPinnedVersion([]uint64{installed.Major, installed.Minor, installed.Patch}, c.project.PinnedVersion) {
+		res.Error = fmt.Errorf("python %s does not match %s in %s", installed, c.project.PinnedVersion, filepath.Base(c.project.VersionFile))
+		res.Severity = SeverityWarn
+		res.Remediation = fmt.Sprintf("Switch to Python %s (e.g. `pyenv install %s`)", c.project.PinnedVersion, c.project.PinnedVersion)
+		res.Location = &Location{File: c.project.VersionFile, Line: 1}
+		return res
+	}
+
+	res.Severity = SeverityPass
+	return res
+}
+
+// pythonResult returns the result of the python check, running it when the check is
+// used outside an Executor.
+func pythonResult(ctx context.Context, r Runner, goos string) CheckResult {
+	if res, ok := DependencyResult(ctx, "python"); ok {
+		return res
+	}
+	return CheckPythonWithOS(r, goos)
+}
+
+// pythonPackageManagerCheck verifies that the package manager of a service is installed:
+// pip for the interpreter, or uv or poetry when the project uses them.
+type pythonPackageManagerCheck struct {
+	service string
+	dir     string
+	project PythonProject
+	goos    string
+}
+
+func (c *pythonPackageManagerCheck) ID() string         { return "python-package-manager:" + c.service }
+func (c *pythonPackageManagerCheck) Category() Category { return CategoryLanguage }
+func (c *pythonPackageManagerCheck) Description() string {
+	return fmt.Sprintf("Service %s package manager", c.service)
+}
+
+// DependsOn requires the interpreter for pip only; uv and poetry are standalone tools.
+func (c *pythonPackageManagerCheck) DependsOn() []string {
+	if c.project.PackageManager == PackageManagerPip {
+		return []string{"python"}
+	}
+	return nil
+}
+
+func (c *pythonPackageManagerCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isPythonLanguage(config.Services[c.service].Language)
+}
+
+func (c *pythonPackageManagerCheck) Run(ctx context.Context, r Runner) CheckResult {
+	pm := c.project.PackageManager
+	name, args := pm, []string{"--version"}
+	var remediation string
+	switch pm {
+	case PackageManagerUv:
+		remediation = "Install uv: https://docs.astral.sh/uv/getting-started/installation"
+	case PackageManagerPoetry:
+		remediation = "Install Poetry: https://python-poetry.org/docs/#installation"
+	default:
+		python := pythonResult(ctx, r, c.goos)
+		if !python.Installed {
+			return CheckResult{Name: "Package Manager", Version: pm, Severity: SeveritySkip, Error: fmt.Errorf("python is not installed")}
+		}
+		// azd installs requirements with the pip of the interpreter.
+		name, args = python.Name, []string{"-m", "pip", "--version"}
+		remediation = fmt.Sprintf("Install pip with `%s -m ensurepip --upgrade`", python.Name)
+	}
+
+	out, err := runnerOutputInDir(ctx, r, c.dir, name, args...)
+	if err != nil {
+		return CheckResult{Name: "Package Manager", Version: pm, Error: fmt.Errorf("%s is not installed", pm),
+			Severity: SeverityFail, Remediation: remediation}
+	}
+	return CheckResult{Name: "Package Manager", Installed: true, Version: pm + " " + cleanVersion(pm, string(out)),
+		Severity: SeverityPass, Path: runnerLookPath(r, name)}
+}
+
+// pythonVenvNames are the directory names of the virtual environments looked up in a
+// service project, besides the <service dir>_env environment azd packages with.
+var pythonVenvNames = []string{".venv", "venv", "env"}
+
+// pythonVenvCheck detects the virtual environment of a service and whether it is active.
+type pythonVenvCheck struct {
+	service string
+	dir     string
+	// lookupEnv reads VIRTUAL_ENV; os.LookupEnv if nil.
+	lookupEnv func(string) (string, bool)
+}
+
+func (c *pythonVenvCheck) ID() string         { return "python-venv:" + c.service }
+func (c *pythonVenvCheck) Category() Category { return CategoryLanguage }
+func (c *pythonVenvCheck) Description() string {
+	return fmt.Sprintf("Service %s virtual environment", c.service)
+}
+
+func (c *pythonVenvCheck) Applies(config *AzureYaml) bool {
+	return config != nil && isPythonLanguage(config.Services[c.service].Language)
+}
+
+func (c *pythonVenvCheck) Run(context.Context, Runner) CheckResult {
+	lookupEnv := c.lookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	active, _ := lookupEnv("VIRTUAL_ENV")
+
+	res := CheckResult{Name: "Virtual Environment", Installed: true}
+	for _, name := range append(pythonVenvNames, filepath.Base(c.dir)+"_env") {
+		venv := filepath.Join(c.dir, name)
+		if !fileExists(filepath.Join(venv, "pyvenv.cfg")) {
+			continue
+		}
+		res.Path = venv
+		res.Severity = SeverityPass
+		switch {
+		case active == "":
+			res.Version = name + " (not active)"
+		case sameFile(active, venv):
+			res.Version = name + " (active)"
+		default:
+			res.Version = name
+			res.Error = fmt.Errorf("the active virtual environment %s is not the service's %s", active, name)
+			res.Severity = SeverityWarn
+			res.Remediation = fmt.Sprintf("Activate %s, or run `deactivate`", venv)
+		}
+		return res
+	}
+
+	if active != "" {
+		res.Version = active + " (active)"
+		res.Path = active
+		res.Severity = SeverityPass
+		return res
+	}
+	// azd creates its own environment when it packages the service.
+	res.Severity = SeverityInfo
+	res.Version = "none found, azd creates one when packaging"
+	res.Remediation = fmt.Sprintf("Create one with `python -m venv .venv` in %s to run the service locally", c.dir)
+	return res
+}
+
+// sameFile reports whether two paths refer to the same file or directory.
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+func isPythonLanguage(language string) bool {
+	return language == "py" || language == "python"
+}
+
+// pythonServiceChecks returns the environment requirements of a Python service.
+func pythonServiceChecks(config *AzureYaml, name, goos string) []Check {
+	dir := config.ServiceDir(name)
+	project, err := LoadPythonProject(dir)
+	if err != nil {
+		return []Check{&projectFileCheck{service: name, err: err}}
+	}
+	var list []Check
+	if project.RequiresPython != "" || project.PinnedVersion != "" {
+		list = append(list, &pythonVersionCheck{service: name, dir: dir, project: project, goos: goos})
+	}
+	if project.Requirements == "" && project.PyProject == "" {
+		// Nothing to install; azd reports the missing requirements itself.
+		return list
+	}
+	return append(list,
+		&pythonPackageManagerCheck{service: name, dir: dir, project: project, goos: goos},
+		&pythonVenvCheck{service: name, dir: dir},
+	)
+}
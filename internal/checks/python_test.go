@@ -0,0 +1,311 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPythonRange(t *testing.T) {
+	tests := []struct {
+		specifier string
+		expected  string
+		matches   []string
+		rejects   []string
+	}{
+		{specifier: ">=3.9", expected: ">=3.9.0", matches: []string{"3.12.1"}, rejects: []string{"3.8.18"}},
+		{specifier: ">=3.9, <3.13", expected: ">=3.9.0 <3.13.0", matches: []string{"3.12.1"}, rejects: []string{"3.13.0"}},
+		{specifier: "~=3.10", expected: ">=3.10.0 <4.0.0", matches: []string{"3.12.1"}, rejects: []string{"3.9.0"}},
+		{specifier: "~=3.11.2", expected: ">=3.11.2 <3.12.0", matches: []string{"3.11.4"}, rejects: []string{"3.12.0"}},
+		{specifier: "==3.11.*", expected: ">=3.11.0 <3.12.0", matches: []string{"3.11.9"}, rejects: []string{"3.10.0"}},
+		{specifier: ">=3.8,!=3.9.0", expected: ">=3.8.0 !=3.9.0", matches: []string{"3.9.1"}, rejects: []string{"3.9.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.specifier, func(t *testing.T) {
+			converted, err := PythonRange(tt.specifier)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, converted)
+			for _, v := range tt.matches {
+				ok, err := SatisfiesRange(mustParseVersion(t, v), converted)
+				require.NoError(t, err)
+				assert.True(t, ok, v)
+			}
+			for _, v := range tt.rejects {
+				ok, err := SatisfiesRange(mustParseVersion(t, v), converted)
+				require.NoError(t, err)
+				assert.False(t, ok, v)
+			}
+		})
+	}
+
+	_, err := PythonRange(">=3.9; python_version")
+	assert.Error(t, err)
+}
+
+func TestLoadPythonProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected PythonProject
+	}{
+		{
+			name: "requirements.txt and runtime.txt",
+			files: map[string]string{
+				"requirements.txt": "flask==3.0.0\n",
+				"runtime.txt":      "python-3.11.4\n",
+			},
+			expected: PythonProject{Requirements: "requirements.txt", VersionFile: "runtime.txt", PinnedVersion: "3.11.4", PackageManager: PackageManagerPip},
+		},
+		{
+			name: "uv project",
+			files: map[string]string{
+				"pyproject.toml":  "[project]\nname = \"api\"\nrequires-python = \">=3.10\"\n\n[tool.uv]\ndev-dependencies = []\n",
+				"uv.lock":         "version = 1\n",
+				".python-version": "3.12\n3.11\n",
+			},
+			expected: PythonProject{
+				PyProject:          "pyproject.toml",
+				RequiresPython:     ">=3.10",
+				RequiresPythonLine: 3,
+				VersionFile:        ".python-version",
+				PinnedVersion:      "3.12",
+				PackageManager:     PackageManagerUv,
+				Lockfile:           "uv.lock",
+			},
+		},
+		{
+			name: "Poetry without lockfile",
+			files: map[string]string{
+				"pyproject.toml": "[tool.poetry]\nname = \"api\"\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\n",
+			},
+			expected: PythonProject{PyProject: "pyproject.toml", PackageManager: PackageManagerPoetry},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			for _, path := range []*string{&tt.expected.Requirements, &tt.expected.PyProject, &tt.expected.VersionFile, &tt.expected.Lockfile} {
+				if *path != "" {
+					*path = filepath.Join(dir, *path)
+				}
+			}
+
+			project, err := LoadPythonProject(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, project)
+		})
+	}
+}
+
+func TestPythonVersionCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		project PythonProject
+		python  CheckResult
+		status  Severity
+		errMsg  string
+	}{
+		{
+			name:    "Satisfies requires-python",
+			project: PythonProject{PyProject: "pyproject.toml", RequiresPython: ">=3.10", RequiresPythonLine: 3},
+			python:  CheckResult{Name: "python3", Installed: true, Version: "3.12.1"},
+			status:  SeverityPass,
+		},
+		{
+			name:    "Outside requires-python",
+			project: PythonProject{PyProject: "pyproject.toml", RequiresPython: ">=3.10", RequiresPythonLine: 3},
+			python:  CheckResult{Name: "python3", Installed: true, Version: "3.9.18"},
+			status:  SeverityFail,
+			errMsg:  `python 3.9.18 does not satisfy requires-python ">=3.10" of service api`,
+		},
+		{
+			name:    "Differs from runtime.txt",
+			project: PythonProject{VersionFile: "runtime.txt", PinnedVersion: "3.11"},
+			python:  CheckResult{Name: "python3", Installed: true, Version: "3.12.1"},
+			status:  SeverityWarn,
+			errMsg:  "python 3.12.1 does not match 3.11 in runtime.txt",
+		},
+		{
+			name:    "Python not installed",
+			project: PythonProject{VersionFile: ".python-version", PinnedVersion: "3.11"},
+			status:  SeveritySkip,
+			errMsg:  "python is not installed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), dependencyResultsKey{}, map[string]CheckResult{"python": tt.python})
+			res := (&pythonVersionCheck{service: "api", project: tt.project, goos: "linux"}).Run(ctx, &MockRunner{})
+			assert.Equal(t, tt.status, res.Status())
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.ErrorContains(t, res.Error, tt.errMsg)
+			}
+			if tt.status == SeverityFail {
+				assert.Equal(t, &Location{File: "pyproject.toml", Line: 3}, res.Location)
+			}
+		})
+	}
+}
+
+func TestPythonVersionCheck_ServiceDir(t *testing.T) {
+	// A pyenv shim reports the interpreter pinned by the service's .python-version.
+	dir := t.TempDir()
+	mock := &DirMockRunner{
+		OutputInDirFunc: func(d, name string, args ...string) ([]byte, error) {
+			assert.Equal(t, dir, d)
+			assert.Equal(t, []string{"python3", "--version"}, append([]string{name}, args...))
+			return []byte("Python 3.11.9"), nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), dependencyResultsKey{}, map[string]CheckResult{
+		"python": {Name: "python3", Installed: true, Version: "3.12.1"},
+	})
+	project := PythonProject{VersionFile: ".python-version", PinnedVersion: "3.11"}
+
+	res := (&pythonVersionCheck{service: "api", dir: dir, project: project, goos: "linux"}).Run(ctx, mock)
+	assert.Equal(t, SeverityPass, res.Status(), res.Error)
+	assert.Equal(t, "3.11.9", res.Version)
+}
+
+func TestPythonPackageManagerCheck(t *testing.T) {
+	python := CheckResult{Name: "python3", Installed: true, Version: "3.12.1"}
+	tests := []struct {
+		name        string
+		manager     string
+		installed   map[string]string
+		command     []string
+		status      Severity
+		version     string
+		remediation string
+	}{
+		{
+			name:      "pip of the interpreter",
+			manager:   PackageManagerPip,
+			installed: map[string]string{"python3": "pip 24.0 from /usr/lib/python3/dist-packages/pip (python 3.12)"},
+			command:   []string{"python3", "-m", "pip", "--version"},
+			status:    SeverityPass,
+			version:   "pip 24.0.0",
+		},
+		{
+			name:        "pip missing",
+			manager:     PackageManagerPip,
+			command:     []string{"python3", "-m", "pip", "--version"},
+			status:      SeverityFail,
+			remediation: "Install pip with `python3 -m ensurepip --upgrade`",
+		},
+		{
+			name:      "uv",
+			manager:   PackageManagerUv,
+			installed: map[string]string{"uv": "uv 0.4.18"},
+			command:   []string{"uv", "--version"},
+			status:    SeverityPass,
+			version:   "uv 0.4.18",
+		},
+		{
+			name:        "poetry missing",
+			manager:     PackageManagerPoetry,
+			command:     []string{"poetry", "--version"},
+			status:      SeverityFail,
+			remediation: "Install Poetry: https://python-poetry.org/docs/#installation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					assert.Equal(t, tt.command, append([]string{name}, args...))
+					if out, ok := tt.installed[name]; ok {
+						return []byte(out), nil
+					}
+					return nil, fmt.Errorf("not found")
+				},
+			}
+			ctx := context.WithValue(context.Background(), dependencyResultsKey{}, map[string]CheckResult{"python": python})
+			c := &pythonPackageManagerCheck{service: "api", project: PythonProject{PackageManager: tt.manager}, goos: "linux"}
+			res := c.Run(ctx, mock)
+			assert.Equal(t, tt.status, res.Status())
+			assert.Equal(t, tt.remediation, res.Remediation)
+			if tt.version != "" {
+				assert.Equal(t, tt.version, res.Version)
+			}
+		})
+	}
+
+	assert.Equal(t, []string{"python"}, (&pythonPackageManagerCheck{project: PythonProject{PackageManager: PackageManagerPip}}).DependsOn())
+	assert.Empty(t, (&pythonPackageManagerCheck{project: PythonProject{PackageManager: PackageManagerUv}}).DependsOn())
+}
+
+func TestPythonVenvCheck(t *testing.T) {
+	dir := t.TempDir()
+	venv := filepath.Join(dir, ".venv")
+	writeFiles(t, dir, map[string]string{".venv/pyvenv.cfg": "home = /usr/bin\n"})
+	other := t.TempDir()
+	// azd packages Python services with a <service dir>_env environment.
+	azdDir := filepath.Join(t.TempDir(), "api")
+	writeFiles(t, azdDir, map[string]string{"api_env/pyvenv.cfg": "home = /usr/bin\n"})
+
+	tests := []struct {
+		name    string
+		dir     string
+		active  string
+		status  Severity
+		version string
+		errMsg  string
+	}{
+		{name: "Present, not active", dir: dir, status: SeverityPass, version: ".venv (not active)"},
+		{name: "Active", dir: dir, active: venv, status: SeverityPass, version: ".venv (active)"},
+		{name: "Other environment active", dir: dir, active: other, status: SeverityWarn, errMsg: "is not the service's .venv"},
+		{name: "azd environment", dir: azdDir, status: SeverityPass, version: "api_env (not active)"},
+		{name: "Missing", dir: other, status: SeverityInfo, version: "none found, azd creates one when packaging"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupEnv := func(name string) (string, bool) {
+				assert.Equal(t, "VIRTUAL_ENV", name)
+				return tt.active, tt.active != ""
+			}
+			res := (&pythonVenvCheck{service: "api", dir: tt.dir, lookupEnv: lookupEnv}).Run(context.Background(), &MockRunner{})
+			assert.Equal(t, tt.status, res.Status())
+			if tt.version != "" {
+				assert.Equal(t, tt.version, res.Version)
+			}
+			if tt.errMsg == "" {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.ErrorContains(t, res.Error, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestPlanPythonService(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"azure.yaml":                "name: test-project\nservices:\n  api:\n    project: ./src/api\n    language: python\n    host: containerapp\n",
+		"src/api/requirements.txt":  "fastapi\n",
+		"src/api/.python-version":   "3.12\n",
+		"src/api/.venv/pyvenv.cfg":  "",
+		"src/unrelated/runtime.txt": "python-3.8",
+	})
+	config, err := LoadProjectConfig(filepath.Join(dir, "azure.yaml"))
+	require.NoError(t, err)
+
+	planner := NewPlannerWithOS(NewRegistry(), "linux")
+	assert.Equal(t, []string{
+		"python-version:api (api)",
+		"python-package-manager:api (api)",
+		"python-venv:api (api)",
+	}, requirementStrings(planner.Plan(config, PlanOptions{Command: "up"})))
+}
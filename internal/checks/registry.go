@@ -0,0 +1,375 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+)
+
+// Category groups related checks so commands can render and select them together.
+type Category string
+
+const (
+	CategoryAzd       Category = "azd"
+	CategoryShell     Category = "shell"
+	CategoryInfra     Category = "infra"
+	CategoryLanguage  Category = "language"
+	CategoryContainer Category = "container"
+	CategoryHosting   Category = "hosting"
+)
+
+// Check is a single prerequisite check that can be registered with a Registry.
+type Check interface {
+	// ID is a stable identifier for the check (e.g. "docker", "node").
+	ID() string
+	Category() Category
+	Description() string
+	// Applies reports whether the check is relevant for the given project.
+	// A nil config means no azure.yaml was found and only general checks apply.
+	Applies(config *AzureYaml) bool
+	// Run executes the check using the given runner. Checks must not share mutable state
+	// so that they can run concurrently.
+	Run(ctx context.Context, r Runner) CheckResult
+}
+
+// DependentCheck is an optional extension to Check for checks that must run after other
+// checks (e.g. a daemon check after its binary check). Results of the dependencies are
+// available to Run through DependencyResult.
+type DependentCheck interface {
+	DependsOn() []string
+}
+
+// Registry holds the set of known checks in registration order.
+type Registry struct {
+	checks []Check
+	byID   map[string]Check
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byID: make(map[string]Check)}
+}
+
+// Register adds a check to the registry. IDs must be unique.
+func (r *Registry) Register(c Check) error {
+	if c.ID() == "" {
+		return fmt.Errorf("check id must not be empty")
+	}
+	if _, exists := r.byID[c.ID()]; exists {
+		return fmt.Errorf("check %q is already registered", c.ID())
+	}
+	r.checks = append(r.checks, c)
+	r.byID[c.ID()] = c
+	return nil
+}
+
+// MustRegister is like Register but panics on error. Intended for built-in checks.
+func (r *Registry) MustRegister(c Check) {
+	if err := r.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) Get(id string) (Check, bool) {
+	c, ok := r.byID[id]
+	return c, ok
+}
+
+// Checks returns all registered checks in registration order.
+func (r *Registry) Checks() []Check {
+	return append([]Check(nil), r.checks...)
+}
+
+// Applicable returns the checks that apply to the given project, in registration order.
+func (r *Registry) Applicable(config *AzureYaml) []Check {
+	var result []Check
+	for _, c := range r.checks {
+		if c.Applies(config) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// FilterCategory returns the checks from the list that belong to one of the given categories.
+func FilterCategory(list []Check, categories ...Category) []Check {
+	var result []Check
+	for _, c := range list {
+		for _, cat := range categories {
+			if c.Category() == cat {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// toolCheck is the Check implementation used for the built-in tool checks.
+type toolCheck struct {
+	id          string
+	category    Category
+	description string
+	// generic checks run as part of the general health check when there is no project.
+	generic bool
+	// optional checks report a missing tool as a warning instead of a failure.
+	optional bool
+	// remediation is used for failed results that don't provide their own hint.
+	remediation string
+	// versionRange is the supported version range (e.g. ">=3.8 <4"); other versions fail.
+	versionRange string
+	applies      func(config *AzureYaml) bool
+	dependsOn    []string
+	run          func(ctx context.Context, r Runner) CheckResult
+	// installer resolves the install command reported for a missing tool.
+	installer *installer
+}
+
+func (c *toolCheck) ID() string          { return c.id }
+func (c *toolCheck) Category() Category  { return c.category }
+func (c *toolCheck) Description() string { return c.description }
+
+func (c *toolCheck) Applies(config *AzureYaml) bool {
+	if config == nil {
+		return c.generic
+	}
+	return c.applies != nil && c.applies(config)
+}
+
+func (c *toolCheck) DependsOn() []string { return c.dependsOn }
+
+func (c *toolCheck) Run(ctx context.Context, r Runner) CheckResult {
+	res := c.run(ctx, r)
+	res.Severity = res.Status()
+	if c.versionRange != "" && res.Severity == SeverityPass {
+		res = CheckVersionRange(res, c.versionRange)
+		if res.Severity != SeverityPass && c.remediation != "" {
+			res.Remediation = fmt.Sprintf("%s (version %s required)", c.remediation, c.versionRange)
+		}
+	}
+	if res.Severity == SeverityFail && c.optional {
+		res.Severity = SeverityWarn
+	}
+	if res.Remediation == "" && (res.Severity == SeverityFail || res.Severity == SeverityWarn) {
+		res.Remediation = c.remediation
+	}
+	if !res.Installed && c.installer != nil && (res.Severity == SeverityFail || res.Severity == SeverityWarn) {
+		res.Install = c.installer.command(ctx, r, c.id)
+	}
+	return res
+}
+
+func always(*AzureYaml) bool { return true }
+
+// DefaultRegistry returns a registry populated with the built-in checks for the current OS.
+func DefaultRegistry() *Registry {
+	return DefaultRegistryWithOS(runtime.GOOS)
+}
+
+// DefaultRegistryWithOS returns a registry populated with the built-in checks for the given OS.
+func DefaultRegistryWithOS(goos string) *Registry {
+	reg := NewRegistry()
+
+	// AZD tooling
+	reg.MustRegister(&toolCheck{
+		id:          "azd",
+		category:    CategoryAzd,
+		description: "Azure Developer CLI",
+		generic:     true,
+		applies:     always,
+		remediation: "Install the Azure Developer CLI: https://aka.ms/azd-install",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckAzdVersion(r) },
+	})
+	reg.MustRegister(&toolCheck{
+		id:          "git",
+		category:    CategoryAzd,
+		description: "Git",
+		generic:     true,
+		applies:     always,
+		remediation: "Install Git: https://git-scm.com/downloads",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckGit(r) },
+	})
+	reg.MustRegister(&toolCheck{
+		id:          "gh",
+		category:    CategoryAzd,
+		description: "GitHub CLI",
+		generic:     true,
+		applies:     always,
+		// gh is only needed for GitHub integration (e.g. `azd pipeline config`),
+		// not for local package/provision/deploy.
+		optional:    true,
+		remediation: "Install the GitHub CLI (needed for `azd pipeline config`): https://cli.github.com",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckGh(r) },
+	})
+
+	// Container runtime
+	needsContainerRuntime := func(config *AzureYaml) bool { return len(config.LocalBuildServices()) > 0 }
+	reg.MustRegister(&toolCheck{
+		id:          "docker",
+		category:    CategoryContainer,
+		description: "Docker or Podman",
+		generic:     true,
+		applies:     needsContainerRuntime,
+		remediation: "Install Docker Desktop (https://www.docker.com/products/docker-desktop) or Podman, or enable remote build with `azd doctor configure remote-build`",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckContainerRuntimeWithOS(r, goos) },
+	})
+	reg.MustRegister(&toolCheck{
+		id:          "docker-daemon",
+		category:    CategoryContainer,
+		description: "Container runtime daemon",
+		generic:     true,
+		applies:     needsContainerRuntime,
+		dependsOn:   []string{"docker"},
+		run: func(ctx context.Context, r Runner) CheckResult {
+			bin, ok := DependencyResult(ctx, "docker")
+			if !ok {
+				// Run on its own (e.g. outside an Executor); resolve the binary first.
+				bin = CheckContainerRuntimeWithOS(r, goos)
+				if !bin.Installed {
+					return CheckResult{Name: "Container runtime daemon", Severity: SeveritySkip, Error: fmt.Errorf("no container runtime found")}
+				}
+			}
+			return CheckContainerDaemon(r, bin.Name)
+		},
+	})
+
+	// Language runtimes
+	reg.MustRegister(&toolCheck{
+		id:          "node",
+		category:    CategoryLanguage,
+		description: "Node.js runtime",
+		generic:     true,
+		applies:     func(config *AzureYaml) bool { return config.HasLanguage("js", "ts") },
+		remediation: "Install Node.js: https://nodejs.org",
+		// Node.js 18 is the oldest release supported by the Azure SDKs.
+		versionRange: ">=18",
+		run:          func(_ context.Context, r Runner) CheckResult { return CheckNode(r) },
+	})
+	reg.MustRegister(&toolCheck{
+		id:           "python",
+		category:     CategoryLanguage,
+		description:  "Python runtime",
+		generic:      true,
+		applies:      func(config *AzureYaml) bool { return config.HasLanguage("py", "python") },
+		remediation:  "Install Python 3: https://www.python.org/downloads",
+		versionRange: ">=3.8 <4",
+		run:          func(_ context.Context, r Runner) CheckResult { return CheckPythonWithOS(r, goos) },
+	})
+	reg.MustRegister(&toolCheck{
+		id:          "dotnet",
+		category:    CategoryLanguage,
+		description: ".NET SDK",
+		generic:     true,
+		applies:     func(config *AzureYaml) bool { return config.HasLanguage("csharp", "fsharp", "dotnet") },
+		remediation: "Install the .NET SDK: https://dotnet.microsoft.com/download",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckDotNet(r) },
+	})
+
+	reg.MustRegister(&toolCheck{
+		id:          "java",
+		category:    CategoryLanguage,
+		description: "Java Development Kit",
+		applies:     func(config *AzureYaml) bool { return config.HasLanguage("java") },
+		remediation: "Install a JDK: https://learn.microsoft.com/java/openjdk/download",
+		run:         CheckJava,
+	})
+
+	// Hook shells
+	reg.MustRegister(&toolCheck{
+		id:          "bash",
+		category:    CategoryShell,
+		description: "Bash shell for hooks",
+		generic:     true,
+		applies: func(config *AzureYaml) bool {
+			shells := HookShells(config, goos)
+			return shells["sh"] || shells["bash"]
+		},
+		remediation: "Install Bash (Git Bash or WSL on Windows), or set `shell: pwsh` on the hooks",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckBashWithOS(r, goos) },
+	})
+	reg.MustRegister(&toolCheck{
+		id:          "pwsh",
+		category:    CategoryShell,
+		description: "PowerShell for hooks",
+		generic:     true,
+		applies: func(config *AzureYaml) bool {
+			shells := HookShells(config, goos)
+			return shells["pwsh"] || shells["powershell"]
+		},
+		remediation: "Install PowerShell: https://aka.ms/install-powershell",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckPwshWithOS(r, goos) },
+	})
+
+	// Hosting tools
+	reg.MustRegister(&toolCheck{
+		id:          "func",
+		category:    CategoryHosting,
+		description: "Azure Functions Core Tools",
+		generic:     true,
+		applies:     func(config *AzureYaml) bool { return config.HasHost("function") },
+		remediation: "Install Azure Functions Core Tools: https://aka.ms/func-core-tools",
+		// Core Tools 4.x is required for the Functions v4 runtime.
+		versionRange: ">=4",
+		run:          func(_ context.Context, r Runner) CheckResult { return CheckAzureFunctionsCoreTools(r) },
+	})
+	reg.MustRegister(&toolCheck{
+		id:          "swa",
+		category:    CategoryHosting,
+		description: "Azure Static Web Apps CLI",
+		applies:     func(config *AzureYaml) bool { return config.HasHost("staticwebapp") },
+		remediation: "Install the Static Web Apps CLI: npm install -g @azure/static-web-apps-cli",
+		run:         func(_ context.Context, r Runner) CheckResult { return CheckSwaCli(r) },
+	})
+
+	// Infrastructure
+	reg.MustRegister(&toolCheck{
+		id:           "terraform",
+		category:     CategoryInfra,
+		description:  "Terraform CLI",
+		applies:      func(config *AzureYaml) bool { return config.Infra.Provider == "terraform" },
+		remediation:  "Install Terraform: https://developer.hashicorp.com/terraform/install",
+		versionRange: ">=1.0",
+		run:          func(_ context.Context, r Runner) CheckResult { return CheckTerraform(r) },
+	})
+
+	// Missing tools report the install command for the package managers of this machine.
+	installs := &installer{goos: goos}
+	for _, c := range reg.checks {
+		if tc, ok := c.(*toolCheck); ok {
+			tc.installer = installs
+		}
+	}
+
+	return reg
+}
+
+// HookShells returns the set of shells required by project and service hooks.
+// Hooks without an explicit shell use the OS default (pwsh on Windows, sh elsewhere).
+func HookShells(config *AzureYaml, goos string) map[string]bool {
+	shells := make(map[string]bool)
+	if config == nil {
+		return shells
+	}
+
+	add := func(hooks Hooks) {
+		for _, hook := range hooks {
+			shells[hook.EffectiveShell(goos)] = true
+		}
+	}
+
+	add(config.Hooks)
+	for _, name := range config.ServiceNames() {
+		add(config.Services[name].Hooks)
+	}
+	return shells
+}
+
+// SortedShells returns the keys of a shell set in sorted order.
+func SortedShells(shells map[string]bool) []string {
+	result := make([]string, 0, len(shells))
+	for s := range shells {
+		result = append(result, s)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,167 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkIDs(list []Check) []string {
+	ids := make([]string, 0, len(list))
+	for _, c := range list {
+		ids = append(ids, c.ID())
+	}
+	return ids
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	c := &toolCheck{id: "test", category: CategoryAzd, run: func(context.Context, Runner) CheckResult {
+		return CheckResult{Name: "test", Installed: true}
+	}}
+
+	require.NoError(t, r.Register(c))
+	assert.Error(t, r.Register(c), "duplicate ids should be rejected")
+	assert.Error(t, r.Register(&toolCheck{}), "empty ids should be rejected")
+
+	got, ok := r.Get("test")
+	assert.True(t, ok)
+	assert.Equal(t, c, got)
+	assert.Len(t, r.Checks(), 1)
+}
+
+func TestDefaultRegistryApplicable(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		config   *AzureYaml
+		expected []string
+	}{
+		{
+			name:     "No project runs generic checks",
+			goos:     "linux",
+			config:   nil,
+			expected: []string{"azd", "git", "gh", "docker", "docker-daemon", "node", "python", "dotnet", "bash", "pwsh", "func"},
+		},
+		{
+			name:     "Empty project only runs azd checks",
+			goos:     "linux",
+			config:   &AzureYaml{},
+			expected: []string{"azd", "git", "gh"},
+		},
+		{
+			name: "Container app with local build",
+			goos: "linux",
+			config: &AzureYaml{Services: map[string]Service{
+				"api": {Language: "js", Host: "containerapp"},
+			}},
+			expected: []string{"azd", "git", "gh", "docker", "docker-daemon", "node"},
+		},
+		{
+			name: "Remote build skips docker",
+			goos: "linux",
+			config: &AzureYaml{Services: map[string]Service{
+				"api": {Language: "python", Host: "containerapp", Docker: DockerConfig{Remote: true}},
+			}},
+			expected: []string{"azd", "git", "gh", "python"},
+		},
+		{
+			name: "Functions, static web apps and terraform",
+			goos: "linux",
+			config: &AzureYaml{
+				Infra: Infra{Provider: "terraform"},
+				Services: map[string]Service{
+					"fn":  {Language: "dotnet", Host: "function"},
+					"web": {Language: "ts", Host: "staticwebapp"},
+				},
+			},
+			expected: []string{"azd", "git", "gh", "node", "dotnet", "func", "swa", "terraform"},
+		},
+		{
+			name: "Hook default shell on Windows",
+			goos: "windows",
+			config: &AzureYaml{Hooks: Hooks{
+				"preprovision": {Run: "./scripts/setup.ps1"},
+			}},
+			expected: []string{"azd", "git", "gh", "pwsh"},
+		},
+		{
+			name: "Service hook shell",
+			goos: "darwin",
+			config: &AzureYaml{Services: map[string]Service{
+				"api": {Hooks: Hooks{"prepackage": {Shell: "bash", Run: "./build.sh"}}},
+			}},
+			expected: []string{"azd", "git", "gh", "bash"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := DefaultRegistryWithOS(tt.goos)
+			assert.Equal(t, tt.expected, checkIDs(r.Applicable(tt.config)))
+		})
+	}
+}
+
+func TestFilterCategory(t *testing.T) {
+	r := DefaultRegistryWithOS("linux")
+
+	azd := FilterCategory(r.Checks(), CategoryAzd)
+	assert.Equal(t, []string{"azd", "git", "gh"}, checkIDs(azd))
+
+	tools := FilterCategory(r.Checks(), CategoryHosting, CategoryInfra)
+	assert.Equal(t, []string{"func", "swa", "terraform"}, checkIDs(tools))
+}
+
+func TestHookShells(t *testing.T) {
+	config := &AzureYaml{
+		Hooks: Hooks{
+			"preprovision": {Run: "echo hi"},
+			"postdeploy":   {Shell: "pwsh", Run: "./post.ps1"},
+		},
+		Services: map[string]Service{
+			"api": {Hooks: Hooks{"prepackage": {Shell: "zsh", Run: "./build.sh"}}},
+		},
+	}
+
+	assert.Equal(t, []string{"pwsh", "sh", "zsh"}, SortedShells(HookShells(config, "linux")))
+	assert.Equal(t, []string{"pwsh", "zsh"}, SortedShells(HookShells(config, "windows")))
+	assert.Empty(t, HookShells(nil, "linux"))
+}
+
+func TestToolCheckSeverity(t *testing.T) {
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return nil, fmt.Errorf("executable file not found in $PATH")
+		},
+	}
+	r := DefaultRegistryWithOS("linux")
+
+	t.Run("Missing optional tool warns", func(t *testing.T) {
+		gh, _ := r.Get("gh")
+		res := gh.Run(context.Background(), mock)
+		assert.Equal(t, SeverityWarn, res.Status())
+		assert.Contains(t, res.Remediation, "cli.github.com")
+	})
+
+	t.Run("Missing required tool fails", func(t *testing.T) {
+		git, _ := r.Get("git")
+		res := git.Run(context.Background(), mock)
+		assert.Equal(t, SeverityFail, res.Status())
+		assert.Contains(t, res.Remediation, "Install Git")
+	})
+
+	t.Run("Passing tool has no remediation", func(t *testing.T) {
+		node, _ := r.Get("node")
+		res := node.Run(context.Background(), &MockRunner{
+			OutputFunc: func(name string, args ...string) ([]byte, error) {
+				return []byte("v20.0.0"), nil
+			},
+		})
+		assert.Equal(t, SeverityPass, res.Status())
+		assert.Empty(t, res.Remediation)
+	})
+}
@@ -0,0 +1,126 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// serviceChecks builds the checks of the services' project files (build files, runtime
+// version pins) for their language. State shared by the checks of several services,
+// such as the installed .NET SDKs, is read once per plan.
+type serviceChecks struct {
+	config    *AzureYaml
+	goos      string
+	sdks      *installedSDKs
+	workloads *installedWorkloads
+	dotnet    map[string]loadedDotNetProject
+}
+
+type loadedDotNetProject struct {
+	project DotNetProject
+	err     error
+}
+
+func newServiceChecks(config *AzureYaml, goos string) *serviceChecks {
+	return &serviceChecks{
+		config:    config,
+		goos:      goos,
+		sdks:      &installedSDKs{},
+		workloads: &installedWorkloads{},
+		dotnet:    make(map[string]loadedDotNetProject),
+	}
+}
+
+// dotnetProject returns the project file of a .NET service, reading it once.
+func (s *serviceChecks) dotnetProject(name string) (DotNetProject, error) {
+	loaded, ok := s.dotnet[name]
+	if !ok {
+		loaded.project, loaded.err = LoadDotNetProject(s.config.ServiceDir(name))
+		s.dotnet[name] = loaded
+	}
+	return loaded.project, loaded.err
+}
+
+// needsContainerRuntime reports whether a service needs a local container runtime its host
+// doesn't imply. Aspire AppHosts build and run the containers of their resources whatever
+// the host.
+func (s *serviceChecks) needsContainerRuntime(name string) bool {
+	if s.config.file == "" || !isDotNetLanguage(s.config.Services[name].Language) {
+		return false
+	}
+	project, err := s.dotnetProject(name)
+	return err == nil && project.AppHost
+}
+
+// checks returns the checks of a service. Configs that were not loaded from a file have
+// no project files to read.
+func (s *serviceChecks) checks(name string) []Check {
+	if s.config.file == "" {
+		return nil
+	}
+	switch s.config.Services[name].Language {
+	case "java":
+		return javaServiceChecks(s.config, name, s.goos)
+	case "js", "ts":
+		return nodeServiceChecks(s.config, name)
+	case "py", "python":
+		return pythonServiceChecks(s.config, name, s.goos)
+	case "csharp", "fsharp", "dotnet":
+		project, err := s.dotnetProject(name)
+		return dotnetServiceChecks(name, project, err, s.sdks, s.workloads)
+	}
+	return nil
+}
+
+// findUp returns the path of name in dir or one of its parents up to root, or "" if
+// none has it. Directories outside root are only searched themselves. An empty root
+// searches up to the root of the file system.
+func findUp(dir, root, name string) string {
+	for {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		if root != "" {
+			if rel, err := filepath.Rel(root, dir); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+				return ""
+			}
+		}
+		dir = parent
+	}
+}
+
+// projectFileCheck reports a service project file that can't be read or parsed.
+type projectFileCheck struct {
+	service string
+	err     error
+}
+
+func (c *projectFileCheck) ID() string          { return "project-file:" + c.service }
+func (c *projectFileCheck) Category() Category  { return CategoryLanguage }
+func (c *projectFileCheck) Description() string { return fmt.Sprintf("Service %s project", c.service) }
+
+func (c *projectFileCheck) Applies(config *AzureYaml) bool {
+	if config == nil {
+		return false
+	}
+	_, ok := config.Services[c.service]
+	return ok
+}
+
+func (c *projectFileCheck) Run(context.Context, Runner) CheckResult {
+	return CheckResult{
+		Name:        "Service Project",
+		Installed:   true,
+		Error:       c.err,
+		Severity:    SeverityFail,
+		Remediation: "Fix the syntax of the project file",
+	}
+}
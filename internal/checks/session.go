@@ -0,0 +1,79 @@
+package checks
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Session remembers the results of tool checks that didn't fail so that later runs in the
+// same process (e.g. the prepackage, preprovision and predeploy events of one `azd up`
+// in the extension host) don't query the same tools again. Only installed tools and their
+// versions are remembered: environment values, auth, daemons and project files can change
+// between events and are always evaluated again. A nil Session remembers nothing.
+type Session struct {
+	mu      sync.Mutex
+	results map[string]CheckResult
+}
+
+func NewSession() *Session {
+	return &Session{results: make(map[string]CheckResult)}
+}
+
+// get returns the remembered result of the check with the given ID.
+func (s *Session) get(id string) (CheckResult, bool) {
+	if s == nil {
+		return CheckResult{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, ok := s.results[id]
+	return res, ok
+}
+
+// put remembers the result of a reusable check unless it failed or was skipped, which
+// are evaluated again.
+func (s *Session) put(c Check, res CheckResult) {
+	if s == nil || !reusable(c) || res.HasDaemon || res.Failed() || res.Status() == SeveritySkip {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[c.ID()] = res
+}
+
+// reusable reports whether the result of a check stays valid for the life of a session:
+// the registry tool checks, with or without an x-doctor.tools range, except the daemon
+// checks since a daemon can stop between events.
+func reusable(c Check) bool {
+	switch c := c.(type) {
+	case *toolCheck:
+		return !strings.HasSuffix(c.id, "-daemon")
+	case *constrainedCheck:
+		return reusable(c.Check)
+	}
+	return false
+}
+
+// Run returns the results of the checks in order, running only the checks without a
+// remembered result. A check whose dependency was remembered runs on its own, as it
+// does outside an Executor.
+func (s *Session) Run(ctx context.Context, e *Executor, list []Check) []CheckResult {
+	results := make([]CheckResult, len(list))
+	var pending []Check
+	var pendingIndex []int
+	for i, c := range list {
+		if res, ok := s.get(c.ID()); ok {
+			results[i] = res
+			continue
+		}
+		pending = append(pending, c)
+		pendingIndex = append(pendingIndex, i)
+	}
+
+	for j, res := range e.Run(ctx, pending) {
+		results[pendingIndex[j]] = res
+		s.put(pending[j], res)
+	}
+	return results
+}
@@ -0,0 +1,76 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRun(t *testing.T) {
+	calls := make(map[string]int)
+	mock := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			calls[name]++
+			if name == "terraform" {
+				return nil, fmt.Errorf("executable file not found in $PATH")
+			}
+			return []byte("v20.0.0"), nil
+		},
+	}
+	reg := DefaultRegistryWithOS("linux")
+	list := func(ids ...string) []Check {
+		var l []Check
+		for _, id := range ids {
+			c, _ := reg.Get(id)
+			l = append(l, c)
+		}
+		return l
+	}
+
+	session := NewSession()
+	executor := NewExecutor(mock, 1)
+
+	first := session.Run(context.Background(), executor, list("git", "terraform"))
+	assert.Equal(t, SeverityPass, first[0].Status())
+	assert.Equal(t, SeverityFail, first[1].Status())
+
+	second := session.Run(context.Background(), executor, list("git", "node", "terraform"))
+	assert.Equal(t, []string{"git", "node", "terraform"}, []string{second[0].ID, second[1].ID, second[2].ID})
+	assert.Equal(t, first[0], second[0], "passed results are reused")
+	assert.Equal(t, 1, calls["git"])
+	assert.Equal(t, 1, calls["node"])
+	assert.Equal(t, 2, calls["terraform"], "failed checks are evaluated again")
+
+	// Environment and project checks are evaluated again: their inputs change between events.
+	_, file := writeInfraProject(t, testParameters)
+	infra := &infraParametersCheck{file: file, lookupEnv: func(string) (string, bool) { return "pw", true }}
+	session.Run(context.Background(), executor, []Check{infra})
+	session.Run(context.Background(), executor, []Check{infra})
+	assert.Equal(t, 2, calls["azd"], "infra parameters read the environment values every time")
+
+	// Daemon checks are evaluated again: the daemon can stop between events.
+	daemonRunning := true
+	daemonRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) { return []byte("Docker version 24.0.0"), nil },
+		RunFunc: func(name string, args ...string) error {
+			calls[name+" "+args[0]]++
+			if !daemonRunning {
+				return fmt.Errorf("cannot connect to the Docker daemon")
+			}
+			return nil
+		},
+	}
+	daemonExecutor := NewExecutor(daemonRunner, 1)
+	running := session.Run(context.Background(), daemonExecutor, list("docker", "docker-daemon"))
+	assert.Equal(t, SeverityPass, running[1].Status())
+	daemonRunning = false
+	stopped := session.Run(context.Background(), daemonExecutor, list("docker", "docker-daemon"))
+	assert.Equal(t, SeverityFail, stopped[1].Status(), "a stopped daemon is reported")
+	assert.Equal(t, 2, calls["docker info"])
+
+	var none *Session
+	none.Run(context.Background(), executor, list("git"))
+	assert.Equal(t, 2, calls["git"], "a nil session runs every check")
+}
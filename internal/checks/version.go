@@ -0,0 +1,112 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?(-[0-9A-Za-z.-]+)?`)
+
+// toolVersionPatterns extract the version from the output of `<tool> --version` (or
+// `azd version`). Tools that are not listed use the first version number in the output.
+var toolVersionPatterns = map[string]*regexp.Regexp{
+	"azd":       regexp.MustCompile(`azd version (\S+)`),
+	"docker":    regexp.MustCompile(`Docker version ([^,\s]+)`),
+	"podman":    regexp.MustCompile(`podman version (\S+)`),
+	"git":       regexp.MustCompile(`git version (\S+)`),
+	"gh":        regexp.MustCompile(`gh version (\S+)`),
+	"bash":      regexp.MustCompile(`version (\d+\.\d+\.\d+)`),
+	"node":      regexp.MustCompile(`v(\d\S*)`),
+	"python":    regexp.MustCompile(`Python (\S+)`),
+	"python3":   regexp.MustCompile(`Python (\S+)`),
+	"pwsh":      regexp.MustCompile(`PowerShell (\S+)`),
+	"terraform": regexp.MustCompile(`Terraform v(\S+)`),
+}
+
+// ParseToolVersion extracts the version of a tool from its version output, e.g.
+// "Docker version 27.1.1, build 6312585" for docker.
+func ParseToolVersion(tool, output string) (semver.Version, error) {
+	if p, ok := toolVersionPatterns[tool]; ok {
+		if m := p.FindStringSubmatch(output); m != nil {
+			return ParseVersion(m[1])
+		}
+	}
+	return ParseVersion(output)
+}
+
+// ParseVersion extracts the first version number from tool output such as
+// "azd version 1.11.0 (commit 1234567)". Missing patch numbers default to 0.
+func ParseVersion(output string) (semver.Version, error) {
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return semver.Version{}, fmt.Errorf("no version found in %q", output)
+	}
+	patch := m[3]
+	if patch == "" {
+		patch = "0"
+	}
+	return semver.Parse(fmt.Sprintf("%s.%s.%s%s", m[1], m[2], patch, m[4]))
+}
+
+// SatisfiesRange reports whether version satisfies a semver range such as ">= 1.5.0".
+// Ranges use the npm syntax (see NpmRange): partial versions match any release with
+// that prefix ("20" is any 20.x.y, ">=3.8 <4"), and caret ("^20"), tilde ("~1.6"),
+// wildcard ("8.x") and hyphen ("18 - 20") ranges are supported.
+func SatisfiesRange(version semver.Version, requiredRange string) (bool, error) {
+	r, err := semver.ParseRange(NpmRange(requiredRange))
+	if err != nil {
+		return false, fmt.Errorf("invalid version range %q: %w", requiredRange, err)
+	}
+	return r(version), nil
+}
+
+// CheckVersionRange fails a passing result whose version is outside requiredRange.
+// Results with a version that can't be parsed are reported as a warning.
+func CheckVersionRange(res CheckResult, requiredRange string) CheckResult {
+	v, err := ParseVersion(res.Version)
+	if err != nil {
+		res.Severity = SeverityWarn
+		res.Error = fmt.Errorf("could not determine the %s version (%s required)", res.Name, requiredRange)
+		return res
+	}
+	ok, err := SatisfiesRange(v, requiredRange)
+	if err != nil {
+		res.Severity = SeverityFail
+		res.Error = err
+		return res
+	}
+	if !ok {
+		res.Severity = SeverityFail
+		res.Error = fmt.Errorf("version %s does not satisfy %s", v, requiredRange)
+	}
+	return res
+}
+
+// fullVersion pads a partial version with zeros, e.g. 3.11.0 for [3 11].
+func fullVersion(parts []int) string {
+	v := make([]int, 3)
+	copy(v, parts)
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// nextVersion returns the first version after a partial version, e.g. 3.12.0 for [3 11].
+func nextVersion(parts []int) string {
+	bumped := append([]int(nil), parts...)
+	bumped[len(bumped)-1]++
+	return fullVersion(bumped)
+}
+
+// matchesPinnedVersion reports whether version matches the parts of a pinned version
+// such as "3.11" (any 3.11 release) or "20.11.0".
+func matchesPinnedVersion(version []uint64, pinned string) bool {
+	for i, part := range strings.Split(pinned, ".") {
+		if n, _ := strconv.ParseUint(part, 10, 64); i >= len(version) || n != version[i] {
+			return false
+		}
+	}
+	return true
+}
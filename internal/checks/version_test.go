@@ -0,0 +1,182 @@
+package checks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+		wantErr  bool
+	}{
+		{output: "azd version 1.11.0 (commit 1234567)", expected: "1.11.0"},
+		{output: "azd version 1.12.0-beta.1 (commit 1234567)", expected: "1.12.0-beta.1"},
+		{output: "v20.11.1", expected: "20.11.1"},
+		{output: "Python 3.12", expected: "3.12.0"},
+		{output: "no version here", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			v, err := ParseVersion(tt.output)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, v.String())
+		})
+	}
+}
+
+func TestSatisfiesRange(t *testing.T) {
+	tests := []struct {
+		rng     string
+		matches []string
+		rejects []string
+	}{
+		{rng: ">= 1.10.0", matches: []string{"1.11.0"}, rejects: []string{"1.9.0"}},
+		{rng: ">=1.12.0 <2.0.0", matches: []string{"1.12.0"}, rejects: []string{"1.11.0", "2.0.0"}},
+		{rng: ">=4", matches: []string{"4.0.0", "5.1.0"}, rejects: []string{"3.9.9"}},
+		{rng: ">=3.8 <4", matches: []string{"3.12.1"}, rejects: []string{"3.7.9", "4.0.0"}},
+		{rng: "<2 || >=3.1", matches: []string{"1.9.0", "3.1.0"}, rejects: []string{"2.5.0"}},
+		{rng: ">=1.2.3-beta.1", matches: []string{"1.2.3"}, rejects: []string{"1.2.2"}},
+		{rng: "20", matches: []string{"20.0.0", "20.11.1"}, rejects: []string{"19.9.0", "21.0.0"}},
+		{rng: "1.6", matches: []string{"1.6.0", "1.6.5"}, rejects: []string{"1.7.0"}},
+		{rng: "^20", matches: []string{"20.11.1"}, rejects: []string{"18.19.0", "21.0.0"}},
+		{rng: "^1.6.2", matches: []string{"1.9.0"}, rejects: []string{"1.6.1", "2.0.0"}},
+		{rng: "~1.6", matches: []string{"1.6.0", "1.6.9"}, rejects: []string{"1.5.9", "1.7.0"}},
+		{rng: "8.x", matches: []string{"8.0.100"}, rejects: []string{"9.0.100"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rng, func(t *testing.T) {
+			for _, v := range tt.matches {
+				ok, err := SatisfiesRange(semver.MustParse(v), tt.rng)
+				require.NoError(t, err)
+				assert.True(t, ok, v)
+			}
+			for _, v := range tt.rejects {
+				ok, err := SatisfiesRange(semver.MustParse(v), tt.rng)
+				require.NoError(t, err)
+				assert.False(t, ok, v)
+			}
+		})
+	}
+
+	_, err := SatisfiesRange(semver.MustParse("1.11.0"), "latest")
+	assert.Error(t, err)
+}
+
+func TestCheckAzdRequiredVersion(t *testing.T) {
+	installed := CheckResult{Name: "azd", Installed: true, Version: "azd version 1.11.0 (commit 1234567)"}
+
+	tests := []struct {
+		name          string
+		azd           CheckResult
+		requiredRange string
+		expected      Severity
+		remediation   string
+	}{
+		{name: "Satisfied", azd: installed, requiredRange: ">= 1.10.0", expected: SeverityPass},
+		{name: "Too old", azd: installed, requiredRange: ">= 1.12.0", expected: SeverityFail, remediation: "Upgrade azd"},
+		{name: "Invalid range", azd: installed, requiredRange: "newest", expected: SeverityFail, remediation: "requiredVersions.azd"},
+		{name: "Unparsable output", azd: CheckResult{Installed: true, Version: "dev build"}, requiredRange: ">= 1.0.0",
+			expected: SeverityFail, remediation: "Upgrade azd"},
+		{name: "Not installed", azd: CheckResult{Name: "azd"}, requiredRange: ">= 1.0.0", expected: SeveritySkip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := CheckAzdRequiredVersion(tt.azd, tt.requiredRange)
+			assert.Equal(t, tt.expected, res.Status())
+			if tt.remediation != "" {
+				assert.Contains(t, res.Remediation, tt.remediation)
+			}
+			if tt.expected == SeverityPass {
+				assert.Equal(t, "1.11.0", res.Version)
+			}
+		})
+	}
+}
+
+func TestParseToolVersion(t *testing.T) {
+	tests := []struct {
+		tool     string
+		output   string
+		expected string
+	}{
+		{tool: "azd", output: "azd version 1.11.0 (commit 1234567)", expected: "1.11.0"},
+		{tool: "docker", output: "Docker version 27.1.1, build 6312585", expected: "27.1.1"},
+		{tool: "podman", output: "podman version 4.9.3", expected: "4.9.3"},
+		{tool: "git", output: "git version 2.43.0.windows.1", expected: "2.43.0"},
+		{tool: "gh", output: "gh version 2.40.1 (2023-12-13)\nhttps://github.com/cli/cli/releases/tag/v2.40.1", expected: "2.40.1"},
+		{tool: "bash", output: "GNU bash, version 5.2.15(1)-release (x86_64-pc-linux-gnu)\nCopyright (C) 2022 Free Software Foundation, Inc.", expected: "5.2.15"},
+		{tool: "node", output: "v20.11.1", expected: "20.11.1"},
+		{tool: "python3", output: "Python 3.12.1", expected: "3.12.1"},
+		{tool: "pwsh", output: "PowerShell 7.4.1", expected: "7.4.1"},
+		{tool: "terraform", output: "Terraform v1.5.0\non linux_amd64", expected: "1.5.0"},
+		{tool: "func", output: "4.0.5455", expected: "4.0.5455"},
+		{tool: "dotnet", output: "8.0.100", expected: "8.0.100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			v, err := ParseToolVersion(tt.tool, tt.output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, v.String())
+		})
+	}
+}
+
+func TestCheckVersionRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		rng      string
+		expected Severity
+	}{
+		{name: "In range", version: "3.12.1", rng: ">=3.8 <4", expected: SeverityPass},
+		{name: "Too old", version: "3.7.9", rng: ">=3.8 <4", expected: SeverityFail},
+		{name: "Too new", version: "4.0.0", rng: ">=3.8 <4", expected: SeverityFail},
+		{name: "Unknown version", version: "", rng: ">=4", expected: SeverityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := CheckVersionRange(CheckResult{Name: "tool", Installed: true, Version: tt.version, Severity: SeverityPass}, tt.rng)
+			assert.Equal(t, tt.expected, res.Status())
+			if tt.expected != SeverityPass {
+				assert.Error(t, res.Error)
+			}
+		})
+	}
+}
+
+func TestToolCheckVersionRange(t *testing.T) {
+	r := DefaultRegistryWithOS("linux")
+	fn, _ := r.Get("func")
+
+	res := fn.Run(context.Background(), &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("3.0.3904\n"), nil
+		},
+	})
+	assert.Equal(t, SeverityFail, res.Status())
+	assert.Equal(t, "3.0.3904", res.Version)
+	assert.Contains(t, res.Error.Error(), "does not satisfy >=4")
+	assert.Contains(t, res.Remediation, "version >=4 required")
+
+	res = fn.Run(context.Background(), &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte("4.0.5455\n"), nil
+		},
+	})
+	assert.Equal(t, SeverityPass, res.Status())
+}
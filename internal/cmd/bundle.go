@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
+	"github.com/spf13/cobra"
+	"spboyer.azd.doctor/internal/checks"
+	"spboyer.azd.doctor/internal/report"
+)
+
+type bundleOptions struct {
+	outputFile          string
+	redactSubscriptions bool
+	skipAuth            bool
+	authTimeout         time.Duration
+	concurrency         int
+	debugLog            string
+}
+
+func newBundleCommand(runner checks.Runner) *cobra.Command {
+	opts := bundleOptions{debugLog: DebugLogPath}
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Collect a redacted support bundle for bug reports",
+		Long: `Collects the check results, the project and environment context, azure.yaml, the installed
+azd extensions, the azd user config and the doctor debug log into a single zip file.
+Secrets, tokens and sensitive environment values are redacted. The zip contains a
+summary.md that can be pasted into a GitHub issue.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.outputFile == "" {
+				opts.outputFile = fmt.Sprintf("azd-doctor-bundle-%s.zip", time.Now().Format("20060102-150405"))
+			}
+
+			data := collectBundle(cmd.Context(), runner, opts)
+			files := data.files(report.NewRedactor(opts.redactSubscriptions))
+			if err := writeBundle(opts.outputFile, files); err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+
+			for _, note := range data.Notes {
+				printInfo("Bundle", note)
+			}
+			printSuccess("Bundle", opts.outputFile)
+			fmt.Fprintf(getOutputWriter(), "\nAttach %s to the issue and paste its summary.md into the description.\n", opts.outputFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.outputFile, "output-file", "", "Path of the zip file (default azd-doctor-bundle-<timestamp>.zip)")
+	cmd.Flags().BoolVar(&opts.redactSubscriptions, "redact-subscriptions", false, "Also redact Azure subscription IDs")
+	cmd.Flags().BoolVar(&opts.skipAuth, "skip-auth", false, "Skip azd auth status check")
+	cmd.Flags().DurationVar(&opts.authTimeout, "auth-timeout", 5*time.Second, "Timeout for azd auth status check")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", checks.DefaultConcurrency, "Maximum number of checks to run in parallel")
+
+	return cmd
+}
+
+// bundleData is the information collected for a support bundle, before redaction.
+// Parts that couldn't be collected are left empty.
+type bundleData struct {
+	CreatedAt time.Time
+	OS        string
+	Arch      string
+	Checks    *report.Report
+	Context   *report.Context
+	// ProjectFile is the name of the azure.yaml file and Project its content.
+	ProjectFile string
+	Project     []byte
+	Extensions  []byte
+	Config      []byte
+	DebugLog    []byte
+	// Notes explain the files that couldn't be collected.
+	Notes []string
+}
+
+// bundleFile is a file in the support bundle zip.
+type bundleFile struct {
+	Name string
+	Data []byte
+}
+
+func collectBundle(ctx context.Context, runner checks.Runner, opts bundleOptions) *bundleData {
+	data := &bundleData{CreatedAt: time.Now().UTC(), OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	rep, _ := newReporter("check", outputJSON, io.Discard)
+	rep.report.Finish(runCheck(ctx, rep, runner, checkOptions{
+		skipAuth:    opts.skipAuth,
+		authTimeout: opts.authTimeout,
+		concurrency: opts.concurrency,
+	}))
+	data.Checks = rep.report
+
+	azdCtx := azdext.WithAccessToken(ctx)
+	if azdClient, err := azdext.NewAzdClient(); err == nil {
+		data.Context = getProjectContext(azdCtx, azdClient)
+		azdClient.Close()
+	}
+
+	if projectFile, found := findProjectFile(); found {
+		if content, err := os.ReadFile(projectFile); err == nil {
+			data.ProjectFile, data.Project = projectFile, content
+		}
+	}
+	if out, err := runner.Output("azd", "extension", "list", "--installed", "--output", "json"); err == nil {
+		data.Extensions = out
+	}
+	if out, err := runner.Output("azd", "config", "show"); err == nil {
+		data.Config = out
+	}
+	if content, err := os.ReadFile(opts.debugLog); err == nil {
+		data.DebugLog = content
+	} else if os.IsNotExist(err) {
+		data.Notes = append(data.Notes, fmt.Sprintf("No debug log at %s: it is only written when doctor runs as an azd extension", opts.debugLog))
+	} else {
+		data.Notes = append(data.Notes, fmt.Sprintf("Could not read the debug log: %v", err))
+	}
+
+	return data
+}
+
+// files returns the redacted files of the bundle. Structured data is redacted before
+// the text files, so the secrets found by key are also masked in azure.yaml and the log.
+func (d *bundleData) files(r *report.Redactor) []bundleFile {
+	var files []bundleFile
+	add := func(name string, data []byte) {
+		if len(data) > 0 {
+			files = append(files, bundleFile{Name: name, Data: data})
+		}
+	}
+
+	// Values are redacted by key, e.g. the environment values and user config.
+	var projectContext *report.Context
+	if d.Context != nil {
+		redacted := r.JSON(mustJSON(d.Context))
+		if err := json.Unmarshal(redacted, &projectContext); err == nil {
+			add("context.json", redacted)
+		}
+	}
+	add("config.json", r.JSON(d.Config))
+	add("extensions.json", r.JSON(d.Extensions))
+
+	var checkReport *report.Report
+	if d.Checks != nil {
+		redacted := r.JSON(mustJSON(d.Checks))
+		if err := json.Unmarshal(redacted, &checkReport); err == nil {
+			add("checks.json", redacted)
+		}
+	}
+	if d.ProjectFile != "" {
+		add(d.ProjectFile, []byte(r.Text(string(d.Project))))
+	}
+	add("azd-doctor-debug.log", []byte(r.Text(string(d.DebugLog))))
+
+	names := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	summary := bundleSummary(d, checkReport, projectContext, names, r.Subscriptions)
+	return append([]bundleFile{{Name: "summary.md", Data: []byte(r.Text(summary))}}, files...)
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// bundleSummary renders the summary.md of a bundle, meant to be pasted into a GitHub issue.
+func bundleSummary(d *bundleData, checkReport *report.Report, projectContext *report.Context, files []string, subscriptions bool) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, "## azd doctor support bundle")
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "- **Created:** %s\n", d.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- **azd doctor:** %s\n", Version)
+	fmt.Fprintf(&b, "- **OS/Arch:** %s/%s\n", d.OS, d.Arch)
+	if projectContext != nil && projectContext.Project != nil {
+		fmt.Fprintf(&b, "- **Project:** %s\n", projectContext.Project.Name)
+	}
+	if projectContext != nil && projectContext.Environment != "" {
+		fmt.Fprintf(&b, "- **Environment:** %s\n", projectContext.Environment)
+	}
+	if checkReport != nil {
+		fmt.Fprintf(&b, "- **Status:** %s\n", checkReport.Status)
+	}
+
+	if checkReport != nil && len(checkReport.Checks) > 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, "### Checks")
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, "| Status | Check | Version | Details |")
+		fmt.Fprintln(&b, "|---|---|---|---|")
+		for _, c := range checkReport.Checks {
+			details := c.Error
+			if c.Remediation != "" {
+				details = strings.TrimPrefix(details+". "+c.Remediation, ". ")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", c.Status, c.ID, markdownCell(c.Version), markdownCell(details))
+		}
+		if checkReport.Error != "" {
+			fmt.Fprintf(&b, "\nError: %s\n", checkReport.Error)
+		}
+	}
+
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "### Files")
+	fmt.Fprintln(&b)
+	for _, name := range files {
+		fmt.Fprintf(&b, "- `%s`\n", name)
+	}
+	if len(d.Notes) > 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, "### Notes")
+		fmt.Fprintln(&b)
+		for _, note := range d.Notes {
+			fmt.Fprintf(&b, "- %s\n", note)
+		}
+	}
+	fmt.Fprintln(&b)
+	if subscriptions {
+		fmt.Fprintln(&b, "Secrets, tokens, sensitive environment values and subscription IDs have been redacted.")
+	} else {
+		fmt.Fprintln(&b, "Secrets, tokens and sensitive environment values have been redacted.")
+	}
+	return b.String()
+}
+
+// markdownCell escapes a value for a Markdown table cell.
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// writeBundle writes the files to a zip archive at path.
+func writeBundle(path string, files []bundleFile) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(filepath.ToSlash(f.Name))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
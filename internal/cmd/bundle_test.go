@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/checks"
+	"spboyer.azd.doctor/internal/report"
+)
+
+func testBundleData() *bundleData {
+	checkReport := report.New("check")
+	checkReport.Add("node", checks.CategoryLanguage, nil, checks.CheckResult{
+		Name: "node", Severity: checks.SeverityFail, Remediation: "Install Node.js | LTS",
+	})
+	checkReport.Add("git", checks.CategoryAzd, nil, checks.CheckResult{Name: "git", Installed: true, Version: "2.45.0"})
+	checkReport.Finish(nil)
+
+	projectContext := report.NewContext()
+	projectContext.Project = &report.Project{Name: "todo", Path: "/src/todo"}
+	projectContext.Environment = "dev"
+	projectContext.Values = map[string]string{
+		"AZURE_LOCATION":        "eastus",
+		"AZURE_SUBSCRIPTION_ID": "00000000-0000-0000-0000-000000000001",
+		"SQL_PASSWORD":          "pw-12345",
+	}
+
+	return &bundleData{
+		CreatedAt:   time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		OS:          "linux",
+		Arch:        "amd64",
+		Checks:      checkReport,
+		Context:     projectContext,
+		ProjectFile: "azure.yaml",
+		Project:     []byte("name: todo\nhooks:\n  preprovision:\n    run: ./setup.sh --password pw-12345\n"),
+		Extensions:  []byte(`[{"id":"microsoft.azd.extensions","version":"0.5.0"}]`),
+		Config:      []byte(`{"defaults":{"location":"eastus"}}`),
+		DebugLog:    []byte("RunExtensionHost started\nSQL_PASSWORD=pw-12345\nscope /subscriptions/00000000-0000-0000-0000-000000000001\n"),
+	}
+}
+
+func bundleContents(files []bundleFile) map[string]string {
+	contents := make(map[string]string, len(files))
+	for _, f := range files {
+		contents[f.Name] = string(f.Data)
+	}
+	return contents
+}
+
+func TestBundleFiles(t *testing.T) {
+	files := testBundleData().files(report.NewRedactor(false))
+
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	assert.Equal(t, []string{"summary.md", "context.json", "config.json", "extensions.json", "checks.json", "azure.yaml", "azd-doctor-debug.log"}, names)
+
+	contents := bundleContents(files)
+	for name, content := range contents {
+		assert.NotContains(t, content, "pw-12345", "%s leaks the password", name)
+	}
+	assert.Contains(t, contents["context.json"], `"AZURE_LOCATION": "eastus"`)
+	assert.Contains(t, contents["context.json"], "00000000-0000-0000-0000-000000000001", "subscription IDs are kept by default")
+	assert.Contains(t, contents["azure.yaml"], "--password "+report.Redacted)
+
+	summary := contents["summary.md"]
+	assert.Contains(t, summary, "- **OS/Arch:** linux/amd64")
+	assert.Contains(t, summary, "- **Project:** todo")
+	assert.Contains(t, summary, "- **Environment:** dev")
+	assert.Contains(t, summary, "- **Status:** fail")
+	assert.Contains(t, summary, `| fail | node |  | Install Node.js \| LTS |`)
+	assert.Contains(t, summary, "| pass | git | 2.45.0 |  |")
+	assert.Contains(t, summary, "- `azd-doctor-debug.log`")
+}
+
+func TestBundleFiles_RedactSubscriptions(t *testing.T) {
+	contents := bundleContents(testBundleData().files(report.NewRedactor(true)))
+
+	for name, content := range contents {
+		assert.NotContains(t, content, "00000000-0000-0000-0000-000000000001", "%s leaks the subscription ID", name)
+	}
+	assert.Contains(t, contents["summary.md"], "subscription IDs have been redacted")
+}
+
+func TestBundleFiles_MasksContextSecrets(t *testing.T) {
+	data := testBundleData()
+	data.Context.Values["AZURE_KEY_VAULT_ENDPOINT"] = "https://kv-dev.vault.azure.net/"
+	data.Context.Values["STORAGE_ACCOUNT_KEY"] = "secondary-1234"
+	data.DebugLog = append(data.DebugLog, "uploading with secondary-1234\n"...)
+	contents := bundleContents(data.files(report.NewRedactor(false)))
+
+	// The bundle hides the values `context` hides.
+	var bundled report.Context
+	require.NoError(t, json.Unmarshal([]byte(contents["context.json"]), &bundled))
+	masked, _ := report.MaskSecrets(data.Context.Values)
+	assert.Equal(t, masked, bundled.Values)
+	assert.Equal(t, "https://kv-dev.vault.azure.net/", bundled.Values["AZURE_KEY_VAULT_ENDPOINT"])
+	assert.Equal(t, report.Redacted, bundled.Values["STORAGE_ACCOUNT_KEY"])
+	assert.NotContains(t, contents["azd-doctor-debug.log"], "secondary-1234")
+}
+
+func TestBundleFiles_Missing(t *testing.T) {
+	data := &bundleData{OS: "linux", Arch: "amd64", Notes: []string{"No debug log at /tmp/azd-doctor-debug.log"}}
+	contents := bundleContents(data.files(report.NewRedactor(false)))
+
+	assert.Len(t, contents, 1, "only the summary is written when nothing was collected")
+	assert.Contains(t, contents["summary.md"], "### Files")
+	assert.Contains(t, contents["summary.md"], "### Notes\n\n- No debug log at /tmp/azd-doctor-debug.log\n")
+}
+
+func TestDebugLogPath(t *testing.T) {
+	assert.Equal(t, os.TempDir(), filepath.Dir(DebugLogPath), "the debug log is written to the OS temp directory")
+}
+
+func TestWriteBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	files := []bundleFile{{Name: "summary.md", Data: []byte("# summary\n")}, {Name: "checks.json", Data: []byte("{}")}}
+	require.NoError(t, writeBundle(path, files))
+
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		contents[f.Name] = string(data)
+	}
+	assert.Equal(t, map[string]string{"summary.md": "# summary\n", "checks.json": "{}"}, contents)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"spboyer.azd.doctor/internal/checks"
+)
+
+// cachedRunner wraps the runner with the persistent version cache unless noCache is set
+// or the runner can't resolve binaries (e.g. test mocks). The returned function saves
+// the cache and must be called once the checks have run.
+func cachedRunner(r checks.Runner, noCache bool) (checks.Runner, func()) {
+	if _, ok := r.(checks.PathResolver); noCache || !ok {
+		return r, func() {}
+	}
+	path, err := checks.DefaultCachePath()
+	if err != nil {
+		debugLog("version cache disabled: %v", err)
+		return r, func() {}
+	}
+	cache := checks.LoadVersionCache(path, checks.DefaultCacheTTL)
+	return checks.NewCachingRunner(r, cache), func() {
+		if err := cache.Save(); err != nil {
+			debugLog("failed to save version cache: %v", err)
+		}
+	}
+}
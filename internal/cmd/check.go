@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"runtime"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
@@ -13,266 +12,254 @@ import (
 	"spboyer.azd.doctor/internal/checks"
 )
 
-func NewCheckCommand() *cobra.Command {
+func NewCheckCommand(runner checks.Runner) *cobra.Command {
 	var skipAuth bool
 	var authTimeout time.Duration
+	var concurrency int
+	var output string
+	var noCache bool
 
 	checkCmd := &cobra.Command{
 		Use:   "check",
 		Short: "Run the doctor checks",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			printRunning("Doctor Checks", "Starting...")
-
-			// 1) Determine Project File
-			projectFile := "azure.yaml"
-			if _, err := os.Stat(projectFile); os.IsNotExist(err) {
-				projectFile = "azure.yml"
-				if _, err := os.Stat(projectFile); os.IsNotExist(err) {
-					fmt.Println()
-					printRunning("AZD Checks", "Checking tools")
-					printResult(checks.CheckAzdVersion())
-					printResult(checks.CheckGit())
-					printResult(checks.CheckGh())
-
-					fmt.Println()
-					printRunning("Project Checks", "Checking azd project")
-					printInfo("Project File", "Not found (azure.yaml/azure.yml)")
-					printInfo("Project Name", "Unknown")
-
-					fmt.Println()
-					printRunning("Generic Checks", "Checking common dependencies")
-					printResult(checks.CheckDocker())
-					printResult(checks.CheckNode())
-					printResult(checks.CheckPython())
-					printResult(checks.CheckDotNet())
-					printResult(checks.CheckBash())
-					printResult(checks.CheckPwsh())
-					printResult(checks.CheckAzureFunctionsCoreTools())
-
-					fmt.Println()
-					if skipAuth {
-						printInfo("Azd Auth", "Skipped")
-						return nil
-					}
-					printRunning("Azd Auth", "Checking login status")
-					authCtx, cancel := context.WithTimeout(cmd.Context(), authTimeout)
-					defer cancel()
-					printResult(checks.CheckAzdLogin(authCtx, nil))
-					return nil
-				}
-			}
-
-			// 2) Load Project
-			config, err := checks.LoadProjectConfig(projectFile)
+			rep, err := newReporter("check", output, cmd.OutOrStdout())
 			if err != nil {
-				return fmt.Errorf("failed to load project config: %w", err)
+				return err
 			}
+			runner, saveCache := cachedRunner(runner, noCache)
+			defer saveCache()
+			return rep.finish(runCheck(cmd.Context(), rep, runner, checkOptions{
+				skipAuth:    skipAuth,
+				authTimeout: authTimeout,
+				concurrency: concurrency,
+			}))
+		},
+	}
 
-			// Initialize azd client only when we have a project file.
-			ctx := azdext.WithAccessToken(cmd.Context())
-			azdClient, err := azdext.NewAzdClient()
-			if err != nil {
-				return fmt.Errorf("failed to create azd client: %w", err)
-			}
-			defer azdClient.Close()
-
-			// 3) AZD / Tool Checks
-			fmt.Println()
-			printRunning("AZD Checks", "Checking tools")
-			printResult(checks.CheckAzdVersion())
-			printResult(checks.CheckGit())
-			printResult(checks.CheckGh())
-
-			// 4) Project Checks
-			fmt.Println()
-			printRunning("Project Checks", "Checking azd project")
-			printSuccess("Project File", projectFile)
-			printInfo("Project Name", config.Name)
-			printResult(checks.CheckAzdInit(ctx, azdClient))
-
-			// 5) Project Hooks
-			if len(config.Hooks) > 0 {
-				fmt.Println()
-				printRunning("Project Hooks", "Checking requirements")
-				checkHooks(config.Hooks)
-			}
+	checkCmd.Flags().BoolVar(&skipAuth, "skip-auth", false, "Skip azd auth status check")
+	checkCmd.Flags().DurationVar(&authTimeout, "auth-timeout", 5*time.Second, "Timeout for azd auth status check")
+	checkCmd.Flags().IntVar(&concurrency, "concurrency", checks.DefaultConcurrency, "Maximum number of checks to run in parallel")
+	checkCmd.Flags().StringVarP(&output, "output", "o", outputText, "Output format (text, json)")
+	checkCmd.Flags().BoolVar(&noCache, "no-cache", false, "Don't reuse cached tool versions")
 
-			// 6) Required Extensions
-			if len(config.RequiredVersions.Extensions) > 0 {
-				fmt.Println()
-				printRunning("Extensions", "Checking requirements")
-				installedExtensions, err := checks.GetInstalledExtensions()
-				if err != nil {
-					printFailure("Extensions", fmt.Sprintf("Failed to list: %v", err))
-				} else {
-					for name, version := range config.RequiredVersions.Extensions {
-						printResult(checks.CheckExtension(installedExtensions, name, version))
-					}
-				}
-			}
+	return checkCmd
+}
 
-			// 7) Infra Checks
-			fmt.Println()
-			printRunning("Infra", "Checking requirements")
-			provider := config.Infra.Provider
-			if provider == "terraform" {
-				printResult(checks.CheckTerraform())
-			} else {
-				// Default provider is bicep
-				if provider == "" {
-					provider = "bicep"
-				}
-				printInfo("Provider", provider)
-			}
+type checkOptions struct {
+	skipAuth    bool
+	authTimeout time.Duration
+	concurrency int
+}
 
-			// 8) Services
-			checkedLangs := make(map[string]bool)
-			checkedTools := make(map[string]bool)
+func runCheck(ctx context.Context, rep *reporter, runner checks.Runner, opts checkOptions) error {
+	planner := checks.NewPlanner(checks.DefaultRegistry())
+	executor := checks.NewExecutor(runner, opts.concurrency)
 
-			for name, svc := range config.Services {
-				fmt.Println()
-				printRunning("Service", fmt.Sprintf("%s (%s, %s)", name, svc.Host, svc.Language))
+	rep.running("Doctor Checks", "Starting...")
 
-				// Check Language Requirements
-				if svc.Language != "" && !checkedLangs[svc.Language] {
-					switch svc.Language {
-					case "js", "ts":
-						printResult(checks.CheckNode())
-					case "py", "python":
-						printResult(checks.CheckPython())
-					case "csharp", "fsharp", "dotnet":
-						printResult(checks.CheckDotNet())
-					default:
-						// Unknown language - no checks.
-					}
-					checkedLangs[svc.Language] = true
-				}
-
-				// Check Hooks (Service Level)
-				if len(svc.Hooks) > 0 {
-					checkHooks(svc.Hooks)
-				}
-
-				// Check Container Requirements
-				isContainerHost := svc.Host == "containerapp" || svc.Host == "aks"
-				needsBuild := svc.Image == "" // If image is provided, assume pre-built.
-
-				if isContainerHost && !svc.Docker.Remote && needsBuild {
-					if !checkedTools["docker"] {
-						dockerCheck := checks.CheckDocker()
-						printResult(dockerCheck)
-						// If Docker daemon is not running, suggest remote-build
-						if dockerCheck.Installed && dockerCheck.HasDaemon && !dockerCheck.Running {
-							fmt.Fprintf(getOutputWriter(), "\n%s %s\n",
-								color.YellowString("💡 Tip:"),
-								"Enable remote build to build without local Docker:")
-							fmt.Fprintf(getOutputWriter(), "   Run: %s\n\n",
-								color.CyanString("azd doctor configure remote-build"))
-						}
-						checkedTools["docker"] = true
-					}
-				}
-
-				// Check Azure Functions
-				if svc.Host == "function" {
-					if !checkedTools["func"] {
-						printResult(checks.CheckAzureFunctionsCoreTools())
-						checkedTools["func"] = true
-					}
-				}
-
-				// Check Static Web Apps
-				if svc.Host == "staticwebapp" {
-					if !checkedTools["swa"] {
-						printResult(checks.CheckSwaCli())
-						checkedTools["swa"] = true
-					}
-				}
-			}
+	// 1) Determine Project File
+	projectFile, found := findProjectFile()
+	if !found {
+		plan := planner.Plan(nil, checks.PlanOptions{})
+		results := runPlan(ctx, executor, plan)
 
-			// 9) Azd Auth (separate + optional + timeout)
-			fmt.Println()
-			if skipAuth {
-				printInfo("Azd Auth", "Skipped")
-				if azdClient != nil {
-					azdClient.Close()
-				}
-				return nil
-			}
-			printRunning("Azd Auth", "Checking login status")
-			authCtx, cancel := context.WithTimeout(cmd.Context(), authTimeout)
-			defer cancel()
-			printResult(checks.CheckAzdLogin(authCtx, azdClient))
-
-			// Ensure gRPC connection is closed before program exit
-			if azdClient != nil {
-				azdClient.Close()
+		rep.section("AZD Checks", "Checking tools")
+		reportPlanResults(rep, plan, results, checks.CategoryAzd)
+
+		rep.section("Project Checks", "Checking azd project")
+		rep.info("project-file", checks.CategoryAzd, "Project File", "Not found (azure.yaml/azure.yml)")
+		rep.info("project-name", checks.CategoryAzd, "Project Name", "Unknown")
+
+		rep.section("Generic Checks", "Checking common dependencies")
+		reportPlanResults(rep, plan, results,
+			checks.CategoryContainer, checks.CategoryLanguage, checks.CategoryShell, checks.CategoryHosting)
+
+		return checkAuth(ctx, rep, runner, nil, opts)
+	}
+
+	// 2) Load Project
+	rep.report.ProjectFile = projectFile
+	config, err := checks.LoadProjectConfig(projectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+	plan := planner.Plan(config, checks.PlanOptions{})
+	results := runPlan(ctx, executor, plan)
+
+	// Initialize azd client only when we have a project file.
+	ctx = azdext.WithAccessToken(ctx)
+	azdClient, err := azdext.NewAzdClient()
+	if err != nil {
+		return fmt.Errorf("failed to create azd client: %w", err)
+	}
+	// Ensure gRPC connection is closed before program exit
+	defer azdClient.Close()
+
+	// 3) AZD / Tool Checks
+	rep.section("AZD Checks", "Checking tools")
+	reportPlanResults(rep, plan, results, checks.CategoryAzd)
+
+	// 4) Project Checks
+	rep.section("Project Checks", "Checking azd project")
+	rep.result("project-file", checks.CategoryAzd, nil,
+		checks.CheckResult{Name: "Project File", Installed: true, Version: projectFile, Severity: checks.SeverityPass})
+	rep.info("project-name", checks.CategoryAzd, "Project Name", config.Name)
+	rep.result("azd-init", checks.CategoryAzd, nil, timed(func() checks.CheckResult {
+		return checks.CheckAzdInit(ctx, azdClient)
+	}))
+
+	// 5) Hooks
+	if hasCategory(plan, checks.CategoryShell) {
+		rep.section("Hooks", "Checking requirements")
+		reportPlanResults(rep, plan, results, checks.CategoryShell)
+	}
+
+	// 6) Required Extensions
+	if hasCategory(plan, checks.CategoryExtension) {
+		rep.section("Extensions", "Checking requirements")
+		reportPlanResults(rep, plan, results, checks.CategoryExtension)
+	}
+
+	// 7) Custom Checks
+	if hasCategory(plan, checks.CategoryCustom) {
+		rep.section("Custom Checks", "Checking project requirements")
+		reportPlanResults(rep, plan, results, checks.CategoryCustom)
+	}
+
+	// 8) Infra Checks
+	rep.section("Infra", "Checking requirements")
+	// Default provider is bicep
+	provider := config.Infra.Provider
+	if provider == "" {
+		provider = "bicep"
+	}
+	if _, ok := plan.Find(provider); !ok {
+		rep.info("infra-provider", checks.CategoryInfra, "Provider", provider)
+	}
+	reportPlanResults(rep, plan, results, checks.CategoryInfra)
+
+	// 9) Services
+	if len(config.Services) > 0 {
+		if rep.isText() {
+			fmt.Fprintln(getOutputWriter())
+			for _, name := range config.ServiceNames() {
+				svc := config.Services[name]
+				printRunning("Service", fmt.Sprintf("%s (%s, %s)", name, svc.Host, svc.Language))
 			}
-			return nil
-		},
+		}
+		reportPlanResults(rep, plan, results, checks.CategoryLanguage, checks.CategoryContainer, checks.CategoryHosting)
+
+		// If Docker daemon is not running, suggest remote-build
+		if daemon, ok := results["docker-daemon"]; ok && daemon.Installed && !daemon.Running && rep.isText() {
+			fmt.Fprintf(getOutputWriter(), "\n%s %s\n",
+				color.YellowString("💡 Tip:"),
+				"Enable remote build to build without local Docker:")
+			fmt.Fprintf(getOutputWriter(), "   Run: %s\n\n",
+				color.CyanString("azd doctor configure remote-build"))
+		}
 	}
 
-	checkCmd.Flags().BoolVar(&skipAuth, "skip-auth", false, "Skip azd auth status check")
-	checkCmd.Flags().DurationVar(&authTimeout, "auth-timeout", 5*time.Second, "Timeout for azd auth status check")
+	// 10) Azd Auth (separate + optional + timeout)
+	return checkAuth(ctx, rep, runner, azdClient, opts)
+}
 
-	return checkCmd
+// checkAuth reports the azd login status unless --skip-auth is set.
+func checkAuth(ctx context.Context, rep *reporter, runner checks.Runner, azdClient *azdext.AzdClient, opts checkOptions) error {
+	if opts.skipAuth {
+		rep.report.Add("azd-auth", checks.CategoryAzd, nil,
+			checks.CheckResult{Name: "Azd Auth", Severity: checks.SeveritySkip, Error: fmt.Errorf("skipped by --skip-auth")})
+		if rep.isText() {
+			fmt.Fprintln(getOutputWriter())
+			printInfo("Azd Auth", "Skipped")
+		}
+		return nil
+	}
+	rep.section("Azd Auth", "Checking login status")
+	authCtx, cancel := context.WithTimeout(ctx, opts.authTimeout)
+	defer cancel()
+	rep.result("azd-auth", checks.CategoryAzd, nil, timed(func() checks.CheckResult {
+		return checks.CheckAzdLogin(authCtx, runner, azdClient)
+	}))
+	return nil
 }
 
-func runGenericChecks(ctx context.Context, azdClient checks.IAzdClient) {
-	printResult(checks.CheckAzdVersion())
-	printResult(checks.CheckAzdLogin(ctx, azdClient))
-	printResult(checks.CheckGit())
-	printResult(checks.CheckGh())
-	printResult(checks.CheckDocker())
-	printResult(checks.CheckNode())
-	printResult(checks.CheckPython())
-	printResult(checks.CheckDotNet())
-	printResult(checks.CheckBash())
-	printResult(checks.CheckPwsh())
-	printResult(checks.CheckAzureFunctionsCoreTools())
+// timed runs a check outside of the executor and records its duration.
+func timed(run func() checks.CheckResult) checks.CheckResult {
+	start := time.Now()
+	res := run()
+	res.Duration = time.Since(start)
+	return res
 }
 
-func checkHooks(hooks checks.Hooks) {
-	checkedShells := make(map[string]bool)
-
-	for _, hookConfig := range hooks {
-		shell := hookConfig.Shell
-		if shell == "" {
-			// Default based on OS
-			if runtime.GOOS == "windows" {
-				shell = "pwsh" // or powershell
-			} else {
-				shell = "sh" // or bash
-			}
+// findProjectFile returns the azure.yaml (or azure.yml) path in the current directory.
+func findProjectFile() (string, bool) {
+	for _, name := range []string{"azure.yaml", "azure.yml"} {
+		if _, err := os.Stat(name); err == nil {
+			return name, true
 		}
+	}
+	return "", false
+}
 
-		if !checkedShells[shell] {
-			// fmt.Printf("  Hook '%s' requires shell: %s\n", hookName, shell)
-			switch shell {
-			case "sh", "bash":
-				printResult(checks.CheckBash())
-			case "pwsh", "powershell":
-				printResult(checks.CheckPwsh())
-			default:
-				printInfo("Unknown Shell", shell)
+// runPlan executes the plan concurrently and returns the results keyed by check ID.
+func runPlan(ctx context.Context, executor *checks.Executor, plan *checks.Plan) map[string]checks.CheckResult {
+	list := plan.Checks()
+	results := make(map[string]checks.CheckResult, len(list))
+	for i, res := range executor.Run(ctx, list) {
+		results[list[i].ID()] = res
+	}
+	return results
+}
+
+// reportPlanResults reports the results of the plan's requirements in the given categories, in plan order.
+func reportPlanResults(rep *reporter, plan *checks.Plan, results map[string]checks.CheckResult, categories ...checks.Category) {
+	for _, req := range plan.Requirements {
+		for _, category := range categories {
+			if req.Check.Category() == category {
+				rep.requirement(req, results[req.Check.ID()])
 			}
-			checkedShells[shell] = true
 		}
 	}
 }
 
+func hasCategory(plan *checks.Plan, category checks.Category) bool {
+	return len(checks.FilterCategory(plan.Checks(), category)) > 0
+}
+
 func printResult(res checks.CheckResult) {
-	if res.Installed {
+	switch res.Status() {
+	case checks.SeverityPass:
 		printSuccess(res.Name, res.Version)
 		if res.HasDaemon {
-			if res.Running {
-				printSuccess(fmt.Sprintf("%s Daemon", res.Name), "Running")
-			} else {
-				printFailure(fmt.Sprintf("%s Daemon", res.Name), "Not running")
-			}
+			printSuccess(fmt.Sprintf("%s Daemon", res.Name), "Running")
 		}
-	} else {
-		printFailure(res.Name, "Not found")
+	case checks.SeverityWarn:
+		printWarning(res.Name, resultDetails(res))
+		printRemediation(res.Remediation)
+		printInstall(res.Install)
+	case checks.SeverityFail:
+		printFailure(res.Name, resultDetails(res))
+		printRemediation(res.Remediation)
+		printInstall(res.Install)
+	case checks.SeveritySkip:
+		printSkipped(res.Name, resultDetails(res))
+	case checks.SeverityInfo:
+		printInfo(res.Name, res.Version)
+	}
+}
+
+// resultDetails returns the short explanation shown next to a non-passing result.
+func resultDetails(res checks.CheckResult) string {
+	switch {
+	case !res.Installed && res.Status() != checks.SeveritySkip:
+		return "Not found"
+	case res.HasDaemon && !res.Running:
+		return "Daemon not running"
+	case res.Error != nil:
+		return res.Error.Error()
+	default:
+		return res.Version
 	}
 }
 
@@ -302,6 +289,38 @@ func printFailure(message, details string) {
 		color.HiBlackString("(%s)", details))
 }
 
+func printWarning(message, details string) {
+	fmt.Fprintf(getOutputWriter(), "%s %s  %-20s  %s\n",
+		color.YellowString("(!)"),
+		color.YellowString("Warning"),
+		message,
+		color.HiBlackString("(%s)", details))
+}
+
+func printSkipped(message, details string) {
+	fmt.Fprintf(getOutputWriter(), "%s %s  %-20s  %s\n",
+		color.HiBlackString("(-)"),
+		color.HiBlackString("Skipped"),
+		message,
+		color.HiBlackString("(%s)", details))
+}
+
+// printRemediation prints a fix hint under a failed or warning result.
+func printRemediation(remediation string) {
+	if remediation == "" {
+		return
+	}
+	fmt.Fprintf(getOutputWriter(), "%13s%s\n", "", color.HiBlackString("↳ %s", remediation))
+}
+
+// printInstall prints the install command for a missing tool.
+func printInstall(command string) {
+	if command == "" {
+		return
+	}
+	fmt.Fprintf(getOutputWriter(), "%13s%s %s\n", "", color.HiBlackString("↳ Install:"), color.CyanString(command))
+}
+
 func printRunning(message, details string) {
 	fmt.Fprintf(getOutputWriter(), "%s %s  %-20s  %s\n",
 		color.CyanString("(-)"),
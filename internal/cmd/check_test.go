@@ -8,16 +8,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"spboyer.azd.doctor/internal/checks"
 )
 
 // TestCheckCommand_NoProjectFile tests that the check command
 // runs only non-project checks when there's no azure.yaml file
 func TestCheckCommand_NoProjectFile(t *testing.T) {
-	// Save original runner and restore after test
-	origRunner := checks.CommandRunner
-	defer func() { checks.CommandRunner = origRunner }()
-
 	// Mock runner that returns success for all tools
 	mockRunner := &MockRunner{
 		OutputFunc: func(name string, args ...string) ([]byte, error) {
@@ -51,7 +46,6 @@ func TestCheckCommand_NoProjectFile(t *testing.T) {
 			return nil
 		},
 	}
-	checks.CommandRunner = mockRunner
 
 	// Create a temporary directory without azure.yaml
 	tmpDir, err := os.MkdirTemp("", "test-no-project-*")
@@ -70,7 +64,7 @@ func TestCheckCommand_NoProjectFile(t *testing.T) {
 	os.Stdout = w
 
 	// Run check command
-	cmd := NewCheckCommand()
+	cmd := NewCheckCommand(mockRunner)
 	cmd.SetArgs([]string{"--skip-auth"})
 	err = cmd.Execute()
 
@@ -110,10 +104,6 @@ func TestCheckCommand_NoProjectFile(t *testing.T) {
 // TestCheckCommand_WithProjectFile tests that the check command
 // runs all checks including project-specific ones when azure.yaml exists
 func TestCheckCommand_WithProjectFile(t *testing.T) {
-	// Save original runner and restore after test
-	origRunner := checks.CommandRunner
-	defer func() { checks.CommandRunner = origRunner }()
-
 	// Mock runner
 	mockRunner := &MockRunner{
 		OutputFunc: func(name string, args ...string) ([]byte, error) {
@@ -136,7 +126,6 @@ func TestCheckCommand_WithProjectFile(t *testing.T) {
 			return nil
 		},
 	}
-	checks.CommandRunner = mockRunner
 
 	// Create a temporary directory with azure.yaml
 	tmpDir, err := os.MkdirTemp("", "test-with-project-*")
@@ -167,7 +156,7 @@ infra:
 	os.Stdout = w
 
 	// Run check command
-	cmd := NewCheckCommand()
+	cmd := NewCheckCommand(mockRunner)
 	cmd.SetArgs([]string{"--skip-auth"})
 	err = cmd.Execute()
 
@@ -40,47 +40,62 @@ func runConfigureRemoteBuild() error {
 		}
 	}
 
+	updated, err := enableRemoteBuild(projectFile)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		fmt.Println("No changes needed. Remote build is already enabled or no applicable services found.")
+		return nil
+	}
+
+	fmt.Printf("Successfully enabled remote build in %s\n", projectFile)
+	return nil
+}
+
+// enableRemoteBuild enables remote build for the container services in projectFile and
+// reports whether the file was changed.
+func enableRemoteBuild(projectFile string) (bool, error) {
+	return updateProjectFile(projectFile, enableRemoteBuildInNodes)
+}
+
+// updateProjectFile applies update to the parsed project file, preserving comments, and
+// writes it back if update reports a change.
+func updateProjectFile(projectFile string, update func(root *yaml.Node) (bool, error)) (bool, error) {
 	// Read file
 	data, err := os.ReadFile(projectFile)
 	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", projectFile, err)
+		return false, fmt.Errorf("failed to read %s: %w", projectFile, err)
 	}
 
 	// Parse into Node to preserve comments
 	var root yaml.Node
 	if err := yaml.Unmarshal(data, &root); err != nil {
-		return fmt.Errorf("failed to parse %s: %w", projectFile, err)
+		return false, fmt.Errorf("failed to parse %s: %w", projectFile, err)
 	}
 
 	// Traverse and update
-	updated, err := enableRemoteBuildInNodes(&root)
-	if err != nil {
-		return err
-	}
-
-	if !updated {
-		fmt.Println("No changes needed. Remote build is already enabled or no applicable services found.")
-		return nil
+	updated, err := update(&root)
+	if err != nil || !updated {
+		return false, err
 	}
 
 	// Write back
-	// Use 2 spaces indentation
-	encoder := yaml.NewEncoder(os.Stdout) // Temporary to check output? No, write to file.
-	
 	f, err := os.Create(projectFile)
 	if err != nil {
-		return fmt.Errorf("failed to open %s for writing: %w", projectFile, err)
+		return false, fmt.Errorf("failed to open %s for writing: %w", projectFile, err)
 	}
 	defer f.Close()
 
-	encoder = yaml.NewEncoder(f)
+	// Use 2 spaces indentation
+	encoder := yaml.NewEncoder(f)
 	encoder.SetIndent(2)
 	if err := encoder.Encode(&root); err != nil {
-		return fmt.Errorf("failed to write %s: %w", projectFile, err)
+		return false, fmt.Errorf("failed to write %s: %w", projectFile, err)
 	}
 
-	fmt.Printf("Successfully enabled remote build in %s\n", projectFile)
-	return nil
+	return true, nil
 }
 
 func enableRemoteBuildInNodes(root *yaml.Node) (bool, error) {
@@ -4,20 +4,31 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"spboyer.azd.doctor/internal/report"
 )
 
 func newContextCommand() *cobra.Command {
-	return &cobra.Command{
+	var output string
+	var showSecrets bool
+
+	cmd := &cobra.Command{
 		Use:   "context",
 		Short: "Get the context of the AZD project & environment.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateContextOutput(output); err != nil {
+				return err
+			}
+
 			// Create a new context that includes the AZD access token
 			ctx := azdext.WithAccessToken(cmd.Context())
 
@@ -29,136 +40,268 @@ func newContextCommand() *cobra.Command {
 
 			defer azdClient.Close()
 
-			hasEnv := false
-
-			getConfigResponse, err := azdClient.UserConfig().Get(ctx, &azdext.GetUserConfigRequest{
-				Path: "",
-			})
-			if err == nil {
-				if getConfigResponse.Found {
-					color.HiWhite("User Config")
-					var userConfig map[string]string
-					err := json.Unmarshal(getConfigResponse.Value, &userConfig)
-					if err == nil {
-						jsonBytes, err := json.MarshalIndent(userConfig, "", "  ")
-						if err == nil {
-							fmt.Println(string(jsonBytes))
-						}
-					}
-				}
+			projectContext := getProjectContext(ctx, azdClient)
+			hidden := 0
+			if !showSecrets {
+				projectContext.Values, hidden = report.MaskSecrets(projectContext.Values)
+			}
+			switch output {
+			case outputJSON:
+				return projectContext.Write(cmd.OutOrStdout())
+			case outputYAML:
+				return projectContext.WriteYAML(cmd.OutOrStdout())
+			case outputTable:
+				return projectContext.WriteTable(cmd.OutOrStdout())
 			}
+			printProjectContext(cmd.OutOrStdout(), projectContext, hidden)
+			return nil
+		},
+	}
 
-			getProjectResponse, err := azdClient.Project().Get(ctx, &azdext.EmptyRequest{})
-			if err == nil {
-				color.Cyan("Project:")
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "Output format (text, json, yaml, table)")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Show secret environment values instead of masking them")
+	cmd.AddCommand(newContextDiffCommand())
 
-				projectValues := map[string]string{
-					"Name": getProjectResponse.Project.Name,
-					"Path": getProjectResponse.Project.Path,
-				}
+	return cmd
+}
 
-				for key, value := range projectValues {
-					fmt.Printf("%s: %s\n", color.HiWhiteString(key), value)
-				}
-				fmt.Println()
-			} else {
-				color.Yellow("WARNING: No azd project found in current working directory")
-				fmt.Printf("Run %s to create a new project.\n", color.CyanString("azd init"))
-				if azdClient != nil {
-					azdClient.Close()
-				}
-				return nil
+func newContextDiffCommand() *cobra.Command {
+	var output string
+	var showSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <envA> <envB>",
+		Short: "Compare the values and deployment scope of two azd environments",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output); err != nil {
+				return err
 			}
 
-			var currentEnvName string
-
-			getEnvResponse, err := azdClient.Environment().GetCurrent(ctx, &azdext.EmptyRequest{})
-			if err == nil {
-				currentEnvName = getEnvResponse.Environment.Name
-				hasEnv = true
-			} else {
-				color.Yellow("WARNING: No azd environment(s) found.")
-				fmt.Printf("Run %s to create a new environment.\n", color.CyanString("azd env new"))
-				if azdClient != nil {
-					azdClient.Close()
-				}
-				return nil
+			ctx := azdext.WithAccessToken(cmd.Context())
+			azdClient, err := azdext.NewAzdClient()
+			if err != nil {
+				return fmt.Errorf("failed to create azd client: %w", err)
 			}
+			defer azdClient.Close()
 
-			environments := []string{}
-			envListResponse, err := azdClient.Environment().List(ctx, &azdext.EmptyRequest{})
-			if err == nil {
-				for _, env := range envListResponse.Environments {
-					environments = append(environments, env.Name)
+			values := make([]map[string]string, len(args))
+			for i, name := range args {
+				if values[i], err = getEnvironmentValues(ctx, azdClient, name); err != nil {
+					return err
 				}
 			}
 
-			if len(environments) == 0 {
-				fmt.Println("No environments found")
+			diffs := report.DiffEnvironments(values[0], values[1])
+			if !showSecrets {
+				maskDifferences(diffs)
+			}
+			if output == outputJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(diffs)
 			}
+			printDifferences(cmd.OutOrStdout(), "Environments", args[0], args[1], diffs)
+			return nil
+		},
+	}
 
-			if hasEnv {
-				color.Cyan("Environments:")
-				for _, env := range environments {
-					envLine := env
-					if env == currentEnvName {
-						envLine += color.HiWhiteString(" (selected)")
-					}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "Output format (text, json)")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Show secret environment values instead of masking them")
 
-					fmt.Printf("- %s\n", envLine)
-				}
+	return cmd
+}
 
-				fmt.Println()
-
-				getValuesResponse, err := azdClient.Environment().GetValues(ctx, &azdext.GetEnvironmentRequest{
-					Name: currentEnvName,
-				})
-				if err == nil {
-					color.Cyan("Environment values:")
-					for _, pair := range getValuesResponse.KeyValues {
-						fmt.Printf("%s: %s\n", color.HiWhiteString(pair.Key), color.HiBlackString(pair.Value))
-					}
-					fmt.Println()
-				}
+func getEnvironmentValues(ctx context.Context, azdClient *azdext.AzdClient, name string) (map[string]string, error) {
+	response, err := azdClient.Environment().GetValues(ctx, &azdext.GetEnvironmentRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get values of environment %s: %w", name, err)
+	}
+	values := make(map[string]string, len(response.KeyValues))
+	for _, pair := range response.KeyValues {
+		values[pair.Key] = pair.Value
+	}
+	return values, nil
+}
 
-				deploymentContextResponse, err := azdClient.Deployment().GetDeploymentContext(ctx, &azdext.EmptyRequest{})
-				if err == nil {
-					scopeMap := map[string]string{
-						"Tenant ID":       deploymentContextResponse.AzureContext.Scope.TenantId,
-						"Subscription ID": deploymentContextResponse.AzureContext.Scope.SubscriptionId,
-						"Location":        deploymentContextResponse.AzureContext.Scope.Location,
-						"Resource Group":  deploymentContextResponse.AzureContext.Scope.ResourceGroup,
-					}
-
-					color.Cyan("Deployment Context:")
-					for key, value := range scopeMap {
-						if value == "" {
-							value = "N/A"
-						}
-
-						fmt.Printf("%s: %s\n", color.HiWhiteString(key), value)
-					}
-					fmt.Println()
-
-					color.Cyan("Provisioned Azure Resources:")
-					for _, resourceId := range deploymentContextResponse.AzureContext.Resources {
-						resource, err := arm.ParseResourceID(resourceId)
-						if err == nil {
-							fmt.Printf(
-								"- %s (%s)\n",
-								resource.Name,
-								color.HiBlackString(resource.ResourceType.String()),
-							)
-						}
-					}
-					fmt.Println()
-				}
+// maskDifferences masks the secret values of environment differences. A value missing
+// from one environment stays empty, so differing secrets still show as differences.
+func maskDifferences(diffs []report.Difference) {
+	for i, d := range diffs {
+		if d.Kind != "value" || (!report.IsSecret(d.Name, d.A) && !report.IsSecret(d.Name, d.B)) {
+			continue
+		}
+		if d.A != "" {
+			diffs[i].A = report.Redacted
+		}
+		if d.B != "" {
+			diffs[i].B = report.Redacted
+		}
+	}
+}
+
+// getProjectContext collects the project, environment and deployment context from azd.
+// Missing parts are left empty rather than reported as errors.
+func getProjectContext(ctx context.Context, azdClient *azdext.AzdClient) *report.Context {
+	result := report.NewContext()
+
+	getConfigResponse, err := azdClient.UserConfig().Get(ctx, &azdext.GetUserConfigRequest{
+		Path: "",
+	})
+	if err == nil && getConfigResponse.Found {
+		var userConfig map[string]any
+		if err := json.Unmarshal(getConfigResponse.Value, &userConfig); err == nil {
+			result.UserConfig = userConfig
+		}
+	}
+
+	getProjectResponse, err := azdClient.Project().Get(ctx, &azdext.EmptyRequest{})
+	if err != nil {
+		return result
+	}
+	result.Project = &report.Project{
+		Name: getProjectResponse.Project.Name,
+		Path: getProjectResponse.Project.Path,
+	}
+
+	getEnvResponse, err := azdClient.Environment().GetCurrent(ctx, &azdext.EmptyRequest{})
+	if err != nil {
+		return result
+	}
+	result.Environment = getEnvResponse.Environment.Name
+
+	envListResponse, err := azdClient.Environment().List(ctx, &azdext.EmptyRequest{})
+	if err == nil {
+		for _, env := range envListResponse.Environments {
+			result.Environments = append(result.Environments, env.Name)
+		}
+	}
+
+	getValuesResponse, err := azdClient.Environment().GetValues(ctx, &azdext.GetEnvironmentRequest{
+		Name: result.Environment,
+	})
+	if err == nil {
+		result.Values = make(map[string]string, len(getValuesResponse.KeyValues))
+		for _, pair := range getValuesResponse.KeyValues {
+			result.Values[pair.Key] = pair.Value
+		}
+	}
+
+	deploymentContextResponse, err := azdClient.Deployment().GetDeploymentContext(ctx, &azdext.EmptyRequest{})
+	if err == nil {
+		scope := deploymentContextResponse.AzureContext.Scope
+		result.Deployment = &report.Deployment{
+			TenantID:       scope.TenantId,
+			SubscriptionID: scope.SubscriptionId,
+			Location:       scope.Location,
+			ResourceGroup:  scope.ResourceGroup,
+		}
+		for _, resourceId := range deploymentContextResponse.AzureContext.Resources {
+			if resource, ok := parseResource(resourceId); ok {
+				result.Resources = append(result.Resources, resource)
 			}
+		}
+	}
+
+	return result
+}
+
+// parseResource splits an Azure resource ID into the parts reported by `context`.
+func parseResource(resourceId string) (report.Resource, bool) {
+	resource, err := arm.ParseResourceID(resourceId)
+	if err != nil {
+		return report.Resource{}, false
+	}
+	return report.Resource{
+		ID:             resourceId,
+		Name:           resource.Name,
+		Type:           resource.ResourceType.String(),
+		SubscriptionID: resource.SubscriptionID,
+		ResourceGroup:  resource.ResourceGroupName,
+	}, true
+}
+
+// printProjectContext prints the context as text. hidden is the number of masked secret values.
+func printProjectContext(w io.Writer, c *report.Context, hidden int) {
+	if c.UserConfig != nil {
+		fmt.Fprintln(w, color.HiWhiteString("User Config"))
+		jsonBytes, err := json.MarshalIndent(c.UserConfig, "", "  ")
+		if err == nil {
+			fmt.Fprintln(w, string(jsonBytes))
+		}
+	}
 
-			if azdClient != nil {
-				azdClient.Close()
+	if c.Project == nil {
+		fmt.Fprintln(w, color.YellowString("WARNING: No azd project found in current working directory"))
+		fmt.Fprintf(w, "Run %s to create a new project.\n", color.CyanString("azd init"))
+		return
+	}
+
+	fmt.Fprintln(w, color.CyanString("Project:"))
+	fmt.Fprintf(w, "%s: %s\n", color.HiWhiteString("Name"), c.Project.Name)
+	fmt.Fprintf(w, "%s: %s\n", color.HiWhiteString("Path"), c.Project.Path)
+	fmt.Fprintln(w)
+
+	if c.Environment == "" {
+		fmt.Fprintln(w, color.YellowString("WARNING: No azd environment(s) found."))
+		fmt.Fprintf(w, "Run %s to create a new environment.\n", color.CyanString("azd env new"))
+		return
+	}
+
+	if len(c.Environments) == 0 {
+		fmt.Fprintln(w, "No environments found")
+	}
+
+	fmt.Fprintln(w, color.CyanString("Environments:"))
+	for _, env := range c.Environments {
+		envLine := env
+		if env == c.Environment {
+			envLine += color.HiWhiteString(" (selected)")
+		}
+
+		fmt.Fprintf(w, "- %s\n", envLine)
+	}
+	fmt.Fprintln(w)
+
+	if c.Values != nil {
+		fmt.Fprintln(w, color.CyanString("Environment values:"))
+		keys := make([]string, 0, len(c.Values))
+		for key := range c.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s: %s\n", color.HiWhiteString(key), color.HiBlackString(c.Values[key]))
+		}
+		if hidden > 0 {
+			fmt.Fprintf(w, "%s\n", color.HiBlackString("%d secret value(s) masked. Use --show-secrets to reveal them.", hidden))
+		}
+		fmt.Fprintln(w)
+	}
+
+	if c.Deployment != nil {
+		scope := []struct{ key, value string }{
+			{"Tenant ID", c.Deployment.TenantID},
+			{"Subscription ID", c.Deployment.SubscriptionID},
+			{"Location", c.Deployment.Location},
+			{"Resource Group", c.Deployment.ResourceGroup},
+		}
+
+		fmt.Fprintln(w, color.CyanString("Deployment Context:"))
+		for _, field := range scope {
+			value := field.value
+			if value == "" {
+				value = "N/A"
 			}
-			return nil
-		},
+
+			fmt.Fprintf(w, "%s: %s\n", color.HiWhiteString(field.key), value)
+		}
+		fmt.Fprintln(w)
+
+		fmt.Fprintln(w, color.CyanString("Provisioned Azure Resources:"))
+		for _, resource := range c.Resources {
+			fmt.Fprintf(w, "- %s (%s)\n", resource.Name, color.HiBlackString(resource.Type))
+		}
+		fmt.Fprintln(w)
 	}
 }
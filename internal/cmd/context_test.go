@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/report"
+)
+
+func TestPrintProjectContext_MaskedSecrets(t *testing.T) {
+	c := report.NewContext()
+	c.Project = &report.Project{Name: "todo", Path: "/src/todo"}
+	c.Environment = "dev"
+	c.Environments = []string{"dev"}
+	values, hidden := report.MaskSecrets(map[string]string{
+		"AZURE_LOCATION":     "eastus",
+		"SQL_ADMIN_PASSWORD": "hunter2!",
+	})
+	c.Values = values
+
+	var out bytes.Buffer
+	printProjectContext(&out, c, hidden)
+
+	assert.Contains(t, out.String(), "eastus")
+	assert.NotContains(t, out.String(), "hunter2!")
+	assert.Contains(t, out.String(), "1 secret value(s) masked. Use --show-secrets to reveal them.")
+
+	out.Reset()
+	c.Values = map[string]string{"SQL_ADMIN_PASSWORD": "hunter2!"}
+	printProjectContext(&out, c, 0)
+	assert.Contains(t, out.String(), "hunter2!", "--show-secrets prints the values")
+	assert.NotContains(t, out.String(), "--show-secrets")
+}
+
+func TestMaskDifferences(t *testing.T) {
+	diffs := []report.Difference{
+		{Kind: "scope", Name: "subscriptionId", A: "sub-1", B: "sub-2"},
+		{Kind: "value", Name: "AZURE_ENV_NAME", A: "dev", B: "prod"},
+		{Kind: "value", Name: "SQL_PASSWORD", A: "dev-pw", B: "prod-pw"},
+		{Kind: "value", Name: "STORAGE_KEY", A: "", B: "abc"},
+		{Kind: "value", Name: "DB_CONN", A: "akvs://sub/kv/db", B: "Server=db"},
+	}
+	maskDifferences(diffs)
+
+	assert.Equal(t, []report.Difference{
+		{Kind: "scope", Name: "subscriptionId", A: "sub-1", B: "sub-2"},
+		{Kind: "value", Name: "AZURE_ENV_NAME", A: "dev", B: "prod"},
+		{Kind: "value", Name: "SQL_PASSWORD", A: report.Redacted, B: report.Redacted},
+		{Kind: "value", Name: "STORAGE_KEY", A: "", B: report.Redacted},
+		{Kind: "value", Name: "DB_CONN", A: report.Redacted, B: report.Redacted},
+	}, diffs)
+
+	var out bytes.Buffer
+	printDifferences(&out, "Environments", "dev", "prod", diffs)
+	assert.Contains(t, out.String(), "KIND   NAME            FIELD  dev")
+	assert.NotContains(t, out.String(), "prod-pw")
+	assert.Contains(t, out.String(), "5 differences")
+
+	out.Reset()
+	printDifferences(&out, "Environments", "dev", "prod", nil)
+	assert.Contains(t, out.String(), "(no differences)")
+}
+
+func TestParseResource(t *testing.T) {
+	id := "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-todo-dev/providers/Microsoft.Web/sites/app-web"
+	resource, ok := parseResource(id)
+	require.True(t, ok)
+	assert.Equal(t, report.Resource{
+		ID:             id,
+		Name:           "app-web",
+		Type:           "Microsoft.Web/sites",
+		SubscriptionID: "00000000-0000-0000-0000-000000000001",
+		ResourceGroup:  "rg-todo-dev",
+	}, resource)
+
+	_, ok = parseResource("not-a-resource-id")
+	assert.False(t, ok)
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/azdext"
 	"github.com/spf13/cobra"
+	"spboyer.azd.doctor/internal/checks"
 )
 
+// DebugLogPath is the log written by the extension host, collected by `bundle`.
+var DebugLogPath = filepath.Join(os.TempDir(), "azd-doctor-debug.log")
+
 func debugLog(format string, args ...interface{}) {
-	f, _ := os.OpenFile("/tmp/azd-doctor-debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, _ := os.OpenFile(DebugLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if f != nil {
 		defer f.Close()
 		fmt.Fprintf(f, format+"\n", args...)
@@ -46,11 +51,13 @@ func RunExtensionHost(ctx context.Context) error {
 
 	debugLog("Registering event handlers")
 
+	handlers := &lifecycleHandlers{runner: &checks.RealRunner{}, session: checks.NewSession()}
+
 	// Use the ExtensionHost pattern which handles Ready() signaling automatically
 	host := azdext.NewExtensionHost(client).
-		WithServiceEventHandler("prepackage", onPrePackage, nil).
-		WithProjectEventHandler("preprovision", onPreProvision).
-		WithProjectEventHandler("predeploy", onPreDeploy)
+		WithServiceEventHandler("prepackage", handlers.onPrePackage, nil).
+		WithProjectEventHandler("preprovision", handlers.onPreProvision).
+		WithProjectEventHandler("predeploy", handlers.onPreDeploy)
 
 	debugLog("Event handlers registered:")
 	debugLog("  - service event: prepackage (no filters)")
@@ -69,7 +76,20 @@ func RunExtensionHost(ctx context.Context) error {
 	return nil
 }
 
-func onPrePackage(ctx context.Context, args *azdext.ServiceEventArgs) error {
+// lifecycleHandlers holds the state shared by the lifecycle event handlers of a single extension host.
+type lifecycleHandlers struct {
+	runner checks.Runner
+	// session keeps the satisfied tool checks across the events of the host, so a single
+	// `azd up` doesn't query the same tools for every event and service.
+	session *checks.Session
+}
+
+func (h *lifecycleHandlers) verifyOptions(targetCommand string) VerifyOptions {
+	// Default timeout for auth check in lifecycle events
+	return VerifyOptions{Runner: h.runner, Command: targetCommand, AuthTimeout: 5 * time.Second, Session: h.session}
+}
+
+func (h *lifecycleHandlers) onPrePackage(ctx context.Context, args *azdext.ServiceEventArgs) error {
 	debugLog("====== onPrePackage CALLED ======")
 	debugLog("Service Name: %s", args.Service.Name)
 	debugLog("Service Host: %s", args.Service.Host)
@@ -79,7 +99,9 @@ func onPrePackage(ctx context.Context, args *azdext.ServiceEventArgs) error {
 	// Write to stderr so azd shows it
 	fmt.Fprintf(os.Stderr, "\n[azd doctor] Verifying environment for packaging service: %s\n", args.Service.Name)
 
-	err := RunVerify(ctx, "package", 5*time.Second)
+	opts := h.verifyOptions("package")
+	opts.Service = args.Service.Name
+	err := RunVerify(ctx, opts)
 	if err != nil {
 		debugLog("onPrePackage RunVerify returned error: %v", err)
 		fmt.Fprintf(os.Stderr, "[azd doctor] Verification failed: %v\n\n", err)
@@ -90,12 +112,11 @@ func onPrePackage(ctx context.Context, args *azdext.ServiceEventArgs) error {
 	return err
 }
 
-func onPreProvision(ctx context.Context, args *azdext.ProjectEventArgs) error {
+func (h *lifecycleHandlers) onPreProvision(ctx context.Context, args *azdext.ProjectEventArgs) error {
 	debugLog("onPreProvision called for project: %s", args.Project.Name)
 	fmt.Fprintf(os.Stderr, "\n[azd doctor] Verifying environment for provisioning\n")
 
-	// Default timeout for auth check in lifecycle events
-	err := RunVerify(ctx, "provision", 5*time.Second)
+	err := RunVerify(ctx, h.verifyOptions("provision"))
 	if err != nil {
 		debugLog("onPreProvision RunVerify returned error: %v", err)
 		fmt.Fprintf(os.Stderr, "[azd doctor] Verification failed: %v\n\n", err)
@@ -106,11 +127,11 @@ func onPreProvision(ctx context.Context, args *azdext.ProjectEventArgs) error {
 	return err
 }
 
-func onPreDeploy(ctx context.Context, args *azdext.ProjectEventArgs) error {
+func (h *lifecycleHandlers) onPreDeploy(ctx context.Context, args *azdext.ProjectEventArgs) error {
 	debugLog("onPreDeploy called for project: %s", args.Project.Name)
 	fmt.Fprintf(os.Stderr, "\n[azd doctor] Verifying environment for deployment\n")
 
-	err := RunVerify(ctx, "deploy", 5*time.Second)
+	err := RunVerify(ctx, h.verifyOptions("deploy"))
 	if err != nil {
 		debugLog("onPreDeploy RunVerify returned error: %v", err)
 		fmt.Fprintf(os.Stderr, "[azd doctor] Verification failed: %v\n\n", err)
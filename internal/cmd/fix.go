@@ -0,0 +1,493 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"spboyer.azd.doctor/internal/checks"
+)
+
+// FixOptions configures RunFix.
+type FixOptions struct {
+	Runner checks.Runner
+	// Command is the azd command whose requirements are fixed (default up).
+	Command     string
+	Service     string
+	AuthTimeout time.Duration
+	Concurrency int
+	// Yes applies the planned fixes without asking for confirmation.
+	Yes bool
+	// In is read for the confirmation; defaults to os.Stdin.
+	In io.Reader
+	// DaemonTimeout is how long to wait for a started container daemon (default 60s).
+	DaemonTimeout time.Duration
+}
+
+// daemonPollInterval is how often a started container daemon is polled.
+var daemonPollInterval = 2 * time.Second
+
+func NewFixCommand(runner checks.Runner) *cobra.Command {
+	var opts FixOptions
+
+	fixCmd := &cobra.Command{
+		Use:   "fix",
+		Short: "Fix failed checks automatically",
+		Long: `Runs the checks for the target command and fixes the failures that have a known remediation:
+enabling remote build, starting the Docker/Podman daemon, installing or upgrading required azd
+extensions, logging in with 'azd auth login', installing a missing hook shell (bash, pwsh) and
+pinning hooks that use an unsupported shell to sh.
+The planned fixes are shown and confirmed before they are applied, then the affected checks are re-run.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Runner = runner
+			return RunFix(cmd.Context(), opts)
+		},
+	}
+
+	fixCmd.Flags().StringVar(&opts.Command, "command", "up", "The azd command to fix requirements for (up, package, provision, deploy)")
+	fixCmd.Flags().StringVar(&opts.Service, "service", "", "Limit service requirements to a single service")
+	fixCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Apply the fixes without asking for confirmation")
+	fixCmd.Flags().DurationVar(&opts.AuthTimeout, "auth-timeout", 5*time.Second, "Timeout for the auth check")
+	fixCmd.Flags().IntVar(&opts.Concurrency, "concurrency", checks.DefaultConcurrency, "Maximum number of checks to run in parallel")
+
+	return fixCmd
+}
+
+// fixAction is an automated remediation for one or more failed checks.
+type fixAction struct {
+	// checkIDs are the checks fixed by the action; they are re-run after it is applied.
+	checkIDs    []string
+	description string
+	// command is the command line run by the action, shown in the plan.
+	command string
+	apply   func(ctx context.Context) error
+}
+
+// fixer plans and applies fixes for a project.
+type fixer struct {
+	opts        FixOptions
+	goos        string
+	projectFile string
+	config      *checks.AzureYaml
+}
+
+func RunFix(ctx context.Context, opts FixOptions) error {
+	return runFixWithOS(ctx, opts, runtime.GOOS)
+}
+
+func runFixWithOS(ctx context.Context, opts FixOptions, goos string) error {
+	if opts.Command == "" {
+		opts.Command = "up"
+	}
+	if opts.AuthTimeout == 0 {
+		opts.AuthTimeout = 5 * time.Second
+	}
+	if opts.DaemonTimeout == 0 {
+		opts.DaemonTimeout = 60 * time.Second
+	}
+	if opts.In == nil {
+		opts.In = os.Stdin
+	}
+	switch opts.Command {
+	case "up", "package", "provision", "deploy":
+	default:
+		return fmt.Errorf("invalid command target: %s. Must be one of: up, package, provision, deploy", opts.Command)
+	}
+
+	f := &fixer{opts: opts, goos: goos}
+	if projectFile, found := findProjectFile(); found {
+		config, err := checks.LoadProjectConfig(projectFile)
+		if err != nil {
+			return fmt.Errorf("failed to load project config: %w", err)
+		}
+		f.projectFile, f.config = projectFile, config
+	}
+
+	printRunning("Checking for", opts.Command)
+	plan, results := f.runChecks(ctx)
+	auth := f.checkAuth(ctx)
+
+	actions := f.planActions(plan, results, auth)
+	fixed := make(map[string]bool)
+	for _, action := range actions {
+		for _, id := range action.checkIDs {
+			fixed[id] = true
+		}
+	}
+
+	// Failures without an automated fix are listed with their remediation.
+	var manual []checks.CheckResult
+	for _, req := range plan.Requirements {
+		if res := results[req.Check.ID()]; res.Failed() && !fixed[req.Check.ID()] {
+			manual = append(manual, res)
+		}
+	}
+	if len(manual) > 0 {
+		fmt.Fprintln(getOutputWriter())
+		printRunning("Manual fixes", fmt.Sprintf("%d failed checks have no automated fix", len(manual)))
+		for _, res := range manual {
+			printResult(res)
+		}
+	}
+
+	if len(actions) == 0 {
+		fmt.Fprintln(getOutputWriter())
+		printSuccess("Fix", "Nothing to fix automatically")
+		return nil
+	}
+
+	w := getOutputWriter()
+	fmt.Fprintf(w, "\nPlanned fixes:\n")
+	for i, action := range actions {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, action.description)
+		if action.command != "" {
+			fmt.Fprintf(w, "     %s\n", color.CyanString(action.command))
+		}
+	}
+
+	if !opts.Yes && !confirm(opts.In, fmt.Sprintf("\nApply %d fixes?", len(actions))) {
+		fmt.Fprintln(w, "No changes made.")
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	var errs []error
+	for _, action := range actions {
+		printRunning("Fixing", action.description)
+		if err := action.apply(ctx); err != nil {
+			printFailure(action.description, err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", action.description, err))
+			continue
+		}
+		printSuccess(action.description, "Applied")
+	}
+
+	// Re-run the affected checks against the updated project.
+	fmt.Fprintln(w)
+	printRunning("Re-running", "affected checks")
+	if f.projectFile != "" {
+		config, err := checks.LoadProjectConfig(f.projectFile)
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("failed to load project config: %w", err))...)
+		}
+		f.config = config
+	}
+	_, results = f.runChecks(ctx)
+	if fixed["azd-auth"] {
+		results["azd-auth"] = f.checkAuth(ctx)
+	}
+	for _, action := range actions {
+		for _, id := range action.checkIDs {
+			res, ok := results[id]
+			if !ok {
+				// e.g. docker is no longer required once remote build is enabled.
+				printSuccess(id, "No longer required")
+				continue
+			}
+			printResult(res)
+			if res.Failed() {
+				errs = append(errs, fmt.Errorf("%s still fails after fix", res.Name))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runChecks runs the requirement plan and returns the results keyed by check ID.
+func (f *fixer) runChecks(ctx context.Context) (*checks.Plan, map[string]checks.CheckResult) {
+	planner := checks.NewPlannerWithOS(checks.DefaultRegistryWithOS(f.goos), f.goos)
+	plan := planner.Plan(f.config, checks.PlanOptions{Command: f.opts.Command, Service: f.opts.Service})
+	return plan, runPlan(ctx, checks.NewExecutor(f.opts.Runner, f.opts.Concurrency), plan)
+}
+
+func (f *fixer) checkAuth(ctx context.Context) checks.CheckResult {
+	authCtx, cancel := context.WithTimeout(ctx, f.opts.AuthTimeout)
+	defer cancel()
+	return checks.CheckAzdLogin(authCtx, f.opts.Runner, nil)
+}
+
+// planActions returns the fixes for the failed checks, in the order they are applied.
+func (f *fixer) planActions(plan *checks.Plan, results map[string]checks.CheckResult, auth checks.CheckResult) []fixAction {
+	var actions []fixAction
+
+	if auth.Failed() {
+		actions = append(actions, f.commandAction([]string{"azd-auth"}, "Log in to Azure", "azd", "auth", "login"))
+	}
+
+	// Container runtime: start a stopped daemon, or build remotely without a local runtime.
+	if docker, ok := results["docker"]; ok && docker.Failed() && !docker.Installed {
+		if f.config != nil && len(f.config.LocalBuildServices()) > 0 {
+			actions = append(actions, f.remoteBuildAction())
+		}
+	} else if daemon, ok := results["docker-daemon"]; ok && daemon.Failed() && daemon.Installed && !daemon.Running {
+		if action, ok := f.startDaemonAction(results["docker"].Name); ok {
+			actions = append(actions, action)
+		} else if f.config != nil && len(f.config.LocalBuildServices()) > 0 {
+			actions = append(actions, f.remoteBuildAction())
+		}
+	}
+
+	for _, req := range plan.Requirements {
+		id := req.Check.ID()
+		res := results[id]
+		switch {
+		case strings.HasPrefix(id, "extension:") && res.Failed():
+			if action, ok := f.extensionAction(strings.TrimPrefix(id, "extension:"), res); ok {
+				actions = append(actions, action)
+			}
+		case (id == "bash" || id == "pwsh") && res.Failed() && !res.Installed:
+			if action, ok := f.installShellAction(id, res); ok {
+				actions = append(actions, action)
+			}
+		case strings.HasPrefix(id, "hook-shell:") && res.Status() == checks.SeverityWarn:
+			shell := strings.TrimPrefix(id, "hook-shell:")
+			if action, ok := f.pinShellAction(id, shell); ok {
+				actions = append(actions, action)
+			}
+		}
+	}
+
+	return actions
+}
+
+// commandAction runs a command attached to the terminal.
+func (f *fixer) commandAction(checkIDs []string, description, name string, args ...string) fixAction {
+	return fixAction{
+		checkIDs:    checkIDs,
+		description: description,
+		command:     strings.Join(append([]string{name}, args...), " "),
+		apply: func(ctx context.Context) error {
+			return checks.RunInteractive(ctx, f.opts.Runner, name, args...)
+		},
+	}
+}
+
+func (f *fixer) remoteBuildAction() fixAction {
+	return fixAction{
+		checkIDs:    []string{"docker", "docker-daemon"},
+		description: fmt.Sprintf("Enable remote build for %s", strings.Join(f.config.LocalBuildServices(), ", ")),
+		command:     "azd doctor configure remote-build",
+		apply: func(context.Context) error {
+			_, err := enableRemoteBuild(f.projectFile)
+			return err
+		},
+	}
+}
+
+// daemonStartCommand returns the command that starts the daemon of a container runtime on goos.
+func daemonStartCommand(runtimeName, goos string) ([]string, bool) {
+	switch {
+	case runtimeName == "docker" && goos == "linux":
+		return []string{"systemctl", "start", "docker"}, true
+	case runtimeName == "docker" && goos == "darwin":
+		return []string{"open", "-a", "Docker"}, true
+	case runtimeName == "docker" && goos == "windows":
+		return []string{"powershell", "-NoProfile", "-Command", `Start-Process "$env:ProgramFiles\Docker\Docker\Docker Desktop.exe"`}, true
+	case runtimeName == "podman" && goos == "linux":
+		// Rootless podman on Linux serves the API through a user socket.
+		return []string{"systemctl", "--user", "start", "podman.socket"}, true
+	case runtimeName == "podman" && (goos == "darwin" || goos == "windows"):
+		return []string{"podman", "machine", "start"}, true
+	}
+	return nil, false
+}
+
+// startDaemonAction starts the container daemon and waits until it responds.
+func (f *fixer) startDaemonAction(runtimeName string) (fixAction, bool) {
+	command, ok := daemonStartCommand(runtimeName, f.goos)
+	if !ok {
+		return fixAction{}, false
+	}
+	action := f.commandAction([]string{"docker-daemon"}, fmt.Sprintf("Start the %s daemon", runtimeName), command[0], command[1:]...)
+	start := action.apply
+	action.apply = func(ctx context.Context) error {
+		if err := start(ctx); err != nil {
+			return err
+		}
+		// Docker Desktop and podman machines take a while to accept connections.
+		deadline := time.Now().Add(f.opts.DaemonTimeout)
+		for {
+			if err := f.opts.Runner.Run(runtimeName, "info"); err == nil {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("%s daemon did not start within %s", runtimeName, f.opts.DaemonTimeout)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(daemonPollInterval):
+			}
+		}
+	}
+	return action, true
+}
+
+// extensionAction installs a missing extension or upgrades an outdated one. An exact
+// required version (e.g. "1.2.0") is passed to azd; a range installs the latest version.
+func (f *fixer) extensionAction(id string, res checks.CheckResult) (fixAction, bool) {
+	if f.config == nil {
+		return fixAction{}, false
+	}
+	requiredRange := strings.TrimSpace(f.config.RequiredVersions.Extensions[id])
+	if requiredRange != "" {
+		if _, err := semver.ParseRange(requiredRange); err != nil {
+			// An invalid range in azure.yaml can't be fixed by installing anything.
+			return fixAction{}, false
+		}
+	}
+
+	verb, description := "upgrade", fmt.Sprintf("Upgrade extension %s", id)
+	if !res.Installed {
+		verb, description = "install", fmt.Sprintf("Install extension %s", id)
+	}
+	args := []string{"extension", verb, id}
+	if v, err := semver.Parse(strings.TrimSpace(strings.TrimPrefix(requiredRange, "="))); err == nil {
+		args = append(args, "--version", v.String())
+	}
+	return f.commandAction([]string{"extension:" + id}, description, "azd", args...), true
+}
+
+// installShellAction installs a missing hook shell with the install command resolved for
+// the machine's package managers. The command may chain steps, so it runs through the
+// system shell (cmd on Windows, sh elsewhere).
+func (f *fixer) installShellAction(id string, res checks.CheckResult) (fixAction, bool) {
+	if res.Install == "" {
+		return fixAction{}, false
+	}
+	shell := []string{"sh", "-c"}
+	if f.goos == "windows" {
+		shell = []string{"cmd", "/c"}
+	}
+	action := f.commandAction([]string{id}, fmt.Sprintf("Install %s for hooks", id), shell[0], shell[1], res.Install)
+	action.command = res.Install
+	return action, true
+}
+
+// pinShellAction sets `shell: sh` on the hooks using an unsupported shell. A missing bash
+// isn't pinned: azd runs sh hooks through bash too.
+// Windows hooks are left alone since sh isn't available there and rewriting bash
+// scripts for PowerShell can't be done automatically.
+func (f *fixer) pinShellAction(checkID, shell string) (fixAction, bool) {
+	if f.config == nil || f.goos == "windows" {
+		return fixAction{}, false
+	}
+	hooks := f.hooksUsingShell(shell)
+	if len(hooks) == 0 {
+		return fixAction{}, false
+	}
+
+	names := make([]string, 0, len(hooks))
+	for _, h := range hooks {
+		names = append(names, h.String())
+	}
+	return fixAction{
+		checkIDs:    []string{checkID},
+		description: fmt.Sprintf("Pin hooks %s to shell sh", strings.Join(names, ", ")),
+		apply: func(context.Context) error {
+			_, err := updateProjectFile(f.projectFile, func(root *yaml.Node) (bool, error) {
+				updated := false
+				for _, h := range hooks {
+					if setHookShell(root, h.service, h.name, "sh") {
+						updated = true
+					}
+				}
+				return updated, nil
+			})
+			return err
+		},
+	}, true
+}
+
+// hookName identifies a hook in azure.yaml; service is empty for project hooks.
+type hookName struct {
+	service string
+	name    string
+}
+
+func (h hookName) String() string {
+	if h.service == "" {
+		return h.name
+	}
+	return h.service + "/" + h.name
+}
+
+// hooksUsingShell returns the hooks of the target command that use the given shell, sorted.
+func (f *fixer) hooksUsingShell(shell string) []hookName {
+	var list []hookName
+	add := func(service string, hooks checks.Hooks) {
+		names := make([]string, 0, len(hooks))
+		for name, hook := range hooks {
+			if hook.Shell == shell {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			list = append(list, hookName{service: service, name: name})
+		}
+	}
+	add("", f.config.Hooks.ForCommand(f.opts.Command))
+	for _, name := range f.config.ServiceNames() {
+		if f.opts.Service == "" || name == f.opts.Service {
+			add(name, f.config.Services[name].Hooks.ForCommand(f.opts.Command))
+		}
+	}
+	return list
+}
+
+// setHookShell sets the shell of a hook in the parsed azure.yaml.
+func setHookShell(root *yaml.Node, service, hook, shell string) bool {
+	path := []string{"hooks", hook, "shell"}
+	if service != "" {
+		path = append([]string{"services", service}, path...)
+	}
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, key := range path {
+		node = mappingValue(node, key)
+		if node == nil {
+			return false
+		}
+	}
+	if node.Value == shell {
+		return false
+	}
+	node.Value = shell
+	return true
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// confirm asks a yes/no question, defaulting to no.
+func confirm(in io.Reader, question string) bool {
+	fmt.Fprintf(getOutputWriter(), "%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/checks"
+)
+
+// fixEnv simulates a machine whose state changes when fixes are applied.
+type fixEnv struct {
+	loggedIn      bool
+	docker        bool
+	daemonRunning bool
+	noBash        bool
+	noPwsh        bool
+	extensions    map[string]string
+	commands      []string
+}
+
+func (e *fixEnv) runner() *MockRunner {
+	return &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			line := strings.Join(append([]string{name}, args...), " ")
+			switch {
+			case line == "azd auth login --check-status":
+				if !e.loggedIn {
+					return nil, fmt.Errorf("exit status 1")
+				}
+				return []byte("Logged in to Azure"), nil
+			case strings.HasPrefix(line, "azd extension list"):
+				list := []checks.AzdExtension{}
+				for id, version := range e.extensions {
+					list = append(list, checks.AzdExtension{Id: id, Name: id, Version: version})
+				}
+				return json.Marshal(list)
+			case name == "azd":
+				return []byte("azd version 1.10.0 (commit 1234567)"), nil
+			case name == "docker":
+				if !e.docker {
+					return nil, fmt.Errorf("executable file not found in $PATH")
+				}
+				return []byte("Docker version 24.0.0, build 1234"), nil
+			case name == "bash" && e.noBash, name == "pwsh" && e.noPwsh:
+				return nil, fmt.Errorf("executable file not found in $PATH")
+			case name == "podman":
+				return nil, fmt.Errorf("executable file not found in $PATH")
+			case name == "node":
+				return []byte("v20.0.0"), nil
+			}
+			return []byte("1.0.0"), nil
+		},
+		RunFunc: func(name string, args ...string) error {
+			line := strings.Join(append([]string{name}, args...), " ")
+			switch line {
+			case "docker info":
+				if !e.daemonRunning {
+					return fmt.Errorf("Cannot connect to the Docker daemon")
+				}
+				return nil
+			}
+			e.commands = append(e.commands, line)
+			switch {
+			case line == "azd auth login":
+				e.loggedIn = true
+			case line == "systemctl start docker":
+				e.daemonRunning = true
+			case line == "sh -c sudo apt-get install -y bash":
+				e.noBash = false
+			case line == "sh -c sudo snap install powershell --classic":
+				e.noPwsh = false
+			case strings.HasPrefix(line, "azd extension install "), strings.HasPrefix(line, "azd extension upgrade "):
+				version := "9.9.9"
+				if i := strings.Index(line, "--version "); i >= 0 {
+					version = line[i+len("--version "):]
+				}
+				e.extensions[args[2]] = version
+			}
+			return nil
+		},
+	}
+}
+
+func writeProject(t *testing.T, content string) string {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "azure.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	cwd, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(cwd) })
+	require.NoError(t, os.Chdir(tmpDir))
+	return path
+}
+
+func TestRunFix(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  string
+		env      fixEnv
+		command  string
+		commands []string
+		contains []string
+	}{
+		{
+			name:     "Log in",
+			project:  "name: test\n",
+			env:      fixEnv{docker: true, daemonRunning: true},
+			commands: []string{"azd auth login"},
+		},
+		{
+			name: "Start the docker daemon",
+			project: `name: test
+services:
+  api:
+    language: js
+    host: containerapp
+`,
+			env:      fixEnv{loggedIn: true, docker: true},
+			commands: []string{"systemctl start docker"},
+		},
+		{
+			name: "Enable remote build without docker",
+			project: `name: test
+services:
+  api:
+    language: js
+    host: containerapp
+`,
+			env:      fixEnv{loggedIn: true},
+			contains: []string{"remoteBuild: true"},
+		},
+		{
+			name: "Install and upgrade extensions",
+			project: `name: test
+requiredVersions:
+  extensions:
+    a.ext: "1.2.0"
+    b.ext: ">= 2.0.0"
+`,
+			env:      fixEnv{loggedIn: true, docker: true, daemonRunning: true, extensions: map[string]string{"b.ext": "1.0.0"}},
+			commands: []string{"azd extension install a.ext --version 1.2.0", "azd extension upgrade b.ext"},
+		},
+		{
+			name: "Pin unsupported hook shell",
+			project: `name: test
+hooks:
+  preprovision:
+    shell: zsh
+    run: ./setup.sh
+`,
+			env:      fixEnv{loggedIn: true, docker: true, daemonRunning: true},
+			command:  "provision",
+			contains: []string{"shell: sh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeProject(t, tt.project)
+			env := tt.env
+			if env.extensions == nil {
+				env.extensions = map[string]string{}
+			}
+
+			err := runFixWithOS(context.Background(), FixOptions{
+				Runner:        env.runner(),
+				Command:       tt.command,
+				Yes:           true,
+				AuthTimeout:   time.Second,
+				DaemonTimeout: time.Second,
+			}, "linux")
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.commands, env.commands)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, string(data), s)
+			}
+		})
+	}
+}
+
+func TestRunFix_MissingShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		shell    string
+		env      fixEnv
+		commands []string
+	}{
+		{
+			name:     "Install bash",
+			shell:    "bash",
+			env:      fixEnv{loggedIn: true, docker: true, daemonRunning: true, noBash: true},
+			commands: []string{"sh -c sudo apt-get install -y bash"},
+		},
+		{
+			name:     "Install pwsh",
+			shell:    "pwsh",
+			env:      fixEnv{loggedIn: true, docker: true, daemonRunning: true, noPwsh: true},
+			commands: []string{"sh -c sudo snap install powershell --classic"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := fmt.Sprintf("name: test\nhooks:\n  preprovision:\n    shell: %s\n    run: ./setup\n", tt.shell)
+			path := writeProject(t, content)
+
+			err := runFixWithOS(context.Background(), FixOptions{
+				Runner:      tt.env.runner(),
+				Command:     "provision",
+				Yes:         true,
+				AuthTimeout: time.Second,
+			}, "linux")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.commands, tt.env.commands)
+
+			// sh hooks run through bash as well, so the hooks aren't pinned to sh.
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data), "azure.yaml is left alone")
+		})
+	}
+}
+
+func TestRunFix_Declined(t *testing.T) {
+	content := `name: test
+services:
+  api:
+    language: js
+    host: containerapp
+`
+	path := writeProject(t, content)
+	env := fixEnv{}
+
+	err := runFixWithOS(context.Background(), FixOptions{
+		Runner:      env.runner(),
+		In:          strings.NewReader("n\n"),
+		AuthTimeout: time.Second,
+	}, "linux")
+	assert.NoError(t, err)
+	assert.Empty(t, env.commands, "no fixes run without confirmation")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func TestRunFix_StillFailing(t *testing.T) {
+	writeProject(t, "name: test\nservices:\n  api:\n    language: js\n    host: containerapp\n")
+	env := fixEnv{loggedIn: true, docker: true}
+	runner := env.runner()
+	run := runner.RunFunc
+	runner.RunFunc = func(name string, args ...string) error {
+		if name == "systemctl" {
+			// The daemon fails to start.
+			return nil
+		}
+		return run(name, args...)
+	}
+
+	daemonPollInterval = time.Millisecond
+	defer func() { daemonPollInterval = 2 * time.Second }()
+
+	err := runFixWithOS(context.Background(), FixOptions{
+		Runner:        runner,
+		Yes:           true,
+		AuthTimeout:   time.Second,
+		DaemonTimeout: 10 * time.Millisecond,
+	}, "linux")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "docker daemon did not start")
+	assert.Contains(t, err.Error(), "still fails after fix")
+}
+
+func TestDaemonStartCommand(t *testing.T) {
+	tests := []struct {
+		runtime  string
+		goos     string
+		expected string
+	}{
+		{runtime: "docker", goos: "linux", expected: "systemctl start docker"},
+		{runtime: "docker", goos: "darwin", expected: "open -a Docker"},
+		{runtime: "docker", goos: "windows", expected: "powershell -NoProfile -Command Start-Process \"$env:ProgramFiles\\Docker\\Docker\\Docker Desktop.exe\""},
+		{runtime: "podman", goos: "linux", expected: "systemctl --user start podman.socket"},
+		{runtime: "podman", goos: "darwin", expected: "podman machine start"},
+		{runtime: "podman", goos: "windows", expected: "podman machine start"},
+		{runtime: "docker", goos: "freebsd", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runtime+"/"+tt.goos, func(t *testing.T) {
+			command, ok := daemonStartCommand(tt.runtime, tt.goos)
+			assert.Equal(t, tt.expected != "", ok)
+			assert.Equal(t, tt.expected, strings.Join(command, " "))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"spboyer.azd.doctor/internal/checks"
+	"spboyer.azd.doctor/internal/report"
+)
+
+// Output formats supported by --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	// outputYAML and outputTable are only supported by `context`.
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+func validateOutput(format string) error {
+	switch format {
+	case outputText, outputJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid output format: %s. Must be one of: %s, %s", format, outputText, outputJSON)
+}
+
+func validateContextOutput(format string) error {
+	switch format {
+	case outputText, outputJSON, outputYAML, outputTable:
+		return nil
+	}
+	return fmt.Errorf("invalid output format: %s. Must be one of: %s, %s, %s, %s", format, outputText, outputJSON, outputYAML, outputTable)
+}
+
+// Report file formats supported by --format.
+const (
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+	formatJUnit = "junit"
+)
+
+func validateFormat(format string) error {
+	switch format {
+	case formatJSON, formatSARIF, formatJUnit:
+		return nil
+	}
+	return fmt.Errorf("invalid report format: %s. Must be one of: %s, %s, %s", format, formatJSON, formatSARIF, formatJUnit)
+}
+
+// reporter prints results as they are produced in text mode, or collects them
+// into a report.Report that is written once the command finishes in JSON mode.
+// Independently of the output format, the report can also be written to a file.
+type reporter struct {
+	format string
+	out    io.Writer
+	report *report.Report
+
+	file       string
+	fileFormat string
+}
+
+func newReporter(command, format string, out io.Writer) (*reporter, error) {
+	if format == "" {
+		format = outputText
+	}
+	if err := validateOutput(format); err != nil {
+		return nil, err
+	}
+	return &reporter{format: format, out: out, report: report.New(command)}, nil
+}
+
+// writeFile also writes the report to path in the given format (json, sarif, junit)
+// when the command finishes.
+func (r *reporter) writeFile(path, format string) error {
+	if path == "" {
+		if format != "" {
+			return fmt.Errorf("--format requires --output-file")
+		}
+		return nil
+	}
+	if format == "" {
+		format = formatJSON
+	}
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+	r.file, r.fileFormat = path, format
+	return nil
+}
+
+func (r *reporter) isText() bool {
+	return r.format == outputText
+}
+
+// section starts a new block of text output.
+func (r *reporter) section(title, details string) {
+	if r.isText() {
+		fmt.Fprintln(getOutputWriter())
+		printRunning(title, details)
+	}
+}
+
+func (r *reporter) running(message, details string) {
+	if r.isText() {
+		printRunning(message, details)
+	}
+}
+
+// result records a check result. id overrides the result ID for results not run by the executor.
+func (r *reporter) result(id string, category checks.Category, services []string, res checks.CheckResult) {
+	r.report.Add(id, category, services, res)
+	if r.isText() {
+		printResult(res)
+	}
+}
+
+// requirement records the result of a planned requirement, attributing it to the
+// requirement's services and azure.yaml location.
+func (r *reporter) requirement(req checks.Requirement, res checks.CheckResult) {
+	if res.Location == nil {
+		res.Location = req.Location
+	}
+	r.result("", req.Check.Category(), req.Services, res)
+}
+
+// info records an informational result such as the project name.
+func (r *reporter) info(id string, category checks.Category, name, details string) {
+	r.result(id, category, nil, checks.CheckResult{Name: name, Version: details, Severity: checks.SeverityInfo})
+}
+
+// skip records that the command was bypassed.
+func (r *reporter) skip(message string) {
+	r.report.Skip(message)
+	if r.isText() {
+		printSuccess("Verification", message)
+	}
+}
+
+// finish writes the requested reports and returns err unchanged.
+func (r *reporter) finish(err error) error {
+	if r.isText() && r.file == "" {
+		return err
+	}
+	r.report.Finish(err)
+
+	if !r.isText() {
+		if writeErr := r.report.Write(r.out); writeErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to write report: %w", writeErr))
+		}
+	}
+	if r.file != "" {
+		if writeErr := r.writeReportFile(); writeErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to write report file: %w", writeErr))
+		}
+	}
+	return err
+}
+
+func (r *reporter) writeReportFile() error {
+	f, err := os.Create(r.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch r.fileFormat {
+	case formatSARIF:
+		err = r.report.WriteSARIF(f)
+	case formatJUnit:
+		err = r.report.WriteJUnit(f)
+	default:
+		err = r.report.Write(f)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/checks"
+	"spboyer.azd.doctor/internal/report"
+)
+
+func TestCheckCommand_JSONOutput(t *testing.T) {
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			switch name {
+			case "gh":
+				return nil, fmt.Errorf("executable file not found in $PATH")
+			case "node":
+				return []byte("v20.0.0"), nil
+			case "python", "python3":
+				return []byte("Python 3.12.0"), nil
+			case "func":
+				return []byte("4.0.5455"), nil
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	var out bytes.Buffer
+	cmd := NewCheckCommand(mockRunner)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--skip-auth", "--output", "json"})
+	require.NoError(t, cmd.Execute())
+
+	var doc report.Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc), out.String())
+	assert.Equal(t, report.SchemaVersion, doc.SchemaVersion)
+	assert.Equal(t, "check", doc.Command)
+	assert.Equal(t, checks.SeverityWarn, doc.Status, "missing gh is a warning")
+
+	byID := map[string]report.Check{}
+	for _, c := range doc.Checks {
+		byID[c.ID] = c
+	}
+	assert.Equal(t, "1.0.0", byID["azd"].Version)
+	assert.Equal(t, checks.SeverityWarn, byID["gh"].Status)
+	assert.NotEmpty(t, byID["gh"].Remediation)
+	assert.Equal(t, checks.SeverityInfo, byID["project-file"].Status)
+	assert.Equal(t, checks.SeveritySkip, byID["azd-auth"].Status)
+}
+
+func TestRunVerify_JSONOutput(t *testing.T) {
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			if name == "git" {
+				return nil, fmt.Errorf("executable file not found in $PATH")
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte("name: test-project\n"), 0644))
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	var out bytes.Buffer
+	err := RunVerify(context.Background(), VerifyOptions{
+		Runner:      mockRunner,
+		Command:     "provision",
+		AuthTimeout: 1 * time.Second,
+		Output:      outputJSON,
+		Out:         &out,
+	})
+	assert.Error(t, err)
+
+	var doc report.Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc), out.String())
+	assert.Equal(t, "verify", doc.Command)
+	assert.Equal(t, "provision", doc.Target)
+	assert.Equal(t, checks.SeverityFail, doc.Status)
+	assert.Contains(t, doc.Error, "required tool not found: git")
+}
+
+func TestRunVerify_ReportFileListsAllFailures(t *testing.T) {
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			if name == "git" || name == "node" {
+				return nil, fmt.Errorf("executable file not found in $PATH")
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	azureYaml := "name: test-project\nservices:\n  api:\n    language: js\n    host: appservice\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(azureYaml), 0644))
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	file := filepath.Join(tmpDir, "doctor.json")
+	err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "deploy", AuthTimeout: 1 * time.Second,
+		Format: formatJSON, OutputFile: file})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required tool not found: git")
+	assert.Contains(t, err.Error(), "required tool not found: node")
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	var doc report.Report
+	require.NoError(t, json.Unmarshal(data, &doc))
+	byID := make(map[string]report.Check)
+	for _, c := range doc.Checks {
+		byID[c.ID] = c
+	}
+	assert.Equal(t, checks.SeverityFail, byID["git"].Status)
+	assert.Equal(t, checks.SeverityFail, byID["node"].Status)
+	assert.Contains(t, byID, "azd-auth", "the auth check runs after a failed common check")
+}
+
+func TestRunVerify_JSONSkip(t *testing.T) {
+	os.Setenv("AZD_DOCTOR_SKIP_VERIFY", "true")
+	defer os.Unsetenv("AZD_DOCTOR_SKIP_VERIFY")
+
+	var out bytes.Buffer
+	err := RunVerify(context.Background(), VerifyOptions{Runner: &MockRunner{}, Output: outputJSON, Out: &out})
+	require.NoError(t, err)
+
+	var doc report.Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, checks.SeveritySkip, doc.Status)
+	assert.Equal(t, "Skipped by AZD_DOCTOR_SKIP_VERIFY", doc.Message)
+}
+
+func TestValidateOutput(t *testing.T) {
+	assert.NoError(t, validateOutput("text"))
+	assert.NoError(t, validateOutput("json"))
+	assert.Error(t, validateOutput("xml"))
+
+	err := RunVerify(context.Background(), VerifyOptions{Runner: &MockRunner{}, Output: "xml"})
+	assert.Error(t, err)
+}
+
+func TestValidateContextOutput(t *testing.T) {
+	for _, format := range []string{"text", "json", "yaml", "table"} {
+		assert.NoError(t, validateContextOutput(format))
+	}
+	assert.Error(t, validateContextOutput("xml"))
+	assert.Error(t, validateOutput("yaml"), "yaml is only supported by context")
+}
+
+func TestRunVerify_ReportFile(t *testing.T) {
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			if name == "node" {
+				return []byte("v20.0.0"), nil
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	azureYaml := "name: test-project\nservices:\n  api:\n    language: js\n    host: containerapps\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(azureYaml), 0644))
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	t.Run("SARIF points at azure.yaml", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "doctor.sarif")
+		err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "deploy", AuthTimeout: 1 * time.Second,
+			Format: formatSARIF, OutputFile: file})
+		require.NoError(t, err, "unknown hosts only warn")
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"ruleId": "host:api"`)
+		assert.Contains(t, string(data), `"uri": "azure.yaml"`)
+		assert.Contains(t, string(data), `"startLine": 5`)
+	})
+
+	t.Run("JUnit", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "doctor.xml")
+		err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "deploy", AuthTimeout: 1 * time.Second,
+			Format: formatJUnit, OutputFile: file})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `<testsuites name="azd doctor verify deploy"`)
+		assert.Contains(t, string(data), `<testcase name="host:api"`)
+	})
+
+	t.Run("Format requires an output file", func(t *testing.T) {
+		err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Format: formatSARIF})
+		assert.ErrorContains(t, err, "--output-file")
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Format: "html", OutputFile: "out.html"})
+		assert.ErrorContains(t, err, "invalid report format")
+	})
+}
@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"spboyer.azd.doctor/internal/checks"
 )
 
 func NewRootCommand() *cobra.Command {
@@ -21,11 +22,16 @@ func NewRootCommand() *cobra.Command {
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
 	rootCmd.PersistentFlags().Bool("debug", false, "Enable debug mode")
 
+	runner := &checks.RealRunner{}
+
 	rootCmd.AddCommand(newVersionCommand())
-	rootCmd.AddCommand(NewCheckCommand())
-	rootCmd.AddCommand(NewVerifyCommand())
+	rootCmd.AddCommand(NewCheckCommand(runner))
+	rootCmd.AddCommand(NewVerifyCommand(runner))
+	rootCmd.AddCommand(NewFixCommand(runner))
 	rootCmd.AddCommand(NewConfigureCommand())
 	rootCmd.AddCommand(newContextCommand())
+	rootCmd.AddCommand(newSnapshotCommand(runner))
+	rootCmd.AddCommand(newBundleCommand(runner))
 	rootCmd.AddCommand(NewListenCommand())
 
 	return rootCmd
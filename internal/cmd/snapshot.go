@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"spboyer.azd.doctor/internal/checks"
+	"spboyer.azd.doctor/internal/report"
+)
+
+// snapshotConfigKeys are the top-level azd user config sections recorded in a snapshot.
+// Other sections may hold personal or secret values and are left out.
+var snapshotConfigKeys = []string{"alpha", "auth", "cloud", "defaults", "platform"}
+
+func newSnapshotCommand(runner checks.Runner) *cobra.Command {
+	var outputFile string
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Write a JSON fingerprint of the environment",
+		Long: `Writes a JSON fingerprint of the environment: every detected tool with its version and
+resolved path, the OS and architecture, the installed azd extensions and the relevant azd config.
+Compare two snapshots with 'azd doctor snapshot diff a.json b.json'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot := collectSnapshot(cmd.Context(), runner, concurrency, runtime.GOOS, runtime.GOARCH)
+			if outputFile == "" {
+				return snapshot.Write(cmd.OutOrStdout())
+			}
+			f, err := os.Create(outputFile)
+			if err != nil {
+				return fmt.Errorf("failed to write snapshot: %w", err)
+			}
+			defer f.Close()
+			if err := snapshot.Write(f); err != nil {
+				return fmt.Errorf("failed to write snapshot: %w", err)
+			}
+			printSuccess("Snapshot", outputFile)
+			return f.Close()
+		},
+	}
+
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "Write the snapshot to a file instead of stdout")
+	cmd.Flags().IntVar(&concurrency, "concurrency", checks.DefaultConcurrency, "Maximum number of checks to run in parallel")
+	cmd.AddCommand(newSnapshotDiffCommand())
+
+	return cmd
+}
+
+func newSnapshotDiffCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "diff <a.json> <b.json>",
+		Short: "Show where two environment snapshots differ",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+			a, err := report.ReadSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+			b, err := report.ReadSnapshot(args[1])
+			if err != nil {
+				return err
+			}
+
+			diffs := report.DiffSnapshots(a, b)
+			if output == outputJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(diffs)
+			}
+			printDifferences(cmd.OutOrStdout(), "Snapshots", args[0], args[1], diffs)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "Output format (text, json)")
+
+	return cmd
+}
+
+// collectSnapshot runs every registered check and records the tools, extensions and
+// azd config of the machine.
+func collectSnapshot(ctx context.Context, runner checks.Runner, concurrency int, goos, goarch string) *report.Snapshot {
+	snapshot := report.NewSnapshot(goos, goarch)
+	snapshot.CreatedAt = time.Now().UTC()
+
+	list := checks.DefaultRegistryWithOS(goos).Checks()
+	for i, res := range checks.NewExecutor(runner, concurrency).Run(ctx, list) {
+		snapshot.AddTool(list[i].ID(), res)
+	}
+
+	if extensions, err := checks.GetInstalledExtensions(runner); err == nil {
+		for _, ext := range extensions {
+			snapshot.AddExtension(ext.Id, ext.Version)
+		}
+	}
+
+	if out, err := runner.Output("azd", "config", "show"); err == nil {
+		var config map[string]any
+		if err := json.Unmarshal(out, &config); err == nil {
+			snapshot.Config = make(map[string]string)
+			for _, key := range snapshotConfigKeys {
+				if value, ok := config[key]; ok {
+					flattenConfig(snapshot.Config, key, value)
+				}
+			}
+		}
+	}
+
+	return snapshot
+}
+
+// flattenConfig adds value to flat using dotted keys for nested objects.
+func flattenConfig(flat map[string]string, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			flattenConfig(flat, prefix+"."+key, nested)
+		}
+	case string:
+		flat[prefix] = v
+	default:
+		data, _ := json.Marshal(v)
+		flat[prefix] = string(data)
+	}
+}
+
+// printDifferences prints the differences between two snapshots or environments as a table.
+func printDifferences(w io.Writer, subject, nameA, nameB string, diffs []report.Difference) {
+	if len(diffs) == 0 {
+		fmt.Fprintf(w, "%s %s  %-20s  %s\n", color.GreenString("(✓)"), color.GreenString("Done   "),
+			subject, color.HiBlackString("(no differences)"))
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "KIND\tNAME\tFIELD\t%s\t%s\n", nameA, nameB)
+	for _, d := range diffs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Kind, d.Name, d.Field, orNone(d.A), orNone(d.B))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d differences\n", len(diffs))
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
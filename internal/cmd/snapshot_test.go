@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/report"
+)
+
+func TestCollectSnapshot(t *testing.T) {
+	runner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			switch {
+			case name == "git":
+				return []byte("git version 2.45.0"), nil
+			case name == "azd" && len(args) > 0 && args[0] == "extension":
+				return []byte(`[{"id":"microsoft.azd.extensions","version":"0.5.0"},{"id":"azure.ai.agents","version":"0.1.0"}]`), nil
+			case name == "azd" && len(args) > 0 && args[0] == "config":
+				return []byte(`{"defaults":{"location":"eastus","subscription":"sub"},"alpha":{"extensions":"on"},"personal":{"token":"secret"}}`), nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+	}
+
+	s := collectSnapshot(context.Background(), runner, 2, "linux", "amd64")
+
+	assert.Equal(t, "snapshot", s.Command)
+	assert.Equal(t, "linux", s.OS)
+	assert.Equal(t, "amd64", s.Arch)
+	assert.False(t, s.CreatedAt.IsZero())
+
+	ids := make([]string, len(s.Tools))
+	var git report.Tool
+	for i, tool := range s.Tools {
+		ids[i] = tool.ID
+		if tool.ID == "git" {
+			git = tool
+		}
+	}
+	assert.IsIncreasing(t, ids, "tools are sorted by ID")
+	assert.Equal(t, "2.45.0", git.Version)
+
+	assert.Equal(t, []report.Extension{
+		{ID: "azure.ai.agents", Version: "0.1.0"},
+		{ID: "microsoft.azd.extensions", Version: "0.5.0"},
+	}, s.Extensions)
+	assert.Equal(t, map[string]string{
+		"alpha.extensions":      "on",
+		"defaults.location":     "eastus",
+		"defaults.subscription": "sub",
+	}, s.Config, "only the relevant config sections are recorded")
+}
+
+func TestSnapshotDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, version string) string {
+		s := report.NewSnapshot("linux", "amd64")
+		s.AddExtension("microsoft.azd.extensions", version)
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, s.Write(f))
+		require.NoError(t, f.Close())
+		return path
+	}
+	a := write("a.json", "0.5.0")
+	b := write("b.json", "0.6.0")
+
+	var out bytes.Buffer
+	cmd := newSnapshotDiffCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{a, b})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "microsoft.azd.extensions")
+	assert.Contains(t, out.String(), "1 differences")
+
+	out.Reset()
+	cmd = newSnapshotDiffCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{a, b, "--output", "json"})
+	require.NoError(t, cmd.Execute())
+	var diffs []report.Difference
+	require.NoError(t, json.Unmarshal(out.Bytes(), &diffs))
+	assert.Equal(t, []report.Difference{
+		{Kind: "extension", Name: "microsoft.azd.extensions", A: "0.5.0", B: "0.6.0"},
+	}, diffs)
+}
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -12,9 +14,33 @@ import (
 	"spboyer.azd.doctor/internal/checks"
 )
 
-func NewVerifyCommand() *cobra.Command {
-	var targetCommand string
-	var authTimeout time.Duration
+// VerifyOptions configures a verification run.
+type VerifyOptions struct {
+	Runner      checks.Runner
+	Command     string
+	AuthTimeout time.Duration
+	// Service limits service requirements to a single service (e.g. for prepackage events).
+	Service string
+	// Environment is the azd environment to verify against; AZURE_ENV_NAME or the default environment if empty.
+	Environment string
+	// Concurrency is the maximum number of checks run in parallel (checks.DefaultConcurrency if zero).
+	Concurrency int
+	// Output is the output format (text or json); text if empty.
+	Output string
+	// Out receives the JSON report; os.Stdout if nil.
+	Out io.Writer
+	// OutputFile, if set, receives the report in Format (json, sarif or junit).
+	OutputFile string
+	Format     string
+	// NoCache disables the persistent tool version cache.
+	NoCache bool
+	// Session, if set, reuses the tool check results that didn't fail in earlier runs
+	// of the same process, such as the previous lifecycle events of the extension host.
+	Session *checks.Session
+}
+
+func NewVerifyCommand(runner checks.Runner) *cobra.Command {
+	opts := VerifyOptions{Runner: runner}
 
 	cmd := &cobra.Command{
 		Use:   "verify",
@@ -24,21 +50,49 @@ func NewVerifyCommand() *cobra.Command {
 This command performs strict checks for:
 - Required tools (azd, git)
 - Authentication status (must be logged in)
-- Project-specific requirements based on azure.yaml (languages, Docker, Functions Core Tools)
+- Project-specific requirements based on azure.yaml (languages, Docker, Functions Core Tools,
+  hook shells and required extensions), scoped to the target command
 
 It is automatically invoked by azd before 'up', 'package', 'provision', and 'deploy' commands, but can also be run manually for debugging.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return RunVerify(cmd.Context(), targetCommand, authTimeout)
+			opts.Out = cmd.OutOrStdout()
+			return RunVerify(cmd.Context(), opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&targetCommand, "command", "up", "The azd command to verify for (up, package, provision, deploy)")
-	cmd.Flags().DurationVar(&authTimeout, "auth-timeout", 5*time.Second, "Timeout for azd auth status check")
+	cmd.Flags().StringVar(&opts.Command, "command", "up", "The azd command to verify for (up, package, provision, deploy)")
+	cmd.Flags().DurationVar(&opts.AuthTimeout, "auth-timeout", 5*time.Second, "Timeout for azd auth status check")
+	cmd.Flags().StringVarP(&opts.Environment, "environment", "e", "", "The azd environment to verify against (defaults to AZURE_ENV_NAME or the default environment)")
+	cmd.Flags().IntVar(&opts.Concurrency, "concurrency", checks.DefaultConcurrency, "Maximum number of checks to run in parallel")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", outputText, "Output format (text, json)")
+	cmd.Flags().StringVar(&opts.Format, "format", "", "Report file format (json, sarif, junit); requires --output-file")
+	cmd.Flags().StringVar(&opts.OutputFile, "output-file", "", "Write the report to a file, in addition to the console output")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "Don't reuse cached tool versions")
 
 	return cmd
 }
 
-func RunVerify(ctx context.Context, targetCommand string, authTimeout time.Duration) error {
+func RunVerify(ctx context.Context, opts VerifyOptions) error {
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	rep, err := newReporter("verify", opts.Output, out)
+	if err != nil {
+		return err
+	}
+	if err := rep.writeFile(opts.OutputFile, opts.Format); err != nil {
+		return err
+	}
+	runner, saveCache := cachedRunner(opts.Runner, opts.NoCache)
+	defer saveCache()
+	opts.Runner = runner
+	return rep.finish(runVerify(ctx, opts, rep))
+}
+
+func runVerify(ctx context.Context, opts VerifyOptions, rep *reporter) error {
+	targetCommand := opts.Command
+
 	// Check for bypass environment variable
 	// AZD_DOCTOR_SKIP_VERIFY can be:
 	// - "true", "1", "all": Skip all verification
@@ -48,7 +102,7 @@ func RunVerify(ctx context.Context, targetCommand string, authTimeout time.Durat
 	if skipVerify != "" {
 		// Check for global skip
 		if skipVerify == "true" || skipVerify == "1" || skipVerify == "all" {
-			printSuccess("Verification", "Skipped by AZD_DOCTOR_SKIP_VERIFY")
+			rep.skip("Skipped by AZD_DOCTOR_SKIP_VERIFY")
 			return nil
 		}
 	}
@@ -78,7 +132,7 @@ func RunVerify(ctx context.Context, targetCommand string, authTimeout time.Durat
 	if skipVerify != "" {
 		// Simple contains check for now, could be more robust with splitting
 		if contains(skipVerify, targetCommand) {
-			printSuccess("Verification", fmt.Sprintf("Skipped for %s by AZD_DOCTOR_SKIP_VERIFY", targetCommand))
+			rep.skip(fmt.Sprintf("Skipped for %s by AZD_DOCTOR_SKIP_VERIFY", targetCommand))
 			return nil
 		}
 	}
@@ -87,25 +141,66 @@ func RunVerify(ctx context.Context, targetCommand string, authTimeout time.Durat
 		return fmt.Errorf("invalid command target: %s. Must be one of: up, package, provision, deploy", targetCommand)
 	}
 
-	printRunning("Verifying for", targetCommand)
+	rep.report.Target = targetCommand
+	rep.running("Verifying for", targetCommand)
 
-	// 1. Common Checks (azd, git, gh)
-	if err := requireCheck(checks.CheckAzdVersion()); err != nil {
-		return err
+	planner := checks.NewPlanner(checks.DefaultRegistry())
+	executor := checks.NewExecutor(opts.Runner, opts.Concurrency)
+
+	// Load the project up front so that the project requirements can run in
+	// parallel with the common checks. Errors are reported in order below.
+	projectFile, found := findProjectFile()
+	var config *checks.AzureYaml
+	var configErr error
+	if found {
+		rep.report.ProjectFile = projectFile
+		config, configErr = checks.LoadProjectConfig(projectFile)
 	}
-	if err := requireCheck(checks.CheckGit()); err != nil {
-		return err
+
+	var requirements []checks.Requirement
+	if config != nil {
+		environment := opts.Environment
+		if environment == "" {
+			environment = os.Getenv("AZURE_ENV_NAME")
+		}
+		requirements = planner.Plan(config, checks.PlanOptions{
+			Command:     targetCommand,
+			Service:     opts.Service,
+			Environment: environment,
+		}).Requirements
+	} else {
+		// Without a project only the common azd tooling is verified.
+		for _, req := range planner.Plan(nil, checks.PlanOptions{Command: targetCommand}).Requirements {
+			if req.Check.Category() == checks.CategoryAzd {
+				requirements = append(requirements, req)
+			}
+		}
+	}
+
+	list := make([]checks.Check, 0, len(requirements))
+	for _, req := range requirements {
+		list = append(list, req.Check)
+	}
+	results := opts.Session.Run(ctx, executor, list)
+
+	// Every result is recorded before returning, so that the report lists all findings;
+	// the errors of the failed requirements are returned together.
+	var errs []error
+
+	// 1. Common Checks (azd, git, gh)
+	// Optional tools such as 'gh' report warnings and don't fail verification.
+	for i, req := range requirements {
+		if req.Check.Category() != checks.CategoryAzd {
+			continue
+		}
+		rep.requirement(req, results[i])
+		if err := requireCheck(results[i]); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	// gh is often optional, but let's check it as warning or skip if not critical?
-	// For now, let's treat it as non-critical for 'provision'/'deploy' unless we know we need it.
-	// But 'azd pipeline' needs it. 'azd up' might not strictly need it unless using repo.
-	// Let's just log it but not fail? Or maybe fail if it's missing?
-	// The user said "specifically the ones that would log as errors or 'stops'".
-	// 'gh' is not strictly required for local provision/deploy.
-	// So we skip strict requirement for gh.
 
 	// 2. Auth Check
-	authCtx, cancel := context.WithTimeout(ctx, authTimeout)
+	authCtx, cancel := context.WithTimeout(ctx, opts.AuthTimeout)
 	defer cancel()
 	// We need an azd client for auth check if we want to be thorough, but CheckAzdLogin uses CLI mostly.
 	// However, CheckAzdLogin signature is (ctx, client).
@@ -116,133 +211,49 @@ func RunVerify(ctx context.Context, targetCommand string, authTimeout time.Durat
 		// But CheckAzdLogin takes client.
 		// Let's proceed with what we have.
 	}
-	if azdClient != nil {
-		defer azdClient.Close()
-	}
-
-	loginRes := checks.CheckAzdLogin(authCtx, azdClient)
-	if !loginRes.Installed || loginRes.Error != nil {
-		printFailure(loginRes.Name, "Not logged in or error")
-		safeCloseAzdClient(azdClient)
-		return fmt.Errorf("azd auth check failed: %v", loginRes.Error)
+	defer safeCloseAzdClient(azdClient)
+
+	// The login is checked for every run since the token can expire between events.
+	loginRes := timed(func() checks.CheckResult {
+		return checks.CheckAzdLogin(authCtx, opts.Runner, azdClient)
+	})
+	rep.result("azd-auth", checks.CategoryAzd, nil, loginRes)
+	if loginRes.Failed() {
+		errs = append(errs, fmt.Errorf("azd auth check failed: %v", loginRes.Error))
 	}
-	printSuccess(loginRes.Name, loginRes.Version)
 
 	// 3. Project Checks
-	projectFile := "azure.yaml"
-	if _, err := os.Stat(projectFile); os.IsNotExist(err) {
-		projectFile = "azure.yml"
-	}
-
-	if _, err := os.Stat(projectFile); os.IsNotExist(err) {
-		safeCloseAzdClient(azdClient)
-		return fmt.Errorf("project file (azure.yaml/yml) not found, required for %s", targetCommand)
+	if !found {
+		errs = append(errs, fmt.Errorf("project file (azure.yaml/yml) not found, required for %s", targetCommand))
+		return errors.Join(errs...)
 	}
 
-	config, err := checks.LoadProjectConfig(projectFile)
-	if err != nil {
-		safeCloseAzdClient(azdClient)
-		return fmt.Errorf("failed to load project config: %w", err)
+	if configErr != nil {
+		errs = append(errs, fmt.Errorf("failed to load project config: %w", configErr))
+		return errors.Join(errs...)
 	}
 
-	// Required Extensions Check
-	if len(config.RequiredVersions.Extensions) > 0 {
-		installedExtensions, err := checks.GetInstalledExtensions()
-		if err != nil {
-			safeCloseAzdClient(azdClient)
-			return fmt.Errorf("failed to list installed extensions: %w", err)
+	// Project requirements: hooks, extensions, infra and services
+	for i, req := range requirements {
+		if req.Check.Category() == checks.CategoryAzd {
+			continue
 		}
-		for id, version := range config.RequiredVersions.Extensions {
-			if err := requireCheck(checks.CheckExtension(installedExtensions, id, version)); err != nil {
-				safeCloseAzdClient(azdClient)
-				return err
+		rep.requirement(req, results[i])
+		if err := requireCheck(results[i]); err != nil {
+			if req.Check.Category() == checks.CategoryContainer && len(req.Services) > 0 {
+				// Provide helpful suggestion for Docker issues
+				err = fmt.Errorf("%w%s", err, remoteBuildSuggestion(req.Services[0]))
 			}
+			errs = append(errs, err)
 		}
 	}
 
-	// Infra Checks (provision/up)
-	if targetCommand == "provision" || targetCommand == "up" {
-		provider := config.Infra.Provider
-		// Default to bicep if empty
-		if provider == "terraform" {
-			if err := requireCheck(checks.CheckTerraform()); err != nil {
-				safeCloseAzdClient(azdClient)
-				return err
-			}
-		}
+	if err := errors.Join(errs...); err != nil {
+		return err
 	}
-
-	// Service Checks (package/deploy/up)
-	if targetCommand == "package" || targetCommand == "deploy" || targetCommand == "up" {
-		// Check Services
-		checkedLangs := make(map[string]bool)
-		checkedTools := make(map[string]bool)
-
-		for svcName, svc := range config.Services {
-			// Language Checks
-			if svc.Language != "" && !checkedLangs[svc.Language] {
-				var res checks.CheckResult
-				switch svc.Language {
-				case "js", "ts":
-					res = checks.CheckNode()
-				case "py", "python":
-					res = checks.CheckPython()
-				case "csharp", "fsharp", "dotnet":
-					res = checks.CheckDotNet()
-				}
-
-				if res.Name != "" {
-					if err := requireCheck(res); err != nil {
-						safeCloseAzdClient(azdClient)
-						return err
-					}
-				}
-				checkedLangs[svc.Language] = true
-			}
-
-			// Container Checks
-			isContainerHost := svc.Host == "containerapp" || svc.Host == "aks"
-			needsBuild := svc.Image == ""
-
-			if isContainerHost && !svc.Docker.Remote && needsBuild {
-				if !checkedTools["docker"] {
-					dockerCheck := checks.CheckDocker()
-					if err := requireCheck(dockerCheck); err != nil {
-						safeCloseAzdClient(azdClient)
-						// Provide helpful suggestion for Docker issues
-						suggestion := fmt.Sprintf("\n\nTip: You can enable remote build in azure.yaml to build without local Docker:\n  services:\n    %s:\n      docker:\n        remoteBuild: true\n\nOr run:\n  azd doctor configure remote-build", svcName)
-						return fmt.Errorf("%w%s", err, suggestion)
-					}
-					checkedTools["docker"] = true
-				}
-			}
-
-			// Functions Checks
-			if svc.Host == "function" {
-				if !checkedTools["func"] {
-					if err := requireCheck(checks.CheckAzureFunctionsCoreTools()); err != nil {
-						safeCloseAzdClient(azdClient)
-						return err
-					}
-					checkedTools["func"] = true
-				}
-			}
-
-			// Static Web Apps Checks
-			if svc.Host == "staticwebapp" {
-				if !checkedTools["swa"] {
-					if err := requireCheck(checks.CheckSwaCli()); err != nil {
-						safeCloseAzdClient(azdClient)
-						return err
-					}
-					checkedTools["swa"] = true
-				}
-			}
-		}
+	if rep.isText() {
+		printSuccess("Verification", "Passed")
 	}
-
-	printSuccess("Verification", "Passed")
-	safeCloseAzdClient(azdClient)
 	return nil
 }
 
@@ -254,21 +265,26 @@ func safeCloseAzdClient(client *azdext.AzdClient) {
 	}
 }
 
+// requireCheck returns an error only for fail-severity results.
 func requireCheck(res checks.CheckResult) error {
+	if !res.Failed() {
+		return nil
+	}
+
 	if !res.Installed {
-		printFailure(res.Name, "Not found")
 		return fmt.Errorf("required tool not found: %s", res.Name)
 	}
-	if res.Error != nil {
-		printFailure(res.Name, res.Error.Error())
-		return fmt.Errorf("check failed for %s: %w", res.Name, res.Error)
-	}
 	if res.HasDaemon && !res.Running {
-		printFailure(res.Name, "Daemon not running")
 		return fmt.Errorf("%s daemon is not running", res.Name)
 	}
-	printSuccess(res.Name, res.Version)
-	return nil
+	if res.Error != nil {
+		return fmt.Errorf("check failed for %s: %w", res.Name, res.Error)
+	}
+	return fmt.Errorf("check failed for %s", res.Name)
+}
+
+func remoteBuildSuggestion(svcName string) string {
+	return fmt.Sprintf("\n\nTip: You can enable remote build in azure.yaml to build without local Docker:\n  services:\n    %s:\n      docker:\n        remoteBuild: true\n\nOr run:\n  azd doctor configure remote-build", svcName)
 }
 
 func contains(s, substr string) bool {
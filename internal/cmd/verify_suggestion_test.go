@@ -10,14 +10,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"spboyer.azd.doctor/internal/checks"
 )
 
 func TestRunVerify_DockerSuggestion(t *testing.T) {
-	// Save original runner
-	origRunner := checks.CommandRunner
-	defer func() { checks.CommandRunner = origRunner }()
-
 	// Mock runner
 	mockRunner := &MockRunner{
 		OutputFunc: func(name string, args ...string) ([]byte, error) {
@@ -40,7 +35,6 @@ func TestRunVerify_DockerSuggestion(t *testing.T) {
 			return nil
 		},
 	}
-	checks.CommandRunner = mockRunner
 
 	// Create temp directory for project
 	tmpDir, err := os.MkdirTemp("", "azd-doctor-test")
@@ -67,7 +61,7 @@ services:
 	require.NoError(t, err)
 
 	// Run Verify
-	err = RunVerify(context.Background(), "up", 1*time.Second)
+	err = RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "up", AuthTimeout: 1 * time.Second})
 
 	// Assert error contains tip
 	require.Error(t, err)
@@ -78,10 +72,6 @@ services:
 }
 
 func TestRunVerify_DockerDaemonNotRunningSuggestion(t *testing.T) {
-	// Save original runner
-	origRunner := checks.CommandRunner
-	defer func() { checks.CommandRunner = origRunner }()
-
 	// Mock runner - Docker is installed but daemon is not running
 	mockRunner := &MockRunner{
 		OutputFunc: func(name string, args ...string) ([]byte, error) {
@@ -110,7 +100,6 @@ func TestRunVerify_DockerDaemonNotRunningSuggestion(t *testing.T) {
 			return nil
 		},
 	}
-	checks.CommandRunner = mockRunner
 
 	// Create temp directory for project
 	tmpDir, err := os.MkdirTemp("", "azd-doctor-test")
@@ -137,7 +126,7 @@ services:
 	require.NoError(t, err)
 
 	// Run Verify for package command
-	err = RunVerify(context.Background(), "package", 1*time.Second)
+	err = RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "package", AuthTimeout: 1 * time.Second})
 
 	// Assert error contains tip for daemon not running
 	require.Error(t, err)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,11 +33,9 @@ func (m *MockRunner) Run(name string, args ...string) error {
 	return nil
 }
 
-func TestRunVerify_Skip(t *testing.T) {
-	// Save original runner
-	origRunner := checks.CommandRunner
-	defer func() { checks.CommandRunner = origRunner }()
+var _ checks.Runner = (*MockRunner)(nil)
 
+func TestRunVerify_Skip(t *testing.T) {
 	// Set up a runner that always fails.
 	// If verification is NOT skipped, RunVerify should fail because it calls checks (starting with azd version check).
 	// If verification IS skipped, RunVerify should succeed (return nil) without calling checks.
@@ -44,7 +44,6 @@ func TestRunVerify_Skip(t *testing.T) {
 			return nil, fmt.Errorf("command execution failed intentionally")
 		},
 	}
-	checks.CommandRunner = failingRunner
 
 	tests := []struct {
 		name       string
@@ -107,7 +106,7 @@ func TestRunVerify_Skip(t *testing.T) {
 			os.Setenv("AZD_DOCTOR_SKIP_VERIFY", tt.envVar)
 			defer os.Unsetenv("AZD_DOCTOR_SKIP_VERIFY")
 
-			err := RunVerify(context.Background(), tt.targetCmd, 1*time.Second)
+			err := RunVerify(context.Background(), VerifyOptions{Runner: failingRunner, Command: tt.targetCmd, AuthTimeout: 1 * time.Second})
 			if tt.shouldSkip {
 				assert.NoError(t, err)
 			} else {
@@ -122,16 +121,11 @@ func TestRunVerify_Skip(t *testing.T) {
 }
 
 func TestRunVerify_HookContext(t *testing.T) {
-	// Save original runner
-	origRunner := checks.CommandRunner
-	defer func() { checks.CommandRunner = origRunner }()
-
 	failingRunner := &MockRunner{
 		OutputFunc: func(name string, args ...string) ([]byte, error) {
 			return nil, fmt.Errorf("command execution failed intentionally")
 		},
 	}
-	checks.CommandRunner = failingRunner
 
 	tests := []struct {
 		name       string
@@ -173,7 +167,7 @@ func TestRunVerify_HookContext(t *testing.T) {
 			defer os.Unsetenv("AZD_DOCTOR_SKIP_VERIFY")
 
 			// Pass empty targetCommand so it infers from hook
-			err := RunVerify(context.Background(), "", 1*time.Second)
+			err := RunVerify(context.Background(), VerifyOptions{Runner: failingRunner, AuthTimeout: 1 * time.Second})
 			if tt.shouldSkip {
 				assert.NoError(t, err)
 			} else {
@@ -182,3 +176,212 @@ func TestRunVerify_HookContext(t *testing.T) {
 		})
 	}
 }
+
+func TestRunVerify_OptionalToolWarns(t *testing.T) {
+	tests := []struct {
+		name      string
+		missing   string
+		shouldErr bool
+	}{
+		{name: "Missing gh only warns", missing: "gh", shouldErr: false},
+		{name: "Missing git fails", missing: "git", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRunner := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					if name == tt.missing {
+						return nil, fmt.Errorf("executable file not found in $PATH")
+					}
+					return []byte("1.0.0"), nil
+				},
+			}
+
+			tmpDir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte("name: test-project\n"), 0644))
+			cwd, _ := os.Getwd()
+			defer os.Chdir(cwd)
+			assert.NoError(t, os.Chdir(tmpDir))
+
+			err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "up", AuthTimeout: 1 * time.Second})
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "required tool not found: "+tt.missing)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunVerify_HookShellRequirement(t *testing.T) {
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			if name == "bash" {
+				return nil, fmt.Errorf("executable file not found in $PATH")
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	azureYaml := "name: test-project\nhooks:\n  preprovision:\n    shell: bash\n    run: ./setup.sh\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(azureYaml), 0644))
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	assert.NoError(t, os.Chdir(tmpDir))
+
+	err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "provision", AuthTimeout: 1 * time.Second})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "required tool not found: bash")
+
+	err = RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "deploy", AuthTimeout: 1 * time.Second})
+	assert.NoError(t, err, "provision hooks don't run during deploy")
+}
+
+func TestRunVerify_RequiredAzdVersion(t *testing.T) {
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			if name == "azd" && len(args) > 0 && args[0] == "version" {
+				return []byte("azd version 1.9.0 (commit 1234567)"), nil
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+
+	tests := []struct {
+		name      string
+		required  string
+		shouldErr bool
+	}{
+		{name: "Installed azd is new enough", required: ">= 1.5.0", shouldErr: false},
+		{name: "Installed azd is too old", required: ">= 1.10.0", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			azureYaml := fmt.Sprintf("name: test-project\nrequiredVersions:\n  azd: \"%s\"\n", tt.required)
+			assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(azureYaml), 0644))
+			cwd, _ := os.Getwd()
+			defer os.Chdir(cwd)
+			assert.NoError(t, os.Chdir(tmpDir))
+
+			err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "provision", AuthTimeout: 1 * time.Second})
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "does not satisfy requiredVersions.azd")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunVerify_CustomCheck(t *testing.T) {
+	azureYaml := `name: test-project
+x-doctor:
+  checks:
+    - id: internal-cli
+      command: internal-cli
+      args: ["--version"]
+      output: "^internal-cli 2\\."
+      remediation: Install internal-cli 2.x
+`
+	tests := []struct {
+		name      string
+		version   string
+		shouldErr bool
+	}{
+		{name: "Custom check passes", version: "internal-cli 2.3.0", shouldErr: false},
+		{name: "Custom check fails", version: "internal-cli 1.9.0", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRunner := &MockRunner{
+				OutputFunc: func(name string, args ...string) ([]byte, error) {
+					if name == "internal-cli" {
+						return []byte(tt.version), nil
+					}
+					return []byte("1.0.0"), nil
+				},
+			}
+			tmpDir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(azureYaml), 0644))
+			cwd, _ := os.Getwd()
+			defer os.Chdir(cwd)
+			assert.NoError(t, os.Chdir(tmpDir))
+
+			err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "provision", AuthTimeout: 1 * time.Second})
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "check failed for internal-cli")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunVerify_Session(t *testing.T) {
+	calls := make(map[string]int)
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			calls[strings.Join(append([]string{name}, args...), " ")]++
+			if name == "node" {
+				return []byte("v20.0.0"), nil
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+	tmpDir := t.TempDir()
+	azureYaml := "name: test-project\nservices:\n  api:\n    language: js\n    host: appservice\n  web:\n    language: js\n    host: appservice\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte(azureYaml), 0644))
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	assert.NoError(t, os.Chdir(tmpDir))
+
+	// The events of a single `azd up` handled by one extension host.
+	h := &lifecycleHandlers{runner: mockRunner, session: checks.NewSession()}
+	for _, service := range []string{"api", "web"} {
+		opts := h.verifyOptions("package")
+		opts.Service = service
+		assert.NoError(t, RunVerify(context.Background(), opts))
+	}
+	assert.NoError(t, RunVerify(context.Background(), h.verifyOptions("provision")))
+	assert.NoError(t, RunVerify(context.Background(), h.verifyOptions("deploy")))
+
+	assert.Equal(t, 1, calls["azd version"])
+	assert.Equal(t, 1, calls["git --version"])
+	assert.Equal(t, 1, calls["node --version"])
+	assert.Equal(t, 4, calls["azd auth login --check-status"], "the login is checked for every event")
+}
+
+func TestRunVerify_InfraParameters(t *testing.T) {
+	tmpDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "azure.yaml"), []byte("name: test-project\n"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "infra"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "infra", "main.parameters.json"),
+		[]byte(`{"parameters": {"name": {"value": "${AZD_DOCTOR_TEST_MISSING}"}}}`), 0644))
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	assert.NoError(t, os.Chdir(tmpDir))
+
+	mockRunner := &MockRunner{
+		OutputFunc: func(name string, args ...string) ([]byte, error) {
+			if name == "azd" && len(args) > 0 && args[0] == "env" {
+				return []byte(`{"AZURE_ENV_NAME":"dev"}`), nil
+			}
+			return []byte("1.0.0"), nil
+		},
+	}
+
+	err := RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "provision", AuthTimeout: 1 * time.Second})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "AZD_DOCTOR_TEST_MISSING")
+
+	err = RunVerify(context.Background(), VerifyOptions{Runner: mockRunner, Command: "deploy", AuthTimeout: 1 * time.Second})
+	assert.NoError(t, err, "parameters are only checked when provisioning")
+}
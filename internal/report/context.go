@@ -0,0 +1,159 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Context is the document emitted by `context --output json|yaml|table`.
+type Context struct {
+	SchemaVersion string `json:"schemaVersion" yaml:"schemaVersion"`
+	Command       string `json:"command" yaml:"command"`
+	// UserConfig is the azd user configuration, if any.
+	UserConfig map[string]any `json:"userConfig,omitempty" yaml:"userConfig,omitempty"`
+	// Project is nil when there is no azd project in the current directory.
+	Project *Project `json:"project" yaml:"project"`
+	// Environment is the selected environment; empty when there is none.
+	Environment  string   `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Environments []string `json:"environments" yaml:"environments"`
+	// Values are the values of the selected environment.
+	Values     map[string]string `json:"values,omitempty" yaml:"values,omitempty"`
+	Deployment *Deployment       `json:"deployment,omitempty" yaml:"deployment,omitempty"`
+	Resources  []Resource        `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+type Project struct {
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
+}
+
+// Deployment is the Azure scope of the selected environment.
+type Deployment struct {
+	TenantID       string `json:"tenantId,omitempty" yaml:"tenantId,omitempty"`
+	SubscriptionID string `json:"subscriptionId,omitempty" yaml:"subscriptionId,omitempty"`
+	Location       string `json:"location,omitempty" yaml:"location,omitempty"`
+	ResourceGroup  string `json:"resourceGroup,omitempty" yaml:"resourceGroup,omitempty"`
+}
+
+// Resource is a provisioned Azure resource, with the parts of its parsed resource ID.
+type Resource struct {
+	ID             string `json:"id" yaml:"id"`
+	Name           string `json:"name" yaml:"name"`
+	Type           string `json:"type" yaml:"type"`
+	SubscriptionID string `json:"subscriptionId,omitempty" yaml:"subscriptionId,omitempty"`
+	ResourceGroup  string `json:"resourceGroup,omitempty" yaml:"resourceGroup,omitempty"`
+}
+
+func NewContext() *Context {
+	return &Context{SchemaVersion: SchemaVersion, Command: "context", Environments: []string{}}
+}
+
+// Write writes the context as indented JSON.
+func (c *Context) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// WriteYAML writes the context as YAML, with the same field names as the JSON document.
+func (c *Context) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// WriteTable writes the context as a KEY VALUE table sorted by key. Keys are the dotted
+// paths of the JSON document (e.g. deployment.resourceGroup, resources.0.name), so a
+// value can be looked up with grep.
+func (c *Context) WriteTable(w io.Writer) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	rows := make(map[string]string)
+	flatten(rows, "", doc)
+
+	keys := make([]string, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE")
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", key, rows[key])
+	}
+	return tw.Flush()
+}
+
+// flatten adds the scalar values of a decoded JSON document to rows, keyed by their dotted path.
+func flatten(rows map[string]string, prefix string, value any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			flatten(rows, join(key), nested)
+		}
+	case []any:
+		for i, nested := range v {
+			flatten(rows, join(strconv.Itoa(i)), nested)
+		}
+	case nil:
+		rows[prefix] = "null"
+	case string:
+		rows[prefix] = v
+	default:
+		data, _ := json.Marshal(v)
+		rows[prefix] = string(data)
+	}
+}
+
+// scopeKeys are the environment values that define the deployment scope of an environment.
+var scopeKeys = []struct{ name, key string }{
+	{"subscriptionId", "AZURE_SUBSCRIPTION_ID"},
+	{"location", "AZURE_LOCATION"},
+	{"resourceGroup", "AZURE_RESOURCE_GROUP"},
+}
+
+// DiffEnvironments returns the differences between the values of two environments: first
+// the deployment scope (kind scope), then the other values (kind value) sorted by key.
+// A and B are empty when the value is missing from that environment.
+func DiffEnvironments(a, b map[string]string) []Difference {
+	diffs := []Difference{}
+	scope := make(map[string]bool, len(scopeKeys))
+	for _, s := range scopeKeys {
+		scope[s.key] = true
+		if a[s.key] != b[s.key] {
+			diffs = append(diffs, Difference{Kind: "scope", Name: s.name, A: a[s.key], B: b[s.key]})
+		}
+	}
+
+	for _, key := range unionKeys(a, b) {
+		va, okA := a[key]
+		vb, okB := b[key]
+		if scope[key] || (okA == okB && va == vb) {
+			continue
+		}
+		diffs = append(diffs, Difference{Kind: "value", Name: key, A: va, B: vb})
+	}
+	return diffs
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testContext() *Context {
+	c := NewContext()
+	c.UserConfig = map[string]any{"defaults": map[string]any{"location": "eastus"}}
+	c.Project = &Project{Name: "todo", Path: "/src/todo"}
+	c.Environment = "dev"
+	c.Environments = []string{"dev", "prod"}
+	c.Values = map[string]string{"AZURE_LOCATION": "eastus", "AZURE_ENV_NAME": "dev"}
+	c.Deployment = &Deployment{SubscriptionID: "sub", Location: "eastus", ResourceGroup: "rg-dev"}
+	c.Resources = []Resource{{
+		ID:             "/subscriptions/sub/resourceGroups/rg-dev/providers/Microsoft.Web/sites/app",
+		Name:           "app",
+		Type:           "Microsoft.Web/sites",
+		SubscriptionID: "sub",
+		ResourceGroup:  "rg-dev",
+	}}
+	return c
+}
+
+func TestContext_WriteYAML(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testContext().WriteYAML(&out))
+
+	assert.Equal(t, `schemaVersion: "1.0"
+command: context
+userConfig:
+  defaults:
+    location: eastus
+project:
+  name: todo
+  path: /src/todo
+environment: dev
+environments:
+  - dev
+  - prod
+values:
+  AZURE_ENV_NAME: dev
+  AZURE_LOCATION: eastus
+deployment:
+  subscriptionId: sub
+  location: eastus
+  resourceGroup: rg-dev
+resources:
+  - id: /subscriptions/sub/resourceGroups/rg-dev/providers/Microsoft.Web/sites/app
+    name: app
+    type: Microsoft.Web/sites
+    subscriptionId: sub
+    resourceGroup: rg-dev
+`, out.String())
+}
+
+func TestContext_WriteTable(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, testContext().WriteTable(&out))
+
+	assert.Equal(t, `KEY                           VALUE
+command                       context
+deployment.location           eastus
+deployment.resourceGroup      rg-dev
+deployment.subscriptionId     sub
+environment                   dev
+environments.0                dev
+environments.1                prod
+project.name                  todo
+project.path                  /src/todo
+resources.0.id                /subscriptions/sub/resourceGroups/rg-dev/providers/Microsoft.Web/sites/app
+resources.0.name              app
+resources.0.resourceGroup     rg-dev
+resources.0.subscriptionId    sub
+resources.0.type              Microsoft.Web/sites
+schemaVersion                 1.0
+userConfig.defaults.location  eastus
+values.AZURE_ENV_NAME         dev
+values.AZURE_LOCATION         eastus
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, NewContext().WriteTable(&out))
+	assert.Contains(t, out.String(), "project        null")
+}
+
+func TestDiffEnvironments(t *testing.T) {
+	dev := map[string]string{
+		"AZURE_ENV_NAME":        "dev",
+		"AZURE_LOCATION":        "eastus",
+		"AZURE_SUBSCRIPTION_ID": "sub-1",
+		"AZURE_RESOURCE_GROUP":  "rg-dev",
+		"API_URL":               "https://dev.example.com",
+		"FEATURE_FLAG":          "on",
+	}
+	prod := map[string]string{
+		"AZURE_ENV_NAME":        "prod",
+		"AZURE_LOCATION":        "eastus",
+		"AZURE_SUBSCRIPTION_ID": "sub-2",
+		"API_URL":               "https://dev.example.com",
+		"CDN_ENDPOINT":          "cdn.example.com",
+	}
+
+	assert.Equal(t, []Difference{
+		{Kind: "scope", Name: "subscriptionId", A: "sub-1", B: "sub-2"},
+		{Kind: "scope", Name: "resourceGroup", A: "rg-dev", B: ""},
+		{Kind: "value", Name: "AZURE_ENV_NAME", A: "dev", B: "prod"},
+		{Kind: "value", Name: "CDN_ENDPOINT", A: "", B: "cdn.example.com"},
+		{Kind: "value", Name: "FEATURE_FLAG", A: "on", B: ""},
+	}, DiffEnvironments(dev, prod))
+
+	assert.Empty(t, DiffEnvironments(dev, dev))
+}
@@ -0,0 +1,34 @@
+package report
+
+import "sort"
+
+// Difference is a value that differs between two snapshots or two environments. A and B
+// are empty when the item is missing from that snapshot or environment.
+type Difference struct {
+	// Kind is system, tool, extension or config for snapshots, scope or value for environments.
+	Kind string `json:"kind"`
+	// Name is the tool or extension ID, the config or environment key, or the system
+	// or scope property (os, arch, subscriptionId, location, resourceGroup).
+	Name string `json:"name"`
+	// Field is the differing tool field (status, version, path); empty otherwise.
+	Field string `json:"field,omitempty"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// unionKeys returns the sorted keys present in either map.
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"spboyer.azd.doctor/internal/checks"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one test case per check. Failed checks
+// are failures, skipped checks are skipped, and warnings pass with the warning in system-out.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	name := "azd doctor " + r.Command
+	if r.Target != "" {
+		name += " " + r.Target
+	}
+
+	suite := junitTestSuite{Name: name}
+	var totalMs int64
+	for _, c := range r.Checks {
+		tc := junitTestCase{
+			Name:      c.ID,
+			ClassName: fmt.Sprintf("%s.%s", toolName, c.categoryOrDefault()),
+			Time:      seconds(c.DurationMs),
+		}
+		switch c.Status {
+		case checks.SeverityFail:
+			tc.Failure = &junitMessage{Message: c.message(), Text: c.Remediation}
+			suite.Failures++
+		case checks.SeveritySkip:
+			tc.Skipped = &junitMessage{Message: c.message()}
+			suite.Skipped++
+		default:
+			tc.SystemOut = c.message()
+		}
+		totalMs += c.DurationMs
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+	suite.Time = seconds(totalMs)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{
+		Name:     name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (c Check) categoryOrDefault() string {
+	if c.Category == "" {
+		return "general"
+	}
+	return string(c.Category)
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,41 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport().WriteJUnit(&buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte(xml.Header)))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(t, "azd doctor verify deploy", suites.Name)
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 1, suites.Skipped)
+	require.Len(t, suites.Suites, 1)
+
+	cases := suites.Suites[0].Cases
+	require.Len(t, cases, 4)
+
+	assert.Equal(t, "git", cases[0].Name)
+	assert.Equal(t, "azd-doctor.azd", cases[0].ClassName)
+	assert.Nil(t, cases[0].Failure)
+
+	assert.Nil(t, cases[1].Failure, "warnings don't fail the test case")
+	assert.Contains(t, cases[1].SystemOut, "unknown host")
+
+	require.NotNil(t, cases[2].Failure)
+	assert.Contains(t, cases[2].Failure.Message, "executable file not found")
+	assert.Equal(t, "Install Node.js", cases[2].Failure.Text)
+	assert.Equal(t, "1.500", cases[2].Time)
+
+	require.NotNil(t, cases[3].Skipped)
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the values removed by a Redactor.
+const Redacted = "<redacted>"
+
+// sensitiveKeyPattern matches config keys and environment variable names whose values
+// are secrets (e.g. AZURE_STORAGE_KEY, auth.token, DB_PASSWORD). It is shared by
+// `context` and the support bundle so that both hide the same values.
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(secret|password|passwd|token|credential|connection_?string|signature|sas_?url|key)`)
+
+// keyExemptPattern matches the parts of names that contain "key" without holding a key,
+// e.g. AZURE_KEY_VAULT_NAME or KEYCLOAK_URL. They are ignored by sensitiveKey.
+var keyExemptPattern = regexp.MustCompile(`(?i)key_?vault|keycloak`)
+
+// sensitiveKey reports whether key names a secret value.
+func sensitiveKey(key string) bool {
+	return sensitiveKeyPattern.MatchString(keyExemptPattern.ReplaceAllString(key, ""))
+}
+
+var subscriptionKeyPattern = regexp.MustCompile(`(?i)subscription`)
+
+// secretPatterns match secrets in free text. The first group, if any, is kept.
+var secretPatterns = []*regexp.Regexp{
+	// JSON web tokens
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]*`),
+	// GitHub tokens
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{30,}`),
+	// Bearer tokens
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]{8,}=*`),
+	// Connection string secrets
+	regexp.MustCompile(`(?i)((?:AccountKey|SharedAccessKey|Password|Pwd)=)[^;"'\s]+`),
+	// SAS signatures
+	regexp.MustCompile(`(?i)([?&]sig=)[^&"'\s]+`),
+}
+
+// keyValuePattern matches `key: value` and `KEY=value` lines in YAML, .env and log files.
+var keyValuePattern = regexp.MustCompile(`(?m)^(\s*(?:-\s+)?(?:export\s+)?["']?([\w.-]+)["']?\s*[:=][ \t]*)(\S.*?)\s*$`)
+
+var subscriptionPattern = regexp.MustCompile(`(?i)(/subscriptions/)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// keyVaultReferencePattern matches Key Vault references: azd `akvs://` references and
+// App Service `@Microsoft.KeyVault(...)` references.
+var keyVaultReferencePattern = regexp.MustCompile(`(?i)^(akvs://|@Microsoft\.KeyVault\()`)
+
+var (
+	tokenPattern = regexp.MustCompile(`^[A-Za-z0-9+/=_-]+$`)
+	guidPattern  = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above which a long
+// token is considered a generated secret (e.g. a storage account key or an API key).
+const minSecretEntropy = 4.0
+
+// IsSecret reports whether an environment value must be masked: its key looks like a
+// secret, it is a Key Vault reference or it looks like a generated secret.
+func IsSecret(key, value string) bool {
+	if value == "" {
+		return false
+	}
+	return sensitiveKey(key) || IsSecretValue(value)
+}
+
+// IsSecretValue reports whether a value is a Key Vault reference or a long token with high
+// entropy, regardless of its key.
+func IsSecretValue(value string) bool {
+	if keyVaultReferencePattern.MatchString(value) {
+		return true
+	}
+	// Resource IDs and paths start with a slash.
+	if len(value) < 24 || !tokenPattern.MatchString(value) || guidPattern.MatchString(value) ||
+		strings.HasPrefix(value, "/") || !strings.ContainsAny(value, "0123456789") {
+		return false
+	}
+	return entropy(value) >= minSecretEntropy
+}
+
+// MaskSecrets returns a copy of environment values with the secrets replaced by Redacted,
+// and the number of masked values.
+func MaskSecrets(values map[string]string) (map[string]string, int) {
+	if values == nil {
+		return nil, 0
+	}
+	masked := make(map[string]string, len(values))
+	count := 0
+	for key, value := range values {
+		if IsSecret(key, value) {
+			value = Redacted
+			count++
+		}
+		masked[key] = value
+	}
+	return masked, count
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+	var e float64
+	for _, count := range counts {
+		p := float64(count) / float64(n)
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+// Redactor masks secrets, tokens and, optionally, subscription IDs in the files of a
+// support bundle. Values it redacts by key are remembered and masked wherever they
+// appear in text redacted later, so redact structured data (context, config) before logs.
+type Redactor struct {
+	// Subscriptions also masks Azure subscription IDs.
+	Subscriptions bool
+
+	values map[string]bool
+}
+
+func NewRedactor(subscriptions bool) *Redactor {
+	return &Redactor{Subscriptions: subscriptions, values: make(map[string]bool)}
+}
+
+// Sensitive reports whether the value of key must be redacted.
+func (r *Redactor) Sensitive(key string) bool {
+	return sensitiveKey(key) || (r.Subscriptions && subscriptionKeyPattern.MatchString(key))
+}
+
+// Value returns value, or Redacted if it is a secret (see IsSecret) or, with
+// Subscriptions, a subscription ID.
+func (r *Redactor) Value(key, value string) string {
+	if !IsSecret(key, value) && !(value != "" && r.Subscriptions && subscriptionKeyPattern.MatchString(key)) {
+		return r.Text(value)
+	}
+	// Short values like "on" would mask unrelated text.
+	if len(value) >= 4 {
+		r.values[value] = true
+	}
+	return Redacted
+}
+
+// Values returns a copy of values with the sensitive values redacted.
+func (r *Redactor) Values(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		redacted[key] = r.Value(key, value)
+	}
+	return redacted
+}
+
+// Any returns a copy of a decoded JSON value with the sensitive values redacted.
+func (r *Redactor) Any(key string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for k, nested := range v {
+			redacted[k] = r.Any(k, nested)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, nested := range v {
+			redacted[i] = r.Any(key, nested)
+		}
+		return redacted
+	case string:
+		return r.Value(key, v)
+	case nil, bool:
+		return v
+	default:
+		if r.Sensitive(key) {
+			return Redacted
+		}
+		return v
+	}
+}
+
+// JSON redacts a JSON document, re-encoding it with indentation. Documents that
+// aren't valid JSON are redacted as text.
+func (r *Redactor) JSON(data []byte) []byte {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []byte(r.Text(string(data)))
+	}
+	redacted, err := json.MarshalIndent(r.Any("", value), "", "  ")
+	if err != nil {
+		return []byte(r.Text(string(data)))
+	}
+	return append(redacted, '\n')
+}
+
+// Text redacts free text such as azure.yaml or a log file.
+func (r *Redactor) Text(text string) string {
+	text = keyValuePattern.ReplaceAllStringFunc(text, func(line string) string {
+		m := keyValuePattern.FindStringSubmatch(line)
+		if !r.Sensitive(m[2]) {
+			return line
+		}
+		return m[1] + r.Value(m[2], m[3])
+	})
+	for _, pattern := range secretPatterns {
+		text = replaceKeepingPrefix(pattern, text)
+	}
+	if r.Subscriptions {
+		text = replaceKeepingPrefix(subscriptionPattern, text)
+	}
+
+	// Longest first, so a value containing another is masked as a whole.
+	values := make([]string, 0, len(r.values))
+	for value := range r.values {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, Redacted)
+	}
+	return text
+}
+
+// replaceKeepingPrefix replaces the matches of pattern with Redacted, keeping the
+// first group of the pattern (e.g. `AccountKey=`) when it has one.
+func replaceKeepingPrefix(pattern *regexp.Regexp, text string) string {
+	if pattern.NumSubexp() == 0 {
+		return pattern.ReplaceAllString(text, Redacted)
+	}
+	return pattern.ReplaceAllString(text, "${1}"+Redacted)
+}
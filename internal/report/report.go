@@ -0,0 +1,135 @@
+// Package report defines the machine-readable output of azd doctor (`--output json`).
+//
+// The schema is versioned through SchemaVersion; fields are only added within a
+// major version and never renamed or removed. See docs/output-schema.md.
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"spboyer.azd.doctor/internal/checks"
+)
+
+// SchemaVersion is the version of the JSON report schema.
+const SchemaVersion = "1.0"
+
+// Report is the JSON document emitted by `check` and `verify`.
+type Report struct {
+	SchemaVersion string `json:"schemaVersion"`
+	// Command is the doctor command that produced the report (check, verify).
+	Command string `json:"command"`
+	// Target is the azd command verified by `verify` (up, package, provision, deploy).
+	Target string `json:"target,omitempty"`
+	// Status is the overall status: fail if any check failed, warn if any check warned,
+	// skip if verification was bypassed, pass otherwise.
+	Status checks.Severity `json:"status"`
+	// Message explains the overall status (e.g. why verification was skipped).
+	Message string `json:"message,omitempty"`
+	// Error is the error that ended the command, if any.
+	Error string `json:"error,omitempty"`
+	// ProjectFile is the azure.yaml the checks were planned from, if any.
+	ProjectFile string  `json:"projectFile,omitempty"`
+	Checks      []Check `json:"checks"`
+}
+
+// Check is a single check result.
+type Check struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Category checks.Category `json:"category,omitempty"`
+	Status   checks.Severity `json:"status"`
+	// Version is the detected version (tool output), if any.
+	Version string `json:"version,omitempty"`
+	// Path is the resolved path of the executable, if known.
+	Path        string `json:"path,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+	// Install is the command that installs a missing tool, if known.
+	Install  string   `json:"install,omitempty"`
+	Services []string `json:"services,omitempty"`
+	// DurationMs is the time the check took, in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+	// Location points at the azure.yaml entry that caused the result, if any.
+	Location *Location `json:"location,omitempty"`
+}
+
+// Location is a position in a project file.
+type Location struct {
+	File   string `json:"file"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+func New(command string) *Report {
+	return &Report{SchemaVersion: SchemaVersion, Command: command, Checks: []Check{}}
+}
+
+// Add appends a check result. The result ID is used unless id is set.
+func (r *Report) Add(id string, category checks.Category, services []string, res checks.CheckResult) {
+	r.Checks = append(r.Checks, FromResult(id, category, services, res))
+}
+
+// FromResult converts a check result to its report entry.
+func FromResult(id string, category checks.Category, services []string, res checks.CheckResult) Check {
+	if id == "" {
+		id = res.ID
+	}
+	c := Check{
+		ID:          id,
+		Name:        res.Name,
+		Category:    category,
+		Status:      res.Status(),
+		Version:     res.Version,
+		Path:        res.Path,
+		Remediation: res.Remediation,
+		Install:     res.Install,
+		Services:    services,
+		DurationMs:  res.Duration.Milliseconds(),
+	}
+	if res.Error != nil {
+		c.Error = res.Error.Error()
+	}
+	if res.Location != nil {
+		c.Location = &Location{File: res.Location.File, Line: res.Location.Line, Column: res.Location.Column}
+	}
+	return c
+}
+
+// Finish computes the overall status and records err, if any.
+func (r *Report) Finish(err error) {
+	if err != nil {
+		r.Error = err.Error()
+	}
+	if r.Status == checks.SeveritySkip {
+		return
+	}
+
+	r.Status = checks.SeverityPass
+	for _, c := range r.Checks {
+		switch c.Status {
+		case checks.SeverityFail:
+			r.Status = checks.SeverityFail
+		case checks.SeverityWarn:
+			if r.Status != checks.SeverityFail {
+				r.Status = checks.SeverityWarn
+			}
+		}
+	}
+	if err != nil {
+		r.Status = checks.SeverityFail
+	}
+}
+
+// Skip marks the report as skipped with the given reason.
+func (r *Report) Skip(message string) {
+	r.Status = checks.SeveritySkip
+	r.Message = message
+}
+
+// Write writes the report as indented JSON.
+func (r *Report) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/checks"
+)
+
+func TestFromResult(t *testing.T) {
+	res := checks.CheckResult{
+		ID:          "node",
+		Name:        "node",
+		Installed:   false,
+		Error:       fmt.Errorf("executable file not found"),
+		Severity:    checks.SeverityFail,
+		Remediation: "Install Node.js",
+		Install:     "brew install node",
+		Duration:    1500 * time.Millisecond,
+	}
+
+	c := FromResult("", checks.CategoryLanguage, []string{"web"}, res)
+	assert.Equal(t, Check{
+		ID:          "node",
+		Name:        "node",
+		Category:    checks.CategoryLanguage,
+		Status:      checks.SeverityFail,
+		Error:       "executable file not found",
+		Remediation: "Install Node.js",
+		Install:     "brew install node",
+		Services:    []string{"web"},
+		DurationMs:  1500,
+	}, c)
+
+	assert.Equal(t, "azd-auth", FromResult("azd-auth", checks.CategoryAzd, nil, res).ID, "explicit id wins")
+}
+
+func TestReportFinish(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []checks.Severity
+		err      error
+		expected checks.Severity
+	}{
+		{name: "Empty report passes", expected: checks.SeverityPass},
+		{name: "Info and skip pass", statuses: []checks.Severity{checks.SeverityInfo, checks.SeveritySkip}, expected: checks.SeverityPass},
+		{name: "Warning", statuses: []checks.Severity{checks.SeverityPass, checks.SeverityWarn}, expected: checks.SeverityWarn},
+		{name: "Failure wins", statuses: []checks.Severity{checks.SeverityFail, checks.SeverityWarn}, expected: checks.SeverityFail},
+		{name: "Error fails", err: fmt.Errorf("project file not found"), expected: checks.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New("verify")
+			for i, status := range tt.statuses {
+				r.Add(fmt.Sprintf("check-%d", i), checks.CategoryAzd, nil, checks.CheckResult{Severity: status})
+			}
+			r.Finish(tt.err)
+			assert.Equal(t, tt.expected, r.Status)
+			if tt.err != nil {
+				assert.Equal(t, tt.err.Error(), r.Error)
+			}
+		})
+	}
+
+	t.Run("Skipped report stays skipped", func(t *testing.T) {
+		r := New("verify")
+		r.Skip("Skipped by AZD_DOCTOR_SKIP_VERIFY")
+		r.Finish(nil)
+		assert.Equal(t, checks.SeveritySkip, r.Status)
+	})
+}
+
+func TestReportWrite(t *testing.T) {
+	r := New("check")
+	r.Add("", checks.CategoryAzd, nil, checks.CheckResult{ID: "git", Name: "git", Installed: true, Version: "2.0.0", Path: "/usr/bin/git"})
+	r.Finish(nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Write(&buf))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, SchemaVersion, doc["schemaVersion"])
+	assert.Equal(t, "check", doc["command"])
+	assert.Equal(t, "pass", doc["status"])
+
+	entries := doc["checks"].([]any)
+	require.Len(t, entries, 1)
+	entry := entries[0].(map[string]any)
+	assert.Equal(t, "git", entry["id"])
+	assert.Equal(t, "/usr/bin/git", entry["path"])
+	assert.Equal(t, "2.0.0", entry["version"])
+	assert.Contains(t, entry, "durationMs")
+	assert.NotContains(t, entry, "error")
+}
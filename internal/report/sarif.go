@@ -0,0 +1,171 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"spboyer.azd.doctor/internal/checks"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "azd-doctor"
+	toolURI      = "https://github.com/spboyer/azd-ext-doctor"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	Name             string        `json:"name"`
+	ShortDescription sarifMessage  `json:"shortDescription"`
+	Help             *sarifMessage `json:"help,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Kind      string          `json:"kind"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the report as a SARIF 2.1.0 log with one result per check.
+// Results that don't point at an azure.yaml entry are reported against the project file, if any.
+func (r *Report) WriteSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]int)
+	for _, c := range r.Checks {
+		index, ok := rules[c.ID]
+		if !ok {
+			rule := sarifRule{ID: c.ID, Name: c.Name, ShortDescription: sarifMessage{Text: c.Name}}
+			if c.Remediation != "" {
+				rule.Help = &sarifMessage{Text: c.Remediation}
+			}
+			index = len(run.Tool.Driver.Rules)
+			rules[c.ID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		kind, level := sarifKindLevel(c.Status)
+		result := sarifResult{
+			RuleID:    c.ID,
+			RuleIndex: index,
+			Kind:      kind,
+			Level:     level,
+			Message:   sarifMessage{Text: c.message()},
+		}
+		if loc := c.sarifLocation(r.ProjectFile); loc != nil {
+			result.Locations = []sarifLocation{*loc}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+func sarifKindLevel(status checks.Severity) (kind, level string) {
+	switch status {
+	case checks.SeverityFail:
+		return "fail", "error"
+	case checks.SeverityWarn:
+		return "fail", "warning"
+	case checks.SeveritySkip:
+		return "notApplicable", "none"
+	case checks.SeverityInfo:
+		return "informational", "none"
+	default:
+		return "pass", "none"
+	}
+}
+
+func (c Check) sarifLocation(projectFile string) *sarifLocation {
+	if c.Location == nil {
+		if projectFile == "" {
+			return nil
+		}
+		return &sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(projectFile)},
+		}}
+	}
+
+	loc := &sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(c.Location.File)},
+	}}
+	if c.Location.Line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: c.Location.Line, StartColumn: c.Location.Column}
+	}
+	return loc
+}
+
+// message summarizes the check for SARIF and JUnit output.
+func (c Check) message() string {
+	text := c.Name
+	switch {
+	case c.Error != "":
+		text += ": " + c.Error
+	case c.Version != "":
+		text += ": " + c.Version
+	}
+	if c.Remediation != "" && (c.Status == checks.SeverityFail || c.Status == checks.SeverityWarn) {
+		text += ". " + c.Remediation
+	}
+	if c.Install != "" {
+		text += ". Install: " + c.Install
+	}
+	return text
+}
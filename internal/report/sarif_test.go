@@ -0,0 +1,96 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/checks"
+)
+
+func sampleReport() *Report {
+	r := New("verify")
+	r.Target = "deploy"
+	r.ProjectFile = "azure.yaml"
+	r.Add("", checks.CategoryAzd, nil, checks.CheckResult{ID: "git", Name: "git", Installed: true, Version: "2.0.0"})
+	r.Add("", checks.CategoryHosting, []string{"api"}, checks.CheckResult{
+		ID:          "host:api",
+		Name:        "Unknown Host",
+		Version:     "containerapps",
+		Error:       fmt.Errorf("service api uses unknown host \"containerapps\""),
+		Severity:    checks.SeverityWarn,
+		Remediation: "Use a supported host",
+		Location:    &checks.Location{File: "azure.yaml", Line: 8, Column: 5},
+	})
+	r.Add("", checks.CategoryLanguage, []string{"api"}, checks.CheckResult{
+		ID:          "node",
+		Name:        "node",
+		Error:       fmt.Errorf("executable file not found"),
+		Severity:    checks.SeverityFail,
+		Remediation: "Install Node.js",
+		Duration:    1500000000,
+	})
+	r.Add("", checks.CategoryContainer, nil, checks.CheckResult{ID: "docker-daemon", Name: "docker Daemon", Severity: checks.SeveritySkip,
+		Error: fmt.Errorf("skipped because docker did not pass")})
+	r.Finish(fmt.Errorf("required tool not found: node"))
+	return r
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport().WriteSARIF(&buf))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal(t, "azd-doctor", run.Tool.Driver.Name)
+	require.Len(t, run.Tool.Driver.Rules, 4)
+	require.Len(t, run.Results, 4)
+
+	tests := []struct {
+		ruleID string
+		kind   string
+		level  string
+	}{
+		{ruleID: "git", kind: "pass", level: "none"},
+		{ruleID: "host:api", kind: "fail", level: "warning"},
+		{ruleID: "node", kind: "fail", level: "error"},
+		{ruleID: "docker-daemon", kind: "notApplicable", level: "none"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.ruleID, func(t *testing.T) {
+			res := run.Results[i]
+			assert.Equal(t, tt.ruleID, res.RuleID)
+			assert.Equal(t, i, res.RuleIndex)
+			assert.Equal(t, tt.kind, res.Kind)
+			assert.Equal(t, tt.level, res.Level)
+			require.Len(t, res.Locations, 1)
+			assert.Equal(t, "azure.yaml", res.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		})
+	}
+
+	host := run.Results[1].Locations[0].PhysicalLocation.Region
+	require.NotNil(t, host, "azure.yaml problems point at the line")
+	assert.Equal(t, 8, host.StartLine)
+	assert.Equal(t, 5, host.StartColumn)
+	assert.Nil(t, run.Results[2].Locations[0].PhysicalLocation.Region, "tool checks point at the project file")
+	assert.Contains(t, run.Results[2].Message.Text, "Install Node.js")
+}
+
+func TestWriteSARIFWithoutProject(t *testing.T) {
+	r := New("verify")
+	r.Add("", checks.CategoryAzd, nil, checks.CheckResult{ID: "azd", Name: "azd", Installed: true})
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteSARIF(&buf))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Empty(t, log.Runs[0].Results[0].Locations)
+}
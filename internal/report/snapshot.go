@@ -0,0 +1,143 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"spboyer.azd.doctor/internal/checks"
+)
+
+// Snapshot is the environment fingerprint written by `snapshot`. Tools, extensions and
+// config are sorted so that snapshots of the same machine are identical.
+type Snapshot struct {
+	SchemaVersion string    `json:"schemaVersion"`
+	Command       string    `json:"command"`
+	CreatedAt     time.Time `json:"createdAt"`
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch"`
+	Tools         []Tool    `json:"tools"`
+	// Extensions are the installed azd extensions.
+	Extensions []Extension `json:"extensions"`
+	// Config holds the relevant azd user config settings, flattened to dotted keys
+	// (e.g. defaults.location).
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// Tool is a detected tool in a snapshot.
+type Tool struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Status  checks.Severity `json:"status"`
+	Version string          `json:"version,omitempty"`
+	Path    string          `json:"path,omitempty"`
+}
+
+// Extension is an installed azd extension.
+type Extension struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+func NewSnapshot(goos, goarch string) *Snapshot {
+	return &Snapshot{
+		SchemaVersion: SchemaVersion,
+		Command:       "snapshot",
+		OS:            goos,
+		Arch:          goarch,
+		Tools:         []Tool{},
+		Extensions:    []Extension{},
+	}
+}
+
+// AddTool records the result of a check.
+func (s *Snapshot) AddTool(id string, res checks.CheckResult) {
+	s.Tools = append(s.Tools, Tool{ID: id, Name: res.Name, Status: res.Status(), Version: res.Version, Path: res.Path})
+	sort.Slice(s.Tools, func(i, j int) bool { return s.Tools[i].ID < s.Tools[j].ID })
+}
+
+// AddExtension records an installed extension.
+func (s *Snapshot) AddExtension(id, version string) {
+	s.Extensions = append(s.Extensions, Extension{ID: id, Version: version})
+	sort.Slice(s.Extensions, func(i, j int) bool { return s.Extensions[i].ID < s.Extensions[j].ID })
+}
+
+// Write writes the snapshot as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ReadSnapshot reads a snapshot written by `snapshot`.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	if s.Command != "snapshot" {
+		return nil, fmt.Errorf("%s is not an azd doctor snapshot", path)
+	}
+	return &s, nil
+}
+
+// DiffSnapshots returns the differences between two snapshots in a stable order:
+// system, tools, extensions, then config.
+func DiffSnapshots(a, b *Snapshot) []Difference {
+	diffs := []Difference{}
+	add := func(kind, name, field, va, vb string) {
+		if va != vb {
+			diffs = append(diffs, Difference{Kind: kind, Name: name, Field: field, A: va, B: vb})
+		}
+	}
+
+	add("system", "os", "", a.OS, b.OS)
+	add("system", "arch", "", a.Arch, b.Arch)
+
+	toolsA, toolsB := toolsByID(a), toolsByID(b)
+	for _, id := range unionKeys(toolsA, toolsB) {
+		ta, okA := toolsA[id]
+		tb, okB := toolsB[id]
+		if !okA || !okB {
+			add("tool", id, "status", string(ta.Status), string(tb.Status))
+			continue
+		}
+		add("tool", id, "status", string(ta.Status), string(tb.Status))
+		add("tool", id, "version", ta.Version, tb.Version)
+		add("tool", id, "path", ta.Path, tb.Path)
+	}
+
+	extA, extB := extensionsByID(a), extensionsByID(b)
+	for _, id := range unionKeys(extA, extB) {
+		add("extension", id, "", extA[id], extB[id])
+	}
+
+	for _, key := range unionKeys(a.Config, b.Config) {
+		add("config", key, "", a.Config[key], b.Config[key])
+	}
+
+	return diffs
+}
+
+func toolsByID(s *Snapshot) map[string]Tool {
+	m := make(map[string]Tool, len(s.Tools))
+	for _, t := range s.Tools {
+		m[t.ID] = t
+	}
+	return m
+}
+
+func extensionsByID(s *Snapshot) map[string]string {
+	m := make(map[string]string, len(s.Extensions))
+	for _, e := range s.Extensions {
+		m[e.ID] = e.Version
+	}
+	return m
+}
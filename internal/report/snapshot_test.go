@@ -0,0 +1,83 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"spboyer.azd.doctor/internal/checks"
+)
+
+func TestSnapshot_AddSorted(t *testing.T) {
+	s := NewSnapshot("linux", "amd64")
+	s.AddTool("node", checks.CheckResult{Name: "node", Installed: true, Version: "20.1.0", Path: "/usr/bin/node"})
+	s.AddTool("azd", checks.CheckResult{Name: "azd", Installed: true, Version: "1.20.0"})
+	s.AddExtension("microsoft.azd.extensions", "0.5.0")
+	s.AddExtension("azure.ai.agents", "0.1.0")
+
+	assert.Equal(t, []Tool{
+		{ID: "azd", Name: "azd", Status: checks.SeverityPass, Version: "1.20.0"},
+		{ID: "node", Name: "node", Status: checks.SeverityPass, Version: "20.1.0", Path: "/usr/bin/node"},
+	}, s.Tools)
+	assert.Equal(t, []Extension{
+		{ID: "azure.ai.agents", Version: "0.1.0"},
+		{ID: "microsoft.azd.extensions", Version: "0.5.0"},
+	}, s.Extensions)
+}
+
+func TestReadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	s := NewSnapshot("darwin", "arm64")
+	s.AddTool("git", checks.CheckResult{Name: "git", Installed: true, Version: "2.45.0"})
+	s.Config = map[string]string{"defaults.location": "eastus"}
+	path := filepath.Join(dir, "a.json")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(f))
+	require.NoError(t, f.Close())
+
+	got, err := ReadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, s, got)
+
+	other := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(other, []byte(`{"command":"verify"}`), 0644))
+	_, err = ReadSnapshot(other)
+	assert.ErrorContains(t, err, "is not an azd doctor snapshot")
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{`), 0644))
+	_, err = ReadSnapshot(invalid)
+	assert.ErrorContains(t, err, "failed to parse snapshot")
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	a := NewSnapshot("linux", "amd64")
+	a.AddTool("node", checks.CheckResult{Name: "node", Installed: true, Version: "18.19.0", Path: "/usr/bin/node"})
+	a.AddTool("git", checks.CheckResult{Name: "git", Installed: true, Version: "2.45.0", Path: "/usr/bin/git"})
+	a.AddTool("docker", checks.CheckResult{Name: "docker", Installed: true, Version: "27.1.1"})
+	a.AddExtension("microsoft.azd.extensions", "0.5.0")
+	a.Config = map[string]string{"defaults.location": "eastus", "alpha.extensions": "on"}
+
+	b := NewSnapshot("linux", "arm64")
+	b.AddTool("node", checks.CheckResult{Name: "node", Installed: true, Version: "20.1.0", Path: "/opt/node/bin/node"})
+	b.AddTool("git", checks.CheckResult{Name: "git", Installed: true, Version: "2.45.0", Path: "/usr/bin/git"})
+	b.AddExtension("microsoft.azd.extensions", "0.6.0")
+	b.AddExtension("azure.ai.agents", "0.1.0")
+	b.Config = map[string]string{"defaults.location": "westus", "alpha.extensions": "on"}
+
+	assert.Equal(t, []Difference{
+		{Kind: "system", Name: "arch", A: "amd64", B: "arm64"},
+		{Kind: "tool", Name: "docker", Field: "status", A: "pass", B: ""},
+		{Kind: "tool", Name: "node", Field: "version", A: "18.19.0", B: "20.1.0"},
+		{Kind: "tool", Name: "node", Field: "path", A: "/usr/bin/node", B: "/opt/node/bin/node"},
+		{Kind: "extension", Name: "azure.ai.agents", A: "", B: "0.1.0"},
+		{Kind: "extension", Name: "microsoft.azd.extensions", A: "0.5.0", B: "0.6.0"},
+		{Kind: "config", Name: "defaults.location", A: "eastus", B: "westus"},
+	}, DiffSnapshots(a, b))
+
+	assert.Empty(t, DiffSnapshots(a, a))
+}
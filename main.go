@@ -20,7 +20,7 @@ func init() {
 }
 
 func main() {
-	f, _ := os.OpenFile("/tmp/azd-doctor-debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, _ := os.OpenFile(cmd.DebugLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if f != nil {
 		defer f.Close()
 		fmt.Fprintf(f, "--- Starting azd-ext-doctor ---\n")
@@ -45,7 +45,8 @@ func main() {
 	rootCmd := cmd.NewRootCommand()
 
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
-		color.Red("Error: %v", err)
+		// Errors go to stderr so they don't corrupt `--output json` on stdout.
+		fmt.Fprintln(os.Stderr, color.RedString("Error: %v", err))
 		os.Exit(1)
 	}
 }